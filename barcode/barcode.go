@@ -0,0 +1,137 @@
+// Package barcode draws the EAN-13 barcode of an ISBN, with an optional
+// EAN-5 add-on, as SVG, PNG or EPS.
+//
+//	b, err := barcode.New(v, "51299") // US$12.99
+//	if err != nil {
+//		...
+//	}
+//	err = b.SVG(w, nil)
+//
+// Sizes are given in modules, the width of the narrowest bar. A module is
+// drawn Options.Scale pixels wide in SVG and PNG, and Options.Scale points
+// wide in EPS.
+package barcode
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/adoublef-go/isbn"
+)
+
+var (
+	// ErrNoISBN is returned by New for the zero ISBN.
+	ErrNoISBN = errors.New("barcode: no ISBN")
+	// ErrAddOn is returned by New for an add-on that is not 5 digits.
+	ErrAddOn = errors.New("barcode: add-on must be 5 digits")
+)
+
+// Barcode is the EAN-13 symbol of an ISBN.
+type Barcode struct {
+	ISBN isbn.ISBN
+	// AddOn is the EAN-5 add-on, usually a price such as "51299" for
+	// US$12.99 or "90000" for none, or empty.
+	AddOn string
+}
+
+// New returns the barcode of an ISBN with an add-on, which may be empty.
+func New(v isbn.ISBN, addOn string) (Barcode, error) {
+	if v == (isbn.ISBN{}) {
+		return Barcode{}, ErrNoISBN
+	}
+	if addOn != "" && (len(addOn) != 5 || strings.Trim(addOn, "0123456789") != "") {
+		return Barcode{}, ErrAddOn
+	}
+	return Barcode{ISBN: v, AddOn: addOn}, nil
+}
+
+// Options configure the drawing of a barcode. A nil *Options uses the
+// defaults.
+type Options struct {
+	// Scale is the width of a module, 1 if zero. PNG rounds the size of
+	// bars to whole pixels.
+	Scale float64
+	// Height is the height of the bars in modules, DefaultHeight if zero.
+	Height float64
+	// NoText leaves out the digits under the bars and the ISBN above them.
+	// PNG never draws text.
+	NoText bool
+}
+
+// DefaultHeight is the height of bars at the nominal size of EAN-13, 22.85mm
+// with a module of 0.33mm.
+const DefaultHeight = 69
+
+func (o *Options) scale() float64 {
+	if o == nil || o.Scale <= 0 {
+		return 1
+	}
+	return o.Scale
+}
+
+func (o *Options) height() float64 {
+	if o == nil || o.Height <= 0 {
+		return DefaultHeight
+	}
+	return o.Height
+}
+
+func (o *Options) text() bool { return o == nil || !o.NoText }
+
+// Symbol character encodings, by digit.
+var (
+	codeL = [10]string{"0001101", "0011001", "0010011", "0111101", "0100011", "0110001", "0101111", "0111011", "0110111", "0001011"}
+	codeG = [10]string{"0100111", "0110011", "0011011", "0100001", "0011101", "0111001", "0000101", "0010001", "0001001", "0010111"}
+	codeR = [10]string{"1110010", "1100110", "1101100", "1000010", "1011100", "1001110", "1010000", "1000100", "1001000", "1110100"}
+
+	// parity13 is the parity of the left digits of an EAN-13, by its first
+	// digit, and parity5 that of an EAN-5, by its checksum.
+	parity13 = [10]string{"LLLLLL", "LLGLGG", "LLGGLG", "LLGGGL", "LGLLGG", "LGGLLG", "LGGGLL", "LGLGLG", "LGLGGL", "LGGLGL"}
+	parity5  = [10]string{"GGLLL", "GLGLL", "GLLGL", "GLLLG", "LGGLL", "LLGGL", "LLLGG", "LGLGL", "LGLLG", "LLGLG"}
+)
+
+func encode(parity, digit byte) string {
+	if parity == 'G' {
+		return codeG[digit-'0']
+	}
+	return codeL[digit-'0']
+}
+
+// modules returns the 95 modules of the EAN-13 symbol, '1' for a bar.
+func (b Barcode) modules() string {
+	d := b.ISBN.String()
+	var s strings.Builder
+	s.WriteString("101")
+	for i, p := range []byte(parity13[d[0]-'0']) {
+		s.WriteString(encode(p, d[1+i]))
+	}
+	s.WriteString("01010")
+	for i := 7; i < 13; i++ {
+		s.WriteString(codeR[d[i]-'0'])
+	}
+	s.WriteString("101")
+	return s.String()
+}
+
+// addOnModules returns the 47 modules of the EAN-5 add-on.
+func (b Barcode) addOnModules() string {
+	d := b.AddOn
+	sum := 0
+	for i := 0; i < 5; i++ {
+		w := 3
+		if i%2 == 1 {
+			w = 9
+		}
+		sum += w * int(d[i]-'0')
+	}
+
+	var s strings.Builder
+	s.WriteString("1011")
+	for i, p := range []byte(parity5[sum%10]) {
+		if i > 0 {
+			s.WriteString("01")
+		}
+		s.WriteString(encode(p, d[i]))
+	}
+	return s.String()
+}
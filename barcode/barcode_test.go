@@ -0,0 +1,135 @@
+package barcode
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/adoublef-go/isbn"
+	"github.com/hyphengolang/prelude/testing/is"
+)
+
+var fox, _ = isbn.Parse("9780140328721")
+
+// decode reads the digits back from the modules of an EAN-13.
+func decode(modules string) string {
+	find := func(code string, codes [10]string) int {
+		for d, c := range codes {
+			if c == code {
+				return d
+			}
+		}
+		return -1
+	}
+
+	var digits, parity strings.Builder
+	for i := 0; i < 6; i++ {
+		code := modules[3+7*i : 10+7*i]
+		if d := find(code, codeL); d >= 0 {
+			digits.WriteByte(byte('0' + d))
+			parity.WriteByte('L')
+		} else if d := find(code, codeG); d >= 0 {
+			digits.WriteByte(byte('0' + d))
+			parity.WriteByte('G')
+		}
+	}
+	for i := 0; i < 6; i++ {
+		if d := find(modules[50+7*i:57+7*i], codeR); d >= 0 {
+			digits.WriteByte(byte('0' + d))
+		}
+	}
+	first := find(parity.String(), parity13)
+	return string(rune('0'+first)) + digits.String()
+}
+
+func TestModules(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	for _, s := range []string{"9780140328721", "9780716703440", "9791032305690"} {
+		v, err := isbn.Parse(s)
+		is.NoErr(err) // parsing isbn
+
+		modules := Barcode{ISBN: v}.modules()
+		is.Equal(len(modules), 95)        // ean-13 modules
+		is.Equal(modules[:3], "101")      // start guard
+		is.Equal(modules[45:50], "01010") // centre guard
+		is.Equal(modules[92:], "101")     // end guard
+		is.Equal(decode(modules), s)      // digits round trip
+	}
+
+	b, err := New(fox, "52495")
+	is.NoErr(err) // barcode with add-on
+
+	addOn := b.addOnModules()
+	is.Equal(len(addOn), 47)         // ean-5 modules
+	is.Equal(addOn[:4], "1011")      // add-on start guard
+	is.Equal(addOn[4:11], codeG[5])  // checksum 1 gives parity GLGLL
+	is.Equal(addOn[13:20], codeL[2]) // second digit
+	is.Equal(addOn[11:13], "01")     // delimiter
+
+	for _, addOn := range []string{"5129", "512990", "5129x"} {
+		_, err := New(fox, addOn)
+		is.True(errors.Is(err, ErrAddOn)) // invalid add-on
+	}
+
+	_, err = New(isbn.ISBN{}, "")
+	is.True(errors.Is(err, ErrNoISBN)) // zero isbn
+}
+
+func TestDraw(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	b, err := New(fox, "51299")
+	is.NoErr(err) // barcode with add-on
+
+	var buf bytes.Buffer
+	is.NoErr(b.SVG(&buf, nil)) // drawing svg
+
+	dec := xml.NewDecoder(&buf)
+	var rects, texts int
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		is.NoErr(err) // well formed svg
+		if start, ok := tok.(xml.StartElement); ok {
+			switch start.Name.Local {
+			case "rect":
+				rects++
+			case "text":
+				texts++
+			}
+		}
+	}
+	is.Equal(rects, 1+30+16) // background, ean-13 and ean-5 bars
+	is.Equal(texts, 13+1+5)  // digits, isbn and add-on digits
+
+	buf.Reset()
+	is.NoErr(b.EPS(&buf, &Options{Scale: 2})) // drawing eps
+	eps := buf.String()
+	is.True(strings.HasPrefix(eps, "%!PS-Adobe-3.0 EPSF-3.0\n"))   // eps header
+	is.True(strings.Contains(eps, "%%BoundingBox: 0 0 334 178\n")) // scaled bounding box
+	is.True(strings.Contains(eps, "(ISBN 978-0-14-032872-1)"))     // hyphenated isbn
+
+	buf.Reset()
+	is.NoErr(b.PNG(&buf, &Options{Scale: 3, Height: 50})) // drawing png
+	img, err := png.Decode(&buf)
+	is.NoErr(err) // decoding png
+
+	bounds := img.Bounds()
+	is.Equal(bounds.Dx(), (11+95+9+47+5)*3) // width with quiet zones
+	is.Equal(bounds.Dy(), (50+5)*3)         // height with guard bars
+
+	black := func(x, y int) bool { r, _, _, _ := img.At(x, y).RGBA(); return r == 0 }
+	is.True(!black(0, 0))           // quiet zone
+	is.True(black(11*3, 0))         // start guard
+	is.True(black(11*3, (50+4)*3))  // guard bar extends down
+	is.True(!black(14*3, (50+4)*3)) // other bars do not
+}
@@ -0,0 +1,199 @@
+package barcode
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Layout of a barcode, in modules.
+const (
+	quietLeft  = 11
+	quietRight = 7
+	addOnGap   = 9
+	addOnQuiet = 5
+	// guardDepth is how far guard bars extend below the others.
+	guardDepth = 5
+	// textSize is the font size of text, and textHeight the height of a
+	// line of it.
+	textSize   = 8
+	textHeight = 10
+)
+
+type rect struct{ x, y, w, h float64 }
+
+// label is text centered on x, with its baseline at y.
+type label struct {
+	x, y float64
+	s    string
+}
+
+// layout is a barcode placed on its page, with y increasing downwards.
+type layout struct {
+	w, h   float64
+	bars   []rect
+	labels []label
+}
+
+// runs appends the bars of modules starting at x, with the height given by
+// the index of their first module.
+func (l *layout) runs(modules string, x, y float64, height func(i int) float64) {
+	for i := 0; i < len(modules); {
+		if modules[i] != '1' {
+			i++
+			continue
+		}
+		j := i
+		for j < len(modules) && modules[j] == '1' {
+			j++
+		}
+		l.bars = append(l.bars, rect{x + float64(i), y, float64(j - i), height(i)})
+		i = j
+	}
+}
+
+func (b Barcode) layout(o *Options) layout {
+	var l layout
+	h, text := o.height(), o.text()
+
+	top := 0.0
+	if text {
+		top = textHeight
+	}
+	l.runs(b.modules(), quietLeft, top, func(i int) float64 {
+		if i < 3 || i >= 45 && i < 50 || i >= 92 {
+			return h + guardDepth
+		}
+		return h
+	})
+	l.w = quietLeft + 95 + quietRight
+	l.h = top + h + guardDepth
+	if text {
+		l.h = top + h + textHeight
+	}
+
+	if text {
+		d := b.ISBN.String()
+		base := top + h + textSize
+		l.labels = append(l.labels, label{quietLeft - 4, base, d[:1]})
+		for i := 0; i < 6; i++ {
+			l.labels = append(l.labels,
+				label{quietLeft + 3 + 7*float64(i) + 3.5, base, d[1+i : 2+i]},
+				label{quietLeft + 50 + 7*float64(i) + 3.5, base, d[7+i : 8+i]},
+			)
+		}
+
+		title, err := b.ISBN.Hyphenate()
+		if err != nil {
+			title = d
+		}
+		l.labels = append(l.labels, label{quietLeft + 95.0/2, textSize, "ISBN " + title})
+	}
+
+	if b.AddOn != "" {
+		x := quietLeft + 95 + addOnGap
+		// the digits of the add-on are above its bars, which end level
+		// with the guard bars
+		y := top
+		if text {
+			y += textHeight
+		}
+		bottom := top + h + guardDepth
+		l.runs(b.addOnModules(), float64(x), y, func(int) float64 { return bottom - y })
+		l.w = float64(x) + 47 + addOnQuiet
+
+		if text {
+			for i := 0; i < 5; i++ {
+				l.labels = append(l.labels, label{float64(x) + 4 + 9*float64(i) + 3.5, y - 2, b.AddOn[i : i+1]})
+			}
+		}
+	}
+	return l
+}
+
+func num(v float64) string {
+	return strconv.FormatFloat(math.Round(v*1000)/1000, 'f', -1, 64)
+}
+
+// SVG writes the barcode as an SVG image.
+func (b Barcode) SVG(w io.Writer, o *Options) error {
+	l, s := b.layout(o), o.scale()
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="0 0 %[1]s %[2]s">`+"\n",
+		num(l.w*s), num(l.h*s))
+	fmt.Fprintf(bw, "<title>ISBN %s</title>\n", b.ISBN)
+	bw.WriteString(`<rect width="100%" height="100%" fill="#fff"/>` + "\n")
+	bw.WriteString(`<g fill="#000">` + "\n")
+	for _, r := range l.bars {
+		fmt.Fprintf(bw, `<rect x="%s" y="%s" width="%s" height="%s"/>`+"\n", num(r.x*s), num(r.y*s), num(r.w*s), num(r.h*s))
+	}
+	bw.WriteString("</g>\n")
+	if len(l.labels) > 0 {
+		fmt.Fprintf(bw, `<g font-family="OCR-B, monospace" font-size="%s" text-anchor="middle" fill="#000">`+"\n", num(textSize*s))
+		for _, t := range l.labels {
+			fmt.Fprintf(bw, `<text x="%s" y="%s">%s</text>`+"\n", num(t.x*s), num(t.y*s), t.s)
+		}
+		bw.WriteString("</g>\n")
+	}
+	bw.WriteString("</svg>\n")
+	return bw.Flush()
+}
+
+// EPS writes the barcode as Encapsulated PostScript.
+func (b Barcode) EPS(w io.Writer, o *Options) error {
+	l, s := b.layout(o), o.scale()
+	width, height := l.w*s, l.h*s
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString("%!PS-Adobe-3.0 EPSF-3.0\n")
+	fmt.Fprintf(bw, "%%%%BoundingBox: 0 0 %d %d\n", int(math.Ceil(width)), int(math.Ceil(height)))
+	fmt.Fprintf(bw, "%%%%HiResBoundingBox: 0 0 %s %s\n", num(width), num(height))
+	fmt.Fprintf(bw, "%%%%Title: ISBN %s\n", b.ISBN)
+	bw.WriteString("%%EndComments\n")
+	bw.WriteString("gsave\n")
+	fmt.Fprintf(bw, "1 setgray 0 0 %s %s rectfill 0 setgray\n", num(width), num(height))
+	for _, r := range l.bars {
+		// PostScript has y increasing upwards
+		fmt.Fprintf(bw, "%s %s %s %s rectfill\n", num(r.x*s), num(height-(r.y+r.h)*s), num(r.w*s), num(r.h*s))
+	}
+	if len(l.labels) > 0 {
+		fmt.Fprintf(bw, "/Helvetica findfont %s scalefont setfont\n", num(textSize*s))
+		for _, t := range l.labels {
+			fmt.Fprintf(bw, "%s %s moveto (%s) dup stringwidth pop 2 div neg 0 rmoveto show\n",
+				num(t.x*s), num(height-t.y*s), strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`).Replace(t.s))
+		}
+	}
+	bw.WriteString("grestore\n%%EOF\n")
+	return bw.Flush()
+}
+
+// PNG writes the barcode as a PNG image, without text.
+func (b Barcode) PNG(w io.Writer, o *Options) error {
+	if o == nil {
+		o = &Options{}
+	}
+	noText := *o
+	noText.NoText = true
+	l, s := b.layout(&noText), o.scale()
+
+	px := func(v float64) int { return int(math.Round(v * s)) }
+	img := image.NewGray(image.Rect(0, 0, px(l.w), px(l.h)))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	for _, r := range l.bars {
+		for y := px(r.y); y < px(r.y+r.h); y++ {
+			for x := px(r.x); x < px(r.x+r.w); x++ {
+				img.SetGray(x, y, color.Gray{})
+			}
+		}
+	}
+	return png.Encode(w, img)
+}
@@ -0,0 +1,277 @@
+package citation
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/adoublef-go/isbn/metadata"
+)
+
+// ReadBibTeX reads the entries of a BibTeX database. Macros defined by
+// @string entries are expanded; @preamble and @comment entries are skipped,
+// as is any text outside of an entry.
+func ReadBibTeX(r io.Reader) ([]Entry, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	p := &bibParser{s: string(b), macros: map[string]string{}}
+
+	var entries []Entry
+	for {
+		e, err := p.entry()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if e != nil {
+			entries = append(entries, *e)
+		}
+	}
+}
+
+type bibParser struct {
+	s      string
+	i      int
+	macros map[string]string
+}
+
+func (p *bibParser) errorf(format string, a ...any) error {
+	line := 1 + strings.Count(p.s[:p.i], "\n")
+	return fmt.Errorf("citation: bibtex line %d: %s", line, fmt.Sprintf(format, a...))
+}
+
+func (p *bibParser) space() {
+	for p.i < len(p.s) && unicode.IsSpace(rune(p.s[p.i])) {
+		p.i++
+	}
+}
+
+// entry parses the next entry, returning nil for one that is skipped.
+func (p *bibParser) entry() (*Entry, error) {
+	at := strings.IndexByte(p.s[p.i:], '@')
+	if at < 0 {
+		return nil, io.EOF
+	}
+	p.i += at + 1
+
+	typ := p.ident()
+	p.space()
+	if p.i >= len(p.s) || p.s[p.i] != '{' && p.s[p.i] != '(' {
+		return nil, p.errorf("expected { after @%s", typ)
+	}
+	open := p.s[p.i]
+	close := byte('}')
+	if open == '(' {
+		close = ')'
+	}
+	p.i++
+
+	switch strings.ToLower(typ) {
+	case "string":
+		p.space()
+		name := strings.ToLower(p.ident())
+		p.space()
+		if p.i >= len(p.s) || p.s[p.i] != '=' {
+			return nil, p.errorf("expected = after @string{%s", name)
+		}
+		p.i++
+		value, err := p.value(close)
+		if err != nil {
+			return nil, err
+		}
+		p.macros[name] = value
+		p.i++
+		return nil, nil
+	case "preamble", "comment":
+		if _, err := p.until(close); err != nil {
+			return nil, err
+		}
+		p.i++
+		return nil, nil
+	}
+
+	e := &Entry{Type: strings.ToLower(typ)}
+	p.space()
+	key := strings.IndexAny(p.s[p.i:], ","+string(close))
+	if key < 0 {
+		return nil, p.errorf("unterminated entry")
+	}
+	e.Key = strings.TrimSpace(p.s[p.i : p.i+key])
+	p.i += key
+
+	for {
+		p.space()
+		if p.i >= len(p.s) {
+			return nil, p.errorf("unterminated entry %s", e.Key)
+		}
+		switch p.s[p.i] {
+		case close:
+			p.i++
+			return e, nil
+		case ',':
+			p.i++
+			continue
+		}
+
+		name := strings.ToLower(p.ident())
+		if name == "" {
+			return nil, p.errorf("expected field name in entry %s", e.Key)
+		}
+		p.space()
+		if p.i >= len(p.s) || p.s[p.i] != '=' {
+			return nil, p.errorf("expected = after %s", name)
+		}
+		p.i++
+
+		value, err := p.value(close)
+		if err != nil {
+			return nil, err
+		}
+
+		switch name {
+		case "title":
+			e.Title = value
+		case "author":
+			e.Authors = append(e.Authors, splitAuthors(value)...)
+		case "publisher":
+			e.Publisher = value
+		case "year":
+			e.Year = value
+		case "isbn":
+			e.ISBNs = append(e.ISBNs, ParseISBNs(value)...)
+		}
+	}
+}
+
+func (p *bibParser) ident() string {
+	start := p.i
+	for p.i < len(p.s) {
+		c := p.s[p.i]
+		if !(unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c)) || strings.IndexByte("_-:.+/", c) >= 0) {
+			break
+		}
+		p.i++
+	}
+	return p.s[start:p.i]
+}
+
+// value parses a field value, the concatenation with # of braced or quoted
+// strings, numbers and macros, with the braces of LaTeX groups removed.
+func (p *bibParser) value(close byte) (string, error) {
+	var b strings.Builder
+	for {
+		p.space()
+		if p.i >= len(p.s) {
+			return "", p.errorf("unterminated value")
+		}
+
+		switch c := p.s[p.i]; c {
+		case '{':
+			p.i++
+			s, err := p.until('}')
+			if err != nil {
+				return "", err
+			}
+			p.i++
+			b.WriteString(s)
+		case '"':
+			p.i++
+			s, err := p.until('"')
+			if err != nil {
+				return "", err
+			}
+			p.i++
+			b.WriteString(s)
+		default:
+			// a number, or a macro which is left as its name if undefined
+			name := p.ident()
+			if v, ok := p.macros[strings.ToLower(name)]; ok {
+				name = v
+			}
+			b.WriteString(name)
+		}
+
+		p.space()
+		if p.i < len(p.s) && p.s[p.i] == '#' {
+			p.i++
+			continue
+		}
+		if p.i >= len(p.s) {
+			return "", p.errorf("unterminated value")
+		}
+		if p.s[p.i] == ',' || p.s[p.i] == close {
+			return strings.Join(strings.Fields(b.String()), " "), nil
+		}
+		return "", p.errorf("unexpected %q in value", p.s[p.i:p.i+1])
+	}
+}
+
+// until returns the text up to the unnested end, with braces removed.
+func (p *bibParser) until(end byte) (string, error) {
+	var b strings.Builder
+	depth := 0
+	for ; p.i < len(p.s); p.i++ {
+		c := p.s[p.i]
+		switch {
+		case c == end && depth == 0:
+			return b.String(), nil
+		case c == '\\' && p.i+1 < len(p.s):
+			// an escaped character, e.g. \&
+			p.i++
+			b.WriteByte(p.s[p.i])
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", p.errorf("missing %q", end)
+}
+
+func splitAuthors(s string) []string {
+	var authors []string
+	for _, a := range strings.Split(s, " and ") {
+		if a = strings.TrimSpace(a); a != "" {
+			authors = append(authors, a)
+		}
+	}
+	return authors
+}
+
+// WriteBibTeX writes books as @book entries keyed by their ISBN, e.g.
+// isbn9780140328721.
+func WriteBibTeX(w io.Writer, books ...metadata.Book) error {
+	bw := bufio.NewWriter(w)
+	for i, book := range books {
+		e := fromBook(book)
+		if i > 0 {
+			bw.WriteString("\n")
+		}
+		fmt.Fprintf(bw, "@book{%s,\n", e.Key)
+
+		field := func(name, value string) {
+			if value != "" {
+				fmt.Fprintf(bw, "  %-9s = {%s},\n", name, escapeBibTeX(value))
+			}
+		}
+		field("title", e.Title)
+		field("author", strings.Join(e.Authors, " and "))
+		field("publisher", e.Publisher)
+		field("year", e.Year)
+		field("isbn", formatISBNs(e.ISBNs))
+		bw.WriteString("}\n")
+	}
+	return bw.Flush()
+}
+
+var bibTeXEscaper = strings.NewReplacer(`\`, `\\`, `{`, `\{`, `}`, `\}`, `&`, `\&`, `%`, `\%`, `$`, `\$`, `#`, `\#`, `_`, `\_`)
+
+func escapeBibTeX(s string) string { return bibTeXEscaper.Replace(s) }
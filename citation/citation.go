@@ -0,0 +1,151 @@
+// Package citation reads the ISBNs of citations in BibTeX, RIS and CSL-JSON,
+// and writes book metadata in those formats.
+//
+// Citations often hold several ISBNs in a single field, each with a
+// qualifier, such as "0140328726 (pbk.); 9780140328721 (hbk.)". Every ISBN is
+// returned with its qualifier, and those that are invalid with the error
+// from isbn.Parse.
+package citation
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/adoublef-go/isbn"
+	"github.com/adoublef-go/isbn/metadata"
+)
+
+// Entry is a citation.
+type Entry struct {
+	// Key is the citation key of a BibTeX entry, the ID of a RIS record or
+	// the id of a CSL-JSON item.
+	Key string
+	// Type is the type as given, e.g. "book" in BibTeX, "BOOK" in RIS.
+	Type string
+
+	Title     string
+	Authors   []string
+	Publisher string
+	// Year is the year of publication, if known.
+	Year string
+
+	ISBNs []ISBN
+}
+
+// ISBN is an ISBN of a citation.
+type ISBN struct {
+	// Value is the ISBN as given, e.g. "0-14-032872-6".
+	Value string
+	// ISBN is the parsed Value, and Err the error parsing it.
+	ISBN isbn.ISBN
+	Err  error
+	// Qualifier is the text qualifying the ISBN, without parentheses, e.g.
+	// "pbk.".
+	Qualifier string
+}
+
+// Valid returns the valid ISBNs of e.
+func (e *Entry) Valid() []isbn.ISBN {
+	var isbns []isbn.ISBN
+	for _, v := range e.ISBNs {
+		if v.Err == nil {
+			isbns = append(isbns, v.ISBN)
+		}
+	}
+	return isbns
+}
+
+// Book returns the metadata of e, with its first valid ISBN.
+func (e *Entry) Book() metadata.Book {
+	book := metadata.Book{
+		Title:     e.Title,
+		Authors:   e.Authors,
+		Publisher: e.Publisher,
+		Published: e.Year,
+	}
+	if valid := e.Valid(); len(valid) > 0 {
+		book.ISBN = valid[0]
+	}
+	return book
+}
+
+var isbnPattern = regexp.MustCompile(`(?i)(?:ISBN(?:-1[03])?:?\s*)?([0-9][0-9-]{8,15}[0-9X])(?:\s*\(([^)]*)\))?`)
+
+// ParseISBNs parses the ISBNs of a field, which are separated by semicolons
+// or commas, each optionally followed by a qualifier. ISBNs separated only by
+// spaces are found too, but then keep only a qualifier in parentheses. Text
+// that holds no ISBN at all is returned as an invalid ISBN, with
+// isbn.ErrFormat.
+func ParseISBNs(s string) []ISBN {
+	var isbns []ISBN
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool { return r == ';' || r == ',' }) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		matches := isbnPattern.FindAllStringSubmatchIndex(part, -1)
+		if len(matches) == 0 {
+			isbns = append(isbns, ISBN{Value: part, Err: isbn.ErrFormat})
+			continue
+		}
+
+		for _, m := range matches {
+			v := ISBN{Value: part[m[2]:m[3]]}
+			v.ISBN, v.Err = isbn.Parse(v.Value)
+			if m[4] >= 0 {
+				v.Qualifier = strings.TrimSpace(part[m[4]:m[5]])
+			}
+			isbns = append(isbns, v)
+		}
+
+		// a single ISBN may be followed by a qualifier without parentheses,
+		// as in "0140328726 pbk."
+		if last := &isbns[len(isbns)-1]; len(matches) == 1 && last.Qualifier == "" {
+			last.Qualifier = strings.Trim(part[matches[0][1]:], " :")
+		}
+	}
+	return isbns
+}
+
+// formatISBNs formats isbns as a field, the inverse of ParseISBNs.
+func formatISBNs(isbns []ISBN) string {
+	parts := make([]string, len(isbns))
+	for i, v := range isbns {
+		parts[i] = v.Value
+		if v.Qualifier != "" {
+			parts[i] += " (" + v.Qualifier + ")"
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// year returns the year of a Published date, e.g. 1988 for "1988-10-01"
+// or "October 1, 1988".
+func year(published string) string {
+	if m := yearPattern.FindStringSubmatch(published); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+var yearPattern = regexp.MustCompile(`(?:^|[^0-9])([0-9]{4})(?:[^0-9]|$)`)
+
+// fromBook returns the entry of book, keyed by its ISBN.
+func fromBook(book metadata.Book) Entry {
+	e := Entry{
+		Key:       "isbn" + book.ISBN.String(),
+		Type:      "book",
+		Title:     book.Title,
+		Authors:   book.Authors,
+		Publisher: book.Publisher,
+		Year:      year(book.Published),
+	}
+	if book.Subtitle != "" {
+		e.Title += ": " + book.Subtitle
+	}
+	if book.ISBN != (isbn.ISBN{}) {
+		e.ISBNs = []ISBN{{Value: book.ISBN.String(), ISBN: book.ISBN}}
+	}
+	return e
+}
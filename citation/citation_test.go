@@ -0,0 +1,280 @@
+package citation
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/adoublef-go/isbn"
+	"github.com/adoublef-go/isbn/metadata"
+	"github.com/hyphengolang/prelude/testing/is"
+)
+
+var (
+	fox, _   = isbn.Parse("9780140328721")
+	panda, _ = isbn.Parse("9780716703440")
+)
+
+func TestParseISBNs(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	tt := []struct {
+		desc       string
+		field      string
+		values     []string
+		qualifiers []string
+		errs       []error
+	}{
+		{
+			desc:       "single",
+			field:      "0140328726",
+			values:     []string{"0140328726"},
+			qualifiers: []string{""},
+			errs:       []error{nil},
+		},
+		{
+			desc:       "semicolon separated with qualifiers",
+			field:      "0-14-032872-6 (pbk.); 9780140328721 (hbk.)",
+			values:     []string{"0-14-032872-6", "9780140328721"},
+			qualifiers: []string{"pbk.", "hbk."},
+			errs:       []error{nil, nil},
+		},
+		{
+			desc:       "prefixed",
+			field:      "ISBN 0716703440, ISBN-13: 978-0-7167-0344-0",
+			values:     []string{"0716703440", "978-0-7167-0344-0"},
+			qualifiers: []string{"", ""},
+			errs:       []error{nil, nil},
+		},
+		{
+			desc:       "qualifier without parentheses",
+			field:      "9780140328721 paperback",
+			values:     []string{"9780140328721"},
+			qualifiers: []string{"paperback"},
+			errs:       []error{nil},
+		},
+		{
+			desc:       "space separated",
+			field:      "0140328726 (pbk.) 9780716703440",
+			values:     []string{"0140328726", "9780716703440"},
+			qualifiers: []string{"pbk.", ""},
+			errs:       []error{nil, nil},
+		},
+		{
+			desc:       "invalid check digit",
+			field:      "0140328727 (pbk.)",
+			values:     []string{"0140328727"},
+			qualifiers: []string{"pbk."},
+			errs:       []error{isbn.ErrValue},
+		},
+		{
+			desc:       "not an isbn",
+			field:      "unknown; 9780140328721",
+			values:     []string{"unknown", "9780140328721"},
+			qualifiers: []string{"", ""},
+			errs:       []error{isbn.ErrFormat, nil},
+		},
+		{
+			desc:  "empty",
+			field: " ; ",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			isbns := ParseISBNs(tc.field)
+			is.Equal(len(isbns), len(tc.values)) // isbns in field
+
+			for i, v := range isbns {
+				is.Equal(v.Value, tc.values[i])         // value as given
+				is.Equal(v.Qualifier, tc.qualifiers[i]) // qualifier
+				is.True(errors.Is(v.Err, tc.errs[i]))   // parse error
+			}
+		})
+	}
+}
+
+func read(t *testing.T, name string, fn func(io.Reader) ([]Entry, error)) []Entry {
+	t.Helper()
+
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	entries, err := fn(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entries
+}
+
+func TestRead(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		desc string
+		name string
+		read func(io.Reader) ([]Entry, error)
+		// authors of the first entry, as written by the format
+		authors []string
+	}{
+		{"bibtex", "testdata/books.bib", ReadBibTeX, []string{"Dahl, Roald", "Blake, Quentin"}},
+		{"ris", "testdata/books.ris", ReadRIS, []string{"Dahl, Roald", "Blake, Quentin"}},
+		{"csl-json", "testdata/books.json", ReadCSLJSON, []string{"Roald Dahl", "Quentin Blake"}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			is := is.New(t)
+
+			entries := read(t, tc.name, tc.read)
+			is.True(len(entries) >= 2) // entries read
+
+			e := entries[0]
+			is.Equal(e.Key, "dahl1988")                 // citation key
+			is.Equal(e.Title, "Fantastic Mr Fox")       // title
+			is.Equal(e.Authors, tc.authors)             // authors
+			is.Equal(e.Publisher, "Puffin")             // publisher
+			is.Equal(e.Year, "1988")                    // year
+			is.Equal(len(e.ISBNs), 2)                   // isbns in entry
+			is.Equal(e.ISBNs[0].Value, "0-14-032872-6") // value as given
+			is.Equal(e.ISBNs[0].Qualifier, "pbk.")      // first qualifier
+			is.Equal(e.ISBNs[1].Qualifier, "hbk.")      // second qualifier
+			is.Equal(e.Valid(), []isbn.ISBN{fox, fox})  // both valid
+
+			e = entries[1]
+			is.Equal(e.Title, "The Panda's Thumb")            // concatenated title
+			is.Equal(e.Year, "1977")                          // year
+			is.True(errors.Is(e.ISBNs[0].Err, isbn.ErrValue)) // invalid check digit
+		})
+	}
+}
+
+func TestReadBibTeX(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	entries := read(t, "testdata/books.bib", ReadBibTeX)
+	is.Equal(len(entries), 3) // @string and @comment skipped
+
+	is.Equal(entries[1].Type, "book")                           // type is lower case
+	is.Equal(entries[1].Book().ISBN, panda)                     // first valid isbn
+	is.Equal(entries[2].Title, "No ISBN & no year")             // escaped character
+	is.True(errors.Is(entries[2].ISBNs[0].Err, isbn.ErrFormat)) // garbage isbn
+
+	_, err := ReadBibTeX(strings.NewReader("@book{key, title = {unterminated}"))
+	is.True(err != nil) // unterminated entry
+}
+
+func TestReadRIS(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	tt := []struct {
+		desc string
+		data string
+	}{
+		{"tag before TY", "AU  - Dahl, Roald\nER  - \n"},
+		{"missing ER", "TY  - BOOK\nTI  - Fantastic Mr Fox\n"},
+		{"nested TY", "TY  - BOOK\nTY  - BOOK\nER  - \n"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := ReadRIS(strings.NewReader(tc.data))
+			is.True(err != nil) // invalid ris
+		})
+	}
+}
+
+func TestReadRISContinuation(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	data := "TY  - BOOK\n" +
+		"TI  - Fantastic\n" +
+		"  Mr Fox\n" +
+		"TI  - Another title\n" +
+		"  wrapped\n" +
+		"AB  - A fox outwits\n" +
+		"  three farmers.\n" +
+		"N1  - A note\n" +
+		"  wrapped\n" +
+		"SN  - 0-14-032872-6\n" +
+		"  (pbk.)\n" +
+		"ER  - \n" +
+		"  after the record\n"
+
+	entries, err := ReadRIS(strings.NewReader(data))
+	is.NoErr(err)                                        // continuation lines of ignored tags
+	is.Equal(len(entries), 1)                            // one record
+	is.Equal(entries[0].Title, "Fantastic Mr Fox")       // wrapped title
+	is.Equal(len(entries[0].ISBNs), 1)                   // one isbn
+	is.Equal(entries[0].ISBNs[0].Qualifier, "pbk.")      // wrapped isbn qualifier
+	is.Equal(entries[0].ISBNs[0].Value, "0-14-032872-6") // isbn value
+
+	_, err = ReadRIS(strings.NewReader("  before TY\nTY  - BOOK\nER  - \n"))
+	is.True(err != nil) // continuation line before the first TY
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+
+	books := []metadata.Book{
+		{
+			ISBN:      fox,
+			Title:     "Fantastic Mr Fox",
+			Authors:   []string{"Roald Dahl"},
+			Publisher: "Puffin",
+			Published: "October 1, 1988",
+		},
+		{
+			ISBN:      panda,
+			Title:     "The Panda's Thumb",
+			Subtitle:  "More Reflections in Natural History",
+			Authors:   []string{"Stephen Jay Gould"},
+			Publisher: "W. W. Norton & Company",
+			Published: "1980",
+		},
+	}
+
+	tt := []struct {
+		desc  string
+		write func(io.Writer, ...metadata.Book) error
+		read  func(io.Reader) ([]Entry, error)
+	}{
+		{"bibtex", WriteBibTeX, ReadBibTeX},
+		{"ris", WriteRIS, ReadRIS},
+		{"csl-json", WriteCSLJSON, ReadCSLJSON},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			is := is.New(t)
+
+			var buf bytes.Buffer
+			is.NoErr(tc.write(&buf, books...)) // writing books
+
+			entries, err := tc.read(&buf)
+			is.NoErr(err)             // reading written books
+			is.Equal(len(entries), 2) // entries read
+
+			is.Equal(entries[0].Key, "isbn9780140328721") // keyed by isbn
+			is.Equal(entries[1].Book(), metadata.Book{
+				ISBN:      panda,
+				Title:     "The Panda's Thumb: More Reflections in Natural History",
+				Authors:   []string{"Stephen Jay Gould"},
+				Publisher: "W. W. Norton & Company",
+				Published: "1980",
+			}) // round trip
+			is.True(reflect.DeepEqual(entries[0].Valid(), []isbn.ISBN{fox})) // isbn round trip
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package citation
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/adoublef-go/isbn/metadata"
+)
+
+type cslItem struct {
+	ID        any       `json:"id"`
+	Type      string    `json:"type"`
+	Title     string    `json:"title,omitempty"`
+	Author    []cslName `json:"author,omitempty"`
+	Publisher string    `json:"publisher,omitempty"`
+	Issued    *cslDate  `json:"issued,omitempty"`
+	ISBN      string    `json:"ISBN,omitempty"`
+}
+
+type cslName struct {
+	Family  string `json:"family,omitempty"`
+	Given   string `json:"given,omitempty"`
+	Literal string `json:"literal,omitempty"`
+}
+
+type cslDate struct {
+	DateParts [][]any `json:"date-parts,omitempty"`
+	Raw       string  `json:"raw,omitempty"`
+	Literal   string  `json:"literal,omitempty"`
+}
+
+// ReadCSLJSON reads the items of a CSL-JSON array, or a single item.
+func ReadCSLJSON(r io.Reader) ([]Entry, error) {
+	var raw json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("citation: csl-json: %w", err)
+	}
+
+	var items []cslItem
+	if trimmed := strings.TrimSpace(string(raw)); strings.HasPrefix(trimmed, "{") {
+		var item cslItem
+		if err := json.Unmarshal(raw, &item); err != nil {
+			return nil, fmt.Errorf("citation: csl-json: %w", err)
+		}
+		items = []cslItem{item}
+	} else if err := json.Unmarshal(raw, &items); err != nil {
+		return nil, fmt.Errorf("citation: csl-json: %w", err)
+	}
+
+	entries := make([]Entry, 0, len(items))
+	for _, item := range items {
+		e := Entry{
+			Type:      item.Type,
+			Title:     item.Title,
+			Publisher: item.Publisher,
+			ISBNs:     ParseISBNs(item.ISBN),
+		}
+		// ids are strings or numbers
+		if item.ID != nil {
+			e.Key = fmt.Sprint(item.ID)
+		}
+		for _, a := range item.Author {
+			e.Authors = append(e.Authors, a.name())
+		}
+		if d := item.Issued; d != nil {
+			switch {
+			case len(d.DateParts) > 0 && len(d.DateParts[0]) > 0:
+				e.Year = fmt.Sprint(d.DateParts[0][0])
+			case d.Raw != "":
+				e.Year = year(d.Raw)
+			case d.Literal != "":
+				e.Year = year(d.Literal)
+			}
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func (n cslName) name() string {
+	if n.Literal != "" {
+		return n.Literal
+	}
+	return strings.TrimSpace(n.Given + " " + n.Family)
+}
+
+// WriteCSLJSON writes books as an array of CSL-JSON book items, with their
+// ISBN as id.
+func WriteCSLJSON(w io.Writer, books ...metadata.Book) error {
+	items := make([]cslItem, 0, len(books))
+	for _, book := range books {
+		e := fromBook(book)
+		item := cslItem{
+			ID:        e.Key,
+			Type:      "book",
+			Title:     e.Title,
+			Publisher: e.Publisher,
+			ISBN:      formatISBNs(e.ISBNs),
+		}
+		for _, a := range e.Authors {
+			// names are not split, as their order is not known
+			item.Author = append(item.Author, cslName{Literal: a})
+		}
+		if y, err := strconv.Atoi(e.Year); err == nil {
+			item.Issued = &cslDate{DateParts: [][]any{{y}}}
+		}
+		items = append(items, item)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(items)
+}
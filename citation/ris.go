@@ -0,0 +1,148 @@
+package citation
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/adoublef-go/isbn/metadata"
+)
+
+// ReadRIS reads the records of a RIS file. Each record starts with a TY tag
+// and ends with an ER tag; lines that are not tags continue the value of the
+// previous tag, and are ignored if that tag is not read, such as AB.
+func ReadRIS(r io.Reader) ([]Entry, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+
+	var (
+		entries []Entry
+		e       *Entry
+		n       int
+		// last points at the value of the previous tag, for continuation
+		// lines, or at ignored if the tag is not read. It is nil before
+		// the first TY.
+		last    *string
+		ignored string
+	)
+	for sc.Scan() {
+		n++
+		line := strings.TrimRight(sc.Text(), " \r")
+		if n == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		tag, value, ok := risTag(line)
+		if !ok {
+			if last == nil {
+				return nil, fmt.Errorf("citation: ris line %d: expected tag", n)
+			}
+			*last += " " + strings.TrimSpace(line)
+			continue
+		}
+
+		if tag == "TY" {
+			if e != nil {
+				return nil, fmt.Errorf("citation: ris line %d: TY before ER", n)
+			}
+			e = &Entry{Type: value}
+			last = &ignored
+			continue
+		}
+		if e == nil {
+			return nil, fmt.Errorf("citation: ris line %d: %s before TY", n, tag)
+		}
+
+		last = &ignored
+		switch tag {
+		case "ER":
+			entries = append(entries, finishRIS(*e))
+			e = nil
+		case "ID":
+			e.Key = value
+		case "TI", "T1", "BT":
+			if e.Title == "" {
+				e.Title = value
+				last = &e.Title
+			}
+		case "AU", "A1":
+			e.Authors = append(e.Authors, value)
+			last = &e.Authors[len(e.Authors)-1]
+		case "PB":
+			e.Publisher = value
+			last = &e.Publisher
+		case "PY", "Y1", "DA":
+			if e.Year == "" {
+				e.Year = value
+			}
+		case "SN":
+			// ISBNs are parsed on ER, once continuation lines are known
+			e.ISBNs = append(e.ISBNs, ISBN{Value: value})
+			last = &e.ISBNs[len(e.ISBNs)-1].Value
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if e != nil {
+		return nil, fmt.Errorf("citation: ris: missing ER")
+	}
+	return entries, nil
+}
+
+// risTag splits a line of the form "XX  - value".
+func risTag(line string) (tag, value string, ok bool) {
+	if len(line) < 5 || line[2:5] != "  -" {
+		return "", "", false
+	}
+	tag = line[:2]
+	if tag[0] < 'A' || tag[0] > 'Z' || !(tag[1] >= 'A' && tag[1] <= 'Z' || tag[1] >= '0' && tag[1] <= '9') {
+		return "", "", false
+	}
+	return tag, strings.TrimSpace(line[5:]), true
+}
+
+func finishRIS(e Entry) Entry {
+	// PY is of the form YYYY/MM/DD/other, DA of YYYY/MM/DD
+	if y, _, _ := strings.Cut(e.Year, "/"); y != "" {
+		e.Year = y
+	}
+
+	sn := e.ISBNs
+	e.ISBNs = nil
+	for _, v := range sn {
+		e.ISBNs = append(e.ISBNs, ParseISBNs(v.Value)...)
+	}
+	return e
+}
+
+// WriteRIS writes books as BOOK records with their ISBN as ID.
+func WriteRIS(w io.Writer, books ...metadata.Book) error {
+	bw := bufio.NewWriter(w)
+	for _, book := range books {
+		e := fromBook(book)
+
+		tag := func(name, value string) {
+			if value != "" {
+				fmt.Fprintf(bw, "%s  - %s\r\n", name, value)
+			}
+		}
+		tag("TY", "BOOK")
+		tag("ID", e.Key)
+		tag("TI", e.Title)
+		for _, a := range e.Authors {
+			tag("AU", a)
+		}
+		tag("PB", e.Publisher)
+		tag("PY", e.Year)
+		for _, v := range e.ISBNs {
+			tag("SN", formatISBNs([]ISBN{v}))
+		}
+		bw.WriteString("ER  - \r\n\r\n")
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/adoublef-go/isbn"
+	"github.com/adoublef-go/isbn/barcode"
+)
+
+const barcodeUsage = "[-o dir] [-format svg|png|eps] [-scale n] [-height n] [-notext] [-name template] [-json] [-q] [-f file] [-csv column [-header] | -jsonl field] [isbn[+addon]...]"
+
+var barcodeCmd = &command{barcodeUsage, barcodeFiles}
+
+// defaultName names barcode files by their ISBN 13 and add-on.
+const defaultName = "{{.ISBN}}{{if .AddOn}}-{{.AddOn}}{{end}}"
+
+// barcodeName is the data of the -name template.
+type barcodeName struct {
+	// ISBN is the ISBN 13, ISBN10 the ISBN 10 if there is one, and
+	// Hyphenated the hyphenated ISBN 13 if it can be hyphenated.
+	ISBN, ISBN10, Hyphenated string
+	AddOn                    string
+}
+
+var drawers = map[string]func(barcode.Barcode, *os.File, *barcode.Options) error{
+	"svg": func(b barcode.Barcode, f *os.File, o *barcode.Options) error { return b.SVG(f, o) },
+	"png": func(b barcode.Barcode, f *os.File, o *barcode.Options) error { return b.PNG(f, o) },
+	"eps": func(b barcode.Barcode, f *os.File, o *barcode.Options) error { return b.EPS(f, o) },
+}
+
+// barcodeFiles writes a barcode file for each ISBN, optionally followed by
+// + or a space and an EAN-5 add-on, to the output directory, and writes the
+// name of each file written. Invalid ISBNs and add-ons are reported.
+func barcodeFiles(env *env, args []string) int {
+	fs := env.flags("barcode", barcodeUsage)
+	dir := fs.String("o", ".", "write files to `dir`")
+	format := fs.String("format", "svg", "write files as `svg`, png or eps")
+	var o barcode.Options
+	fs.Float64Var(&o.Scale, "scale", 1, "draw a module `n` pixels, or points for eps, wide")
+	fs.Float64Var(&o.Height, "height", barcode.DefaultHeight, "draw bars `n` modules high")
+	fs.BoolVar(&o.NoText, "notext", false, "leave out the text above and below the bars")
+	name := fs.String("name", defaultName, "name files with a text/template `template`, without extension, of .ISBN, .ISBN10, .Hyphenated and .AddOn")
+	var in inputFlags
+	in.register(fs)
+	r := &reporter{env: env}
+	r.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+
+	draw, ok := drawers[*format]
+	if !ok {
+		fmt.Fprintf(env.stderr, "isbn: unknown format %q\n", *format)
+		fs.Usage()
+		return exitError
+	}
+	tmpl, err := template.New("name").Option("missingkey=error").Parse(*name)
+	if err != nil {
+		fmt.Fprintln(env.stderr, "isbn: -name:", err)
+		return exitError
+	}
+	if err := os.MkdirAll(*dir, 0o755); err != nil {
+		fmt.Fprintln(env.stderr, "isbn:", err)
+		return exitError
+	}
+
+	err = in.each(env, fs.Args(), func(in input) error {
+		if in.err != nil {
+			return r.fail(in, in.err)
+		}
+
+		value, addOn := splitAddOn(in.value)
+		v, err := isbn.Parse(value)
+		if err != nil {
+			return r.fail(in, err)
+		}
+		b, err := barcode.New(v, addOn)
+		if err != nil {
+			return r.fail(in, err)
+		}
+
+		data := barcodeName{ISBN: v.String(), AddOn: addOn}
+		data.ISBN10, _ = v.ISBN10()
+		data.Hyphenated, _ = v.Hyphenate()
+		var sb strings.Builder
+		if err := tmpl.Execute(&sb, data); err != nil {
+			return err
+		}
+		path, err := outputPath(*dir, sb.String()+"."+*format)
+		if err != nil {
+			return err
+		}
+
+		// a failure to write a file stops the command, as it likely
+		// affects the others
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		if err := draw(b, f, &o); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		return r.ok(in, v, path)
+	})
+	return r.status(err)
+}
+
+// splitAddOn splits an ISBN from an add-on following it after + or spaces.
+func splitAddOn(s string) (value, addOn string) {
+	if i := strings.LastIndexAny(s, "+ \t"); i >= 0 {
+		return strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+1:])
+	}
+	return s, ""
+}
+
+// outputPath returns the path of a file named within dir, rejecting names
+// that would be outside of it.
+func outputPath(dir, name string) (string, error) {
+	name = filepath.Clean(name)
+	if name == "." || filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+		return "", errors.New("-name: " + name + " is not within the output directory")
+	}
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	return path, nil
+}
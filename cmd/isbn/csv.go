@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/adoublef-go/isbn/csvx"
+)
+
+const csvUsage = "-columns list [-form 13|10|hyphen] [-repair] [-assigned] [-rejects file] [-noheader] [-j n] [-o file] [file]"
+
+var csvCmd = &command{csvUsage, csvFile}
+
+var csvForms = map[string]csvx.Form{
+	"13":     csvx.ISBN13,
+	"10":     csvx.ISBN10,
+	"hyphen": csvx.Hyphenated,
+}
+
+// csvFile validates, and optionally repairs, the ISBN columns of a CSV file,
+// appending status columns to each row. A summary is written to standard
+// error, and the command exits with status 1 if any ISBN is invalid.
+func csvFile(env *env, args []string) int {
+	fs := env.flags("csv", csvUsage)
+	columns := fs.String("columns", "", "comma separated `list` of ISBN columns, by header name or 1-based index")
+	form := fs.String("form", "13", "write ISBNs in the `form` 13, 10 or hyphen")
+	var p csvx.Processor
+	fs.BoolVar(&p.Repair, "repair", false, "rewrite valid ISBNs in -form and suggest fixes for invalid ones")
+	fs.BoolVar(&p.Assigned, "assigned", false, "reject ISBNs whose registration group or registrant is not assigned")
+	fs.BoolVar(&p.NoHeader, "noheader", false, "the first row is not a header")
+	fs.IntVar(&p.Workers, "j", runtime.NumCPU(), "process `n` rows at a time")
+	rejects := fs.String("rejects", "", "write rows with an invalid ISBN to `file` instead of the output")
+	output := fs.String("o", "", "write to `file` instead of standard output")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+
+	var ok bool
+	if p.Form, ok = csvForms[*form]; !ok || *columns == "" || fs.NArg() > 1 {
+		fs.Usage()
+		return exitError
+	}
+	for _, c := range strings.Split(*columns, ",") {
+		p.Columns = append(p.Columns, strings.TrimSpace(c))
+	}
+
+	stats, err := func() (csvx.Stats, error) {
+		var r io.Reader = env.stdin
+		if fs.NArg() == 1 && fs.Arg(0) != "-" {
+			f, err := os.Open(fs.Arg(0))
+			if err != nil {
+				return csvx.Stats{}, err
+			}
+			defer f.Close()
+			r = f
+		}
+
+		w := env.stdout
+		if *output != "" {
+			f, err := os.Create(*output)
+			if err != nil {
+				return csvx.Stats{}, err
+			}
+			defer f.Close()
+			w = f
+		}
+		if *rejects != "" {
+			f, err := os.Create(*rejects)
+			if err != nil {
+				return csvx.Stats{}, err
+			}
+			defer f.Close()
+			p.Rejects = f
+		}
+
+		return p.Process(env.ctx, r, w)
+	}()
+	if err != nil {
+		fmt.Fprintln(env.stderr, "isbn:", err)
+		return exitError
+	}
+
+	fmt.Fprintf(env.stderr, "%d rows, %d valid, %d repaired, %d invalid, %d empty, %d rejected\n",
+		stats.Rows, stats.Valid, stats.Repaired, stats.Invalid, stats.Empty, stats.Rejected)
+	if stats.Invalid > 0 {
+		return exitInvalid
+	}
+	return exitOK
+}
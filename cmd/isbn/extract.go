@@ -0,0 +1,288 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/adoublef-go/isbn"
+)
+
+const extractUsage = "[-format json|csv] [-j n] [-ext list] [path...]"
+
+var extractCmd = &command{extractUsage, extract}
+
+// extensions are the files searched for ISBNs by default.
+const extensions = ".txt,.text,.html,.htm,.xhtml,.csv,.md,.markdown"
+
+// candidate matches what may be an ISBN 13 or ISBN 10, with its digits
+// separated by single hyphens or spaces. The ISBN 13 form is tried first.
+var candidate = regexp.MustCompile(`97[89](?:[- ]?[0-9]){9}[- ]?[0-9]|[0-9](?:[- ]?[0-9]){8}[- ]?[0-9Xx]`)
+
+// match is an ISBN found in a file.
+type match struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	Match  string `json:"match"`
+	// ISBN is the ISBN 13 of a valid match.
+	ISBN  string `json:"isbn,omitempty"`
+	Valid bool   `json:"valid"`
+	Error string `json:"error,omitempty"`
+}
+
+// summary counts the matches of an extract.
+type summary struct {
+	Files   int `json:"files"`
+	Matches int `json:"matches"`
+	Valid   int `json:"valid"`
+	Invalid int `json:"invalid"`
+	// InvalidFiles counts the invalid matches of each file that has any.
+	InvalidFiles map[string]int `json:"invalid_files,omitempty"`
+}
+
+// extract walks the paths, by default the working directory, and reports
+// every ISBN found in their text, HTML, CSV and Markdown files, whether its
+// check digit is valid or not. Files are searched concurrently, but reported
+// in the order they were walked. The command exits with status 1 if any
+// match has an invalid check digit.
+func extract(env *env, args []string) int {
+	fs := env.flags("extract", extractUsage)
+	format := fs.String("format", "json", "write the report as `json` or csv")
+	jobs := fs.Int("j", runtime.NumCPU(), "search `n` files at a time")
+	exts := fs.String("ext", extensions, "comma separated `list` of file extensions to search")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if *format != "json" && *format != "csv" {
+		fmt.Fprintf(env.stderr, "isbn: unknown format %q\n", *format)
+		fs.Usage()
+		return exitError
+	}
+	if *jobs < 1 {
+		*jobs = 1
+	}
+
+	paths := fs.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	files, err := walk(paths, strings.Split(*exts, ","))
+	if err != nil {
+		fmt.Fprintln(env.stderr, "isbn:", err)
+		return exitError
+	}
+
+	results := make([][]match, len(files))
+	errs := make([]error, len(files))
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < *jobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				results[i], errs[i] = extractFile(files[i])
+			}
+		}()
+	}
+	for i := range files {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+
+	status := exitOK
+	sum := summary{Files: len(files)}
+	var matches []match
+	for i, ms := range results {
+		if errs[i] != nil {
+			fmt.Fprintln(env.stderr, "isbn:", errs[i])
+			status = exitError
+			continue
+		}
+		for _, m := range ms {
+			sum.Matches++
+			if m.Valid {
+				sum.Valid++
+				continue
+			}
+			sum.Invalid++
+			if sum.InvalidFiles == nil {
+				sum.InvalidFiles = map[string]int{}
+			}
+			sum.InvalidFiles[m.File]++
+		}
+		matches = append(matches, ms...)
+	}
+
+	if *format == "csv" {
+		err = writeMatchesCSV(env.stdout, matches)
+		writeSummary(env.stderr, sum)
+	} else {
+		if matches == nil {
+			matches = []match{}
+		}
+		enc := json.NewEncoder(env.stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(struct {
+			Matches []match `json:"matches"`
+			Summary summary `json:"summary"`
+		}{matches, sum})
+	}
+	switch {
+	case err != nil:
+		fmt.Fprintln(env.stderr, "isbn:", err)
+		return exitError
+	case status == exitOK && sum.Invalid > 0:
+		return exitInvalid
+	default:
+		return status
+	}
+}
+
+// walk returns the files of paths with one of the extensions, skipping
+// hidden directories. A path that is a file is returned whatever its
+// extension.
+func walk(paths, exts []string) ([]string, error) {
+	want := map[string]bool{}
+	for _, ext := range exts {
+		if ext = strings.ToLower(strings.TrimSpace(ext)); ext != "" {
+			if !strings.HasPrefix(ext, ".") {
+				ext = "." + ext
+			}
+			want[ext] = true
+		}
+	}
+
+	var files []string
+	for _, root := range paths {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			switch {
+			case err != nil:
+				return err
+			case path == root && !d.IsDir():
+				files = append(files, path)
+			case d.IsDir():
+				if path != root && strings.HasPrefix(d.Name(), ".") {
+					return filepath.SkipDir
+				}
+			case d.Type().IsRegular() && want[strings.ToLower(filepath.Ext(path))]:
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+func extractFile(name string) ([]match, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	ms, err := extractReader(f, name)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return ms, nil
+}
+
+func extractReader(r io.Reader, name string) ([]match, error) {
+	var ms []match
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+	for n := 1; sc.Scan(); n++ {
+		line := sc.Text()
+		for _, loc := range candidate.FindAllStringIndex(line, -1) {
+			start, end := loc[0], loc[1]
+			if !boundary(line, start, end) {
+				continue
+			}
+
+			m := match{
+				File:   name,
+				Line:   n,
+				Column: utf8.RuneCountInString(line[:start]) + 1,
+				Match:  line[start:end],
+			}
+			digits := strings.NewReplacer("-", "", " ", "").Replace(m.Match)
+			v, err := isbn.Parse(strings.ToUpper(digits))
+			if err != nil {
+				m.Error = err.Error()
+			} else {
+				m.ISBN, m.Valid = v.String(), true
+			}
+			ms = append(ms, m)
+		}
+	}
+	return ms, sc.Err()
+}
+
+// boundary reports whether a candidate is not part of a longer word or
+// number.
+func boundary(line string, start, end int) bool {
+	if r, _ := utf8.DecodeLastRuneInString(line[:start]); start > 0 && (isWord(r) || r == '-') {
+		return false
+	}
+	if r, _ := utf8.DecodeRuneInString(line[end:]); end < len(line) && isWord(r) {
+		return false
+	}
+	// a hyphen followed by a digit continues the number
+	if end+1 < len(line) && line[end] == '-' && line[end+1] >= '0' && line[end+1] <= '9' {
+		return false
+	}
+	return true
+}
+
+func isWord(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' }
+
+func writeMatchesCSV(w io.Writer, matches []match) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"file", "line", "column", "match", "isbn", "valid", "error"})
+	for _, m := range matches {
+		cw.Write([]string{
+			m.File,
+			strconv.Itoa(m.Line),
+			strconv.Itoa(m.Column),
+			m.Match,
+			m.ISBN,
+			strconv.FormatBool(m.Valid),
+			m.Error,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeSummary(w io.Writer, sum summary) {
+	fmt.Fprintf(w, "%d files, %d matches, %d valid, %d invalid\n", sum.Files, sum.Matches, sum.Valid, sum.Invalid)
+
+	files := make([]string, 0, len(sum.InvalidFiles))
+	for name := range sum.InvalidFiles {
+		files = append(files, name)
+	}
+	sort.Strings(files)
+	for _, name := range files {
+		fmt.Fprintf(w, "%s: %d invalid\n", name, sum.InvalidFiles[name])
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// errInput is the kind of an input that could not be read as an ISBN at
+// all, such as a JSON line without the field.
+var errInput = errors.New("invalid input")
+
+// input is a value read as an ISBN, and where it was read.
+type input struct {
+	source string
+	line   int
+	value  string
+	// err is set if no value could be read from the line.
+	err error
+}
+
+func (in input) String() string { return fmt.Sprintf("%s:%d", in.source, in.line) }
+
+// inputFlags are the flags selecting where ISBNs are read from.
+type inputFlags struct {
+	files  files
+	csv    string
+	header bool
+	jsonl  string
+}
+
+type files []string
+
+func (f *files) String() string     { return strings.Join(*f, ",") }
+func (f *files) Set(s string) error { *f = append(*f, s); return nil }
+
+func (f *inputFlags) register(fs *flag.FlagSet) {
+	fs.Var(&f.files, "f", "read ISBNs from `file`, - for standard input; may be repeated")
+	fs.StringVar(&f.csv, "csv", "", "read ISBNs from a CSV `column`, by header name or 1-based index")
+	fs.BoolVar(&f.header, "header", false, "the first CSV row is a header, as it is when -csv names a column")
+	fs.StringVar(&f.jsonl, "jsonl", "", "read ISBNs from a `field` of JSON lines")
+}
+
+// each calls fn for every ISBN of the arguments, or else of the files or
+// standard input. It stops at the first error fn returns, or error reading
+// a file; an input that is not valid CSV or JSON is passed to fn with err
+// set.
+func (f *inputFlags) each(env *env, args []string, fn func(input) error) error {
+	if f.csv != "" && f.jsonl != "" {
+		return errors.New("-csv and -jsonl are exclusive")
+	}
+	if f.header && f.csv == "" {
+		return errors.New("-header requires -csv")
+	}
+
+	if len(args) > 0 {
+		if len(f.files) > 0 {
+			return errors.New("ISBNs given both as arguments and with -f")
+		}
+		for i, arg := range args {
+			if err := fn(input{source: "arg", line: i + 1, value: arg}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	names := f.files
+	if len(names) == 0 {
+		names = files{"-"}
+	}
+	for _, name := range names {
+		if err := f.read(env, name, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *inputFlags) read(env *env, name string, fn func(input) error) error {
+	var r io.Reader
+	source := name
+	if name == "-" {
+		r, source = env.stdin, "stdin"
+	} else {
+		file, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		r = file
+	}
+
+	switch {
+	case f.csv != "":
+		return readCSV(r, source, f.csv, f.header, fn)
+	case f.jsonl != "":
+		return readJSONL(r, source, f.jsonl, fn)
+	default:
+		return readLines(r, source, fn)
+	}
+}
+
+// readLines reads an ISBN per line, skipping blank lines.
+func readLines(r io.Reader, source string, fn func(input) error) error {
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		if err := fn(input{source: source, line: n, value: line}); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
+
+// readCSV reads the ISBNs of a column, given by a 1-based index or the name
+// of a column in the header row. The header row is skipped if header is set
+// or the column is given by name.
+func readCSV(r io.Reader, source, column string, header bool, fn func(input) error) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+
+	col, err := strconv.Atoi(column)
+	byIndex := err == nil
+	if byIndex && col < 1 {
+		return fmt.Errorf("-csv %d: columns are numbered from 1", col)
+	}
+	col--
+
+	for first := true; ; first = false {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		var perr *csv.ParseError
+		if errors.As(err, &perr) {
+			return fmt.Errorf("%s:%d: %w", source, perr.Line, perr.Err)
+		}
+		if err != nil {
+			return err
+		}
+		line, _ := cr.FieldPos(0)
+
+		if first && byIndex && header {
+			continue
+		}
+		if first && !byIndex {
+			col = -1
+			for i, name := range record {
+				if strings.EqualFold(strings.TrimSpace(name), column) {
+					col = i
+					break
+				}
+			}
+			if col < 0 {
+				return fmt.Errorf("%s: no column %q in header", source, column)
+			}
+			continue
+		}
+
+		in := input{source: source, line: line}
+		if col < len(record) {
+			in.value = strings.TrimSpace(record[col])
+		} else {
+			in.err = fmt.Errorf("%w: no column %s", errInput, column)
+		}
+		if err := fn(in); err != nil {
+			return err
+		}
+	}
+}
+
+// readJSONL reads the ISBNs of a field of JSON lines, which may be a string
+// or a number.
+func readJSONL(r io.Reader, source, field string, fn func(input) error) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+	for n := 1; sc.Scan(); n++ {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		in := input{source: source, line: n}
+		in.value, in.err = jsonField(line, field)
+		if err := fn(in); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
+
+func jsonField(line []byte, field string) (string, error) {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(line, &obj); err != nil {
+		return "", fmt.Errorf("%w: %v", errInput, err)
+	}
+
+	raw, ok := obj[field]
+	if !ok {
+		return "", fmt.Errorf("%w: no field %q", errInput, field)
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return strings.TrimSpace(s), nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(raw, &n); err == nil {
+		return n.String(), nil
+	}
+	return "", fmt.Errorf("%w: field %q is not a string or number", errInput, field)
+}
@@ -0,0 +1,129 @@
+// Command isbn validates, normalizes and extracts ISBNs, and draws their
+// barcodes, for use in shell pipelines.
+//
+// Usage:
+//
+//	isbn validate [flags] [isbn...]
+//	isbn normalize [flags] [isbn...]
+//	isbn extract [flags] [path...]
+//	isbn barcode [flags] [isbn[+addon]...]
+//	isbn csv -columns list [flags] [file]
+//
+// ISBNs are read from the arguments or, if there are none, from the files
+// given with -f or standard input. Files hold one ISBN per line, or with -csv
+// or -jsonl a column of a CSV file or a field of JSON lines. A CSV column
+// named by its header skips the header row; one given by index reads every
+// row, unless -header is set.
+//
+// Each invalid ISBN is reported on standard error with where it was read and
+// why it is invalid, and the command exits with status 1:
+//
+//	$ isbn validate 0140328726 0140328727
+//	arg:2: 0140328727: invalid ISBN value
+//
+// With -json the result of every ISBN is written to standard output as a
+// JSON line instead, with the kind of error, for other tools to consume.
+//
+// Extract searches the text, HTML, CSV and Markdown files of directory trees
+// for ISBNs, and reports where each was found and whether it is valid.
+//
+// Barcode writes the EAN-13 barcode of each ISBN, with an optional EAN-5
+// add-on, to a file of its own:
+//
+//	$ isbn barcode -o barcodes -format png -scale 4 9780140328721+51299
+//	barcodes/9780140328721-51299.png
+//
+// Csv validates the ISBN columns of a CSV file, appending status columns to
+// each row, and with -repair rewrites them in a canonical form and suggests
+// fixes for those with an invalid check digit.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+)
+
+// Exit statuses.
+const (
+	exitOK      = 0
+	exitInvalid = 1
+	// exitError is the status of a usage error, or one that stopped the
+	// command before all inputs were read.
+	exitError = 2
+)
+
+// command is a subcommand of isbn.
+type command struct {
+	usage string
+	// run runs the command with its arguments, returning an exit status.
+	run func(env *env, args []string) int
+}
+
+var commands = map[string]*command{
+	"validate":  validateCmd,
+	"normalize": normalizeCmd,
+	"extract":   extractCmd,
+	"barcode":   barcodeCmd,
+	"csv":       csvCmd,
+}
+
+// env is where a command reads and writes.
+type env struct {
+	// ctx is done when the command is interrupted.
+	ctx            context.Context
+	stdin          io.Reader
+	stdout, stderr io.Writer
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	status := run(&env{ctx, os.Stdin, os.Stdout, os.Stderr}, os.Args[1:])
+	stop()
+	os.Exit(status)
+}
+
+func run(env *env, args []string) int {
+	if len(args) == 0 {
+		usage(env.stderr)
+		return exitError
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(env.stderr, "isbn: unknown command %q\n", args[0])
+		usage(env.stderr)
+		return exitError
+	}
+	return cmd.run(env, args[1:])
+}
+
+// flags returns the flag set of a command, which writes its errors to the
+// standard error of env.
+func (env *env) flags(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet("isbn "+name, flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: isbn %s %s\n", name, usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+func usage(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "usage: isbn command [flags] [args]")
+	fmt.Fprintln(w, "\ncommands:")
+	for _, name := range names {
+		fmt.Fprintf(w, "  %s %s\n", name, commands[name].usage)
+	}
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hyphengolang/prelude/testing/is"
+)
+
+// runCmd runs isbn with args, returning its output and exit status.
+func runCmd(stdin string, args ...string) (stdout, stderr string, status int) {
+	var out, errOut bytes.Buffer
+	status = run(&env{context.Background(), strings.NewReader(stdin), &out, &errOut}, args)
+	return out.String(), errOut.String(), status
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	tt := []struct {
+		desc   string
+		args   []string
+		stdin  string
+		stderr string
+		status int
+	}{
+		{
+			desc:   "valid args",
+			args:   []string{"0140328726", "978-0-7167-0344-0"},
+			status: exitOK,
+		},
+		{
+			desc:   "invalid args",
+			args:   []string{"0140328726", "0140328727", "abc"},
+			stderr: "arg:2: 0140328727: invalid ISBN value\narg:3: abc: invalid ISBN length 3\n",
+			status: exitInvalid,
+		},
+		{
+			desc:   "lines",
+			stdin:  "0140328726\n\n  9780716703440  \n0716703441\n",
+			stderr: "stdin:4: 0716703441: invalid ISBN value\n",
+			status: exitInvalid,
+		},
+		{
+			desc:   "quiet",
+			args:   []string{"-q", "0140328727"},
+			status: exitInvalid,
+		},
+		{
+			desc:   "csv column by name",
+			args:   []string{"-csv", "ISBN"},
+			stdin:  "title,isbn\n\"Fox, Fantastic Mr\",0140328726\nPanda,0716703441\n",
+			stderr: "stdin:3: 0716703441: invalid ISBN value\n",
+			status: exitInvalid,
+		},
+		{
+			desc:   "csv column by index",
+			args:   []string{"-csv", "2"},
+			stdin:  "Fox,0140328726\nShort\n",
+			stderr: "stdin:2: : invalid input: no column 2\n",
+			status: exitInvalid,
+		},
+		{
+			desc:   "csv column by index with header",
+			args:   []string{"-csv", "2", "-header"},
+			stdin:  "title,isbn\nFox,0140328726\nPanda,0716703441\n",
+			stderr: "stdin:3: 0716703441: invalid ISBN value\n",
+			status: exitInvalid,
+		},
+		{
+			desc:   "header without csv",
+			args:   []string{"-header", "0140328726"},
+			status: exitError,
+		},
+		{
+			desc:   "jsonl field",
+			args:   []string{"-jsonl", "isbn"},
+			stdin:  "{\"isbn\": \"0140328726\"}\n{\"isbn\": 9780716703440}\n{}\n",
+			stderr: "stdin:3: : invalid input: no field \"isbn\"\n",
+			status: exitInvalid,
+		},
+		{
+			desc:   "missing csv column",
+			args:   []string{"-csv", "isbn"},
+			stdin:  "title\nFox\n",
+			stderr: "isbn: stdin: no column \"isbn\" in header\n",
+			status: exitError,
+		},
+		{
+			desc:   "unknown command",
+			args:   []string{"check"},
+			status: exitError,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			args := tc.args
+			if len(args) == 0 || args[0] != "check" {
+				args = append([]string{"validate"}, args...)
+			}
+
+			stdout, stderr, status := runCmd(tc.stdin, args...)
+			is.Equal(status, tc.status) // exit status
+			is.Equal(stdout, "")        // nothing written
+			if tc.status != exitError {
+				is.Equal(stderr, tc.stderr) // diagnostics
+			}
+		})
+	}
+}
+
+func TestValidateJSON(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	stdout, stderr, status := runCmd("", "validate", "-json", "0140328726", "9789990000000")
+	is.Equal(status, exitInvalid) // invalid isbn
+	is.Equal(stderr, "")          // diagnostics written as json
+	is.Equal(stdout, `{"source":"arg","line":1,"input":"0140328726","valid":true,"isbn":"9780140328721"}`+"\n"+
+		`{"source":"arg","line":2,"input":"9789990000000","valid":false,"error":"invalid ISBN value","kind":"checksum"}`+"\n") // json results
+
+	stdout, _, _ = runCmd("", "validate", "-json", "-assigned", "9790727887794")
+	is.True(strings.Contains(stdout, `"kind":"group"`)) // unassigned group
+}
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "isbns.txt")
+	is.NoErr(os.WriteFile(file, []byte("0-14-032872-6\n9791032305690\n"), 0o644)) // writing isbns
+
+	tt := []struct {
+		desc   string
+		args   []string
+		stdin  string
+		stdout string
+		stderr string
+		status int
+	}{
+		{
+			desc:   "isbn 13",
+			args:   []string{"-f", file},
+			stdout: "9780140328721\n9791032305690\n",
+			status: exitOK,
+		},
+		{
+			desc:   "isbn 10",
+			args:   []string{"-to", "10", "-f", file},
+			stdout: "0140328726\n",
+			stderr: file + ":2: 9791032305690: ISBN has no ISBN 10 form\n",
+			status: exitInvalid,
+		},
+		{
+			desc:   "hyphenated",
+			args:   []string{"-to", "hyphen", "0140328726", "9780716703440"},
+			stdout: "978-0-14-032872-1\n978-0-7167-0344-0\n",
+			status: exitOK,
+		},
+		{
+			desc:   "csv column by name",
+			args:   []string{"-csv", "isbn"},
+			stdin:  "title,isbn\nFox,0-14-032872-6\n",
+			stdout: "9780140328721\n",
+			status: exitOK,
+		},
+		{
+			desc:   "csv column by index",
+			args:   []string{"-csv", "2"},
+			stdin:  "title,isbn\nFox,0-14-032872-6\n",
+			stdout: "9780140328721\n",
+			stderr: "stdin:1: isbn: invalid ISBN length 4\n",
+			status: exitInvalid,
+		},
+		{
+			desc:   "csv column by index with header",
+			args:   []string{"-csv", "2", "-header"},
+			stdin:  "title,isbn\nFox,0-14-032872-6\n",
+			stdout: "9780140328721\n",
+			status: exitOK,
+		},
+		{
+			desc:   "unknown form",
+			args:   []string{"-to", "9", "0140328726"},
+			status: exitError,
+		},
+		{
+			desc:   "missing file",
+			args:   []string{"-f", filepath.Join(dir, "missing.txt")},
+			status: exitError,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			stdout, stderr, status := runCmd(tc.stdin, append([]string{"normalize"}, tc.args...)...)
+			is.Equal(status, tc.status) // exit status
+			is.Equal(stdout, tc.stdout) // normalized isbns
+			if tc.status != exitError {
+				is.Equal(stderr, tc.stderr) // diagnostics
+			}
+		})
+	}
+}
+
+func TestExtract(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"notes.md":       "# Reading\n\n* Fantastic Mr Fox, ISBN 978-0-14-032872-1\n* phone 0123456789012345\n",
+		"books/list.csv": "title,isbn\nPanda,0716703441\nFox,0 14 032872 6\n",
+		"books/a.html":   "<p data-isbn=\"9780716703440\">The Panda’s Thumb 0716703440</p>\n",
+		"books/skip.go":  "// 9780140328721\n",
+		".git/HEAD.txt":  "9780140328721\n",
+	}
+	for name, data := range files {
+		name = filepath.Join(dir, name)
+		is.NoErr(os.MkdirAll(filepath.Dir(name), 0o755))  // making directory
+		is.NoErr(os.WriteFile(name, []byte(data), 0o644)) // writing file
+	}
+
+	stdout, stderr, status := runCmd("", "extract", "-format", "csv", "-j", "2", dir)
+	is.Equal(status, exitInvalid) // invalid check digit found
+	is.Equal(stdout, strings.Join([]string{
+		"file,line,column,match,isbn,valid,error",
+		filepath.Join(dir, "books/a.html") + ",1,15,9780716703440,9780716703440,true,",
+		filepath.Join(dir, "books/a.html") + ",1,48,0716703440,9780716703440,true,",
+		filepath.Join(dir, "books/list.csv") + ",2,7,0716703441,,false,invalid ISBN value",
+		filepath.Join(dir, "books/list.csv") + ",3,5,0 14 032872 6,9780140328721,true,",
+		filepath.Join(dir, "notes.md") + ",3,26,978-0-14-032872-1,9780140328721,true,",
+		"",
+	}, "\n")) // report in walk order
+	is.Equal(stderr, "3 files, 5 matches, 4 valid, 1 invalid\n"+
+		filepath.Join(dir, "books/list.csv")+": 1 invalid\n") // summary
+
+	stdout, _, status = runCmd("", "extract", filepath.Join(dir, "notes.md"))
+	is.Equal(status, exitOK)                          // all valid
+	is.True(strings.Contains(stdout, `"files": 1`))   // json summary
+	is.True(strings.Contains(stdout, `"column": 26`)) // json match
+}
+
+func TestBarcode(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	dir := t.TempDir()
+	stdout, stderr, status := runCmd("9780140328721+51299\n0716703440\n0716703441\n9780140328721 5129\n",
+		"barcode", "-o", dir, "-format", "eps", "-name", "{{.Hyphenated}}/{{.ISBN10}}{{if .AddOn}}+{{.AddOn}}{{end}}")
+	is.Equal(status, exitInvalid) // invalid isbn and add-on
+	is.Equal(stdout, filepath.Join(dir, "978-0-14-032872-1", "0140328726+51299.eps")+"\n"+
+		filepath.Join(dir, "978-0-7167-0344-0", "0716703440.eps")+"\n") // files written
+	is.Equal(stderr, "stdin:3: 0716703441: invalid ISBN value\n"+
+		"stdin:4: 9780140328721 5129: barcode: add-on must be 5 digits\n") // diagnostics
+
+	b, err := os.ReadFile(filepath.Join(dir, "978-0-7167-0344-0", "0716703440.eps"))
+	is.NoErr(err)                                              // reading barcode
+	is.True(bytes.HasPrefix(b, []byte("%!PS-Adobe-3.0 EPSF"))) // eps file
+
+	stdout, _, status = runCmd("", "barcode", "-o", dir, "-format", "png", "9780716703440")
+	is.Equal(status, exitOK)                                       // barcode written
+	is.Equal(stdout, filepath.Join(dir, "9780716703440.png")+"\n") // default name
+
+	_, _, status = runCmd("", "barcode", "-o", dir, "-name", "../{{.ISBN}}", "9780716703440")
+	is.Equal(status, exitError) // name outside of directory
+}
+
+func TestCSV(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	dir := t.TempDir()
+	rejects := filepath.Join(dir, "rejects.csv")
+	stdout, stderr, status := runCmd("title,isbn\nFox,0-14-032872-6\nPanda,0716703441\n",
+		"csv", "-columns", "isbn", "-repair", "-form", "hyphen", "-rejects", rejects)
+	is.Equal(status, exitInvalid) // invalid isbn
+	is.Equal(stdout, "title,isbn,isbn_isbn,isbn_status,isbn_error,isbn_suggestions\n"+
+		"Fox,978-0-14-032872-1,978-0-14-032872-1,repaired,,\n") // repaired rows
+	is.Equal(stderr, "2 rows, 0 valid, 1 repaired, 1 invalid, 0 empty, 1 rejected\n") // summary
+
+	b, err := os.ReadFile(rejects)
+	is.NoErr(err)                                                                                           // reading rejects
+	is.True(strings.Contains(string(b), "Panda,0716703441,,invalid,invalid ISBN value,978-0-7167-0344-0 ")) // suggestions
+
+	_, _, status = runCmd("", "csv", "-form", "hyphen")
+	is.Equal(status, exitError) // no columns
+	_, _, status = runCmd("title\nFox\n", "csv", "-columns", "isbn")
+	is.Equal(status, exitError) // unknown column
+}
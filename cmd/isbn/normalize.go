@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/adoublef-go/isbn"
+)
+
+const normalizeUsage = "[-to 13|10|hyphen] [-assigned] [-json] [-q] [-f file] [-csv column [-header] | -jsonl field] [isbn...]"
+
+var normalizeCmd = &command{normalizeUsage, normalize}
+
+// forms are the forms an ISBN is normalized to.
+var forms = map[string]func(isbn.ISBN) (string, error){
+	"13":     func(v isbn.ISBN) (string, error) { return v.String(), nil },
+	"10":     isbn.ISBN.ISBN10,
+	"hyphen": isbn.ISBN.Hyphenate,
+}
+
+// normalize writes the valid inputs in a canonical form, one per line, and
+// reports the others. An ISBN with no ISBN 10 form, or that cannot be
+// hyphenated, is reported as invalid.
+func normalize(env *env, args []string) int {
+	fs := env.flags("normalize", normalizeUsage)
+	to := fs.String("to", "13", "normalize to the ISBN `form` 13, 10 or hyphen")
+	assigned := fs.Bool("assigned", false, "reject ISBNs whose registration group or registrant is not assigned")
+	var in inputFlags
+	in.register(fs)
+	r := &reporter{env: env}
+	r.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+
+	form, ok := forms[*to]
+	if !ok {
+		fmt.Fprintf(env.stderr, "isbn: unknown form %q\n", *to)
+		fs.Usage()
+		return exitError
+	}
+	parse := isbn.Parse
+	if *assigned {
+		parse = isbn.ParseAssigned
+	}
+
+	err := in.each(env, fs.Args(), func(in input) error {
+		if in.err != nil {
+			return r.fail(in, in.err)
+		}
+		v, err := parse(in.value)
+		if err != nil {
+			return r.fail(in, err)
+		}
+		s, err := form(v)
+		if err != nil {
+			return r.fail(in, err)
+		}
+		return r.ok(in, v, s)
+	})
+	return r.status(err)
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/adoublef-go/isbn"
+)
+
+// result is the outcome of an input, as written with -json.
+type result struct {
+	Source string `json:"source"`
+	Line   int    `json:"line"`
+	Input  string `json:"input"`
+	Valid  bool   `json:"valid"`
+	// ISBN is the ISBN 13 of a valid input, and Output its form as written
+	// by the command, if any.
+	ISBN   string `json:"isbn,omitempty"`
+	Output string `json:"output,omitempty"`
+	Error  string `json:"error,omitempty"`
+	Kind   string `json:"kind,omitempty"`
+}
+
+// kinds name the errors of isbn, most specific first, for scripts to tell
+// them apart without matching messages.
+var kinds = []struct {
+	err  error
+	name string
+}{
+	{errInput, "input"},
+	{isbn.ErrLength, "length"},
+	{isbn.ErrFormat, "format"},
+	{isbn.ErrValue, "checksum"},
+	{isbn.ErrGroup, "group"},
+	{isbn.ErrRegistrant, "registrant"},
+	{isbn.ErrRange, "range"},
+	{isbn.ErrPrefix, "prefix"},
+}
+
+func kind(err error) string {
+	for _, k := range kinds {
+		if errors.Is(err, k.err) {
+			return k.name
+		}
+	}
+	return "unknown"
+}
+
+// reporter writes the results of a command, counting the invalid inputs.
+type reporter struct {
+	env   *env
+	json  bool
+	quiet bool
+
+	invalid int
+}
+
+func (r *reporter) register(fs *flag.FlagSet) {
+	fs.BoolVar(&r.json, "json", false, "write the result of every input as a JSON line")
+	fs.BoolVar(&r.quiet, "q", false, "do not report invalid inputs")
+}
+
+// ok reports a valid input, writing output if not empty.
+func (r *reporter) ok(in input, v isbn.ISBN, output string) error {
+	if r.json {
+		return json.NewEncoder(r.env.stdout).Encode(result{
+			Source: in.source,
+			Line:   in.line,
+			Input:  in.value,
+			Valid:  true,
+			ISBN:   v.String(),
+			Output: output,
+		})
+	}
+	if output != "" {
+		_, err := fmt.Fprintln(r.env.stdout, output)
+		return err
+	}
+	return nil
+}
+
+// fail reports an invalid input.
+func (r *reporter) fail(in input, err error) error {
+	r.invalid++
+	if r.json {
+		return json.NewEncoder(r.env.stdout).Encode(result{
+			Source: in.source,
+			Line:   in.line,
+			Input:  in.value,
+			Error:  err.Error(),
+			Kind:   kind(err),
+		})
+	}
+	if !r.quiet {
+		fmt.Fprintf(r.env.stderr, "%s: %s: %v\n", in, in.value, err)
+	}
+	return nil
+}
+
+// status returns the exit status of a command that stopped with err.
+func (r *reporter) status(err error) int {
+	switch {
+	case err != nil:
+		fmt.Fprintln(r.env.stderr, "isbn:", err)
+		return exitError
+	case r.invalid > 0:
+		return exitInvalid
+	default:
+		return exitOK
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import "github.com/adoublef-go/isbn"
+
+const validateUsage = "[-assigned] [-json] [-q] [-f file] [-csv column [-header] | -jsonl field] [isbn...]"
+
+var validateCmd = &command{validateUsage, validate}
+
+// validate reports the inputs that are not valid ISBNs.
+func validate(env *env, args []string) int {
+	fs := env.flags("validate", validateUsage)
+	assigned := fs.Bool("assigned", false, "reject ISBNs whose registration group or registrant is not assigned")
+	var in inputFlags
+	in.register(fs)
+	r := &reporter{env: env}
+	r.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+
+	parse := isbn.Parse
+	if *assigned {
+		parse = isbn.ParseAssigned
+	}
+
+	err := in.each(env, fs.Args(), func(in input) error {
+		if in.err != nil {
+			return r.fail(in, in.err)
+		}
+		v, err := parse(in.value)
+		if err != nil {
+			return r.fail(in, err)
+		}
+		return r.ok(in, v, "")
+	})
+	return r.status(err)
+}
@@ -0,0 +1,152 @@
+// Command olindex builds a local index of books from Open Library data dumps,
+// for resolving ISBNs where external APIs cannot be called.
+//
+// Usage:
+//
+//	olindex [-db books.db] ingest dump.txt.gz...
+//	olindex [-db books.db] lookup isbn...
+//
+// Ingesting the editions dump is enough to look up books, the authors dump
+// adds the names of their authors. An interrupted ingest resumes where it
+// left off when run again.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/adoublef-go/isbn"
+	"github.com/adoublef-go/isbn/metadata"
+	"github.com/adoublef-go/isbn/metadata/olindex"
+)
+
+func main() {
+	db := flag.String("db", "books.db", "path of the index `database`")
+	quiet := flag.Bool("q", false, "do not report progress")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: olindex [flags] ingest dump...\n       olindex [flags] lookup isbn...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() < 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	ix, err := olindex.Open(*db)
+	if err != nil {
+		fatal(err)
+	}
+	defer ix.Close()
+
+	switch cmd, args := flag.Arg(0), flag.Args()[1:]; cmd {
+	case "ingest":
+		err = ingest(ctx, ix, args, *quiet)
+	case "lookup":
+		err = lookup(ctx, ix, args)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		ix.Close()
+		fatal(err)
+	}
+}
+
+func ingest(ctx context.Context, ix *olindex.Index, names []string, quiet bool) error {
+	for _, name := range names {
+		var last time.Time
+		err := ix.Ingest(ctx, name, func(p olindex.Progress) {
+			if quiet || !p.Done && time.Since(last) < time.Second {
+				return
+			}
+			last = time.Now()
+
+			pct := ""
+			if p.Size > 0 {
+				pct = fmt.Sprintf(" %5.1f%%", 100*float64(p.Read)/float64(p.Size))
+			}
+			fmt.Fprintf(os.Stderr, "%s:%s %d lines, %d editions, %d authors, %d skipped\n",
+				name, pct, p.Lines, p.Editions, p.Authors, p.Skipped)
+		})
+		if errors.Is(err, context.Canceled) {
+			return fmt.Errorf("%s: interrupted, run again to resume", name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func lookup(ctx context.Context, ix *olindex.Index, args []string) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+
+	var missing int
+	for _, arg := range args {
+		v, err := isbn.Parse(arg)
+		if err != nil {
+			return fmt.Errorf("%s: %w", arg, err)
+		}
+
+		book, err := ix.Resolve(ctx, v)
+		if errors.Is(err, metadata.ErrNotFound) {
+			fmt.Fprintf(os.Stderr, "%s: not found\n", arg)
+			missing++
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := enc.Encode(bookJSON{
+			ISBN:        v.String(),
+			Title:       book.Title,
+			Subtitle:    book.Subtitle,
+			Authors:     book.Authors,
+			Publisher:   book.Publisher,
+			Published:   book.Published,
+			Pages:       book.Pages,
+			Language:    book.Language,
+			Subjects:    book.Subjects,
+			Description: book.Description,
+			CoverURL:    book.CoverURL,
+		}); err != nil {
+			return err
+		}
+	}
+	if missing > 0 {
+		return fmt.Errorf("%d of %d not found", missing, len(args))
+	}
+	return nil
+}
+
+type bookJSON struct {
+	ISBN        string   `json:"isbn"`
+	Title       string   `json:"title"`
+	Subtitle    string   `json:"subtitle,omitempty"`
+	Authors     []string `json:"authors,omitempty"`
+	Publisher   string   `json:"publisher,omitempty"`
+	Published   string   `json:"published,omitempty"`
+	Pages       int      `json:"pages,omitempty"`
+	Language    string   `json:"language,omitempty"`
+	Subjects    []string `json:"subjects,omitempty"`
+	Description string   `json:"description,omitempty"`
+	CoverURL    string   `json:"cover_url,omitempty"`
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "olindex:", err)
+	os.Exit(1)
+}
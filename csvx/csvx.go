@@ -0,0 +1,388 @@
+// Package csvx validates, normalizes and repairs the ISBN columns of CSV
+// files, streaming rows through a bounded pool of workers.
+//
+//	p := &csvx.Processor{Columns: []string{"isbn"}, Repair: true, Rejects: rejects}
+//	stats, err := p.Process(ctx, r, w)
+//
+// For each ISBN column, say isbn, four status columns are appended to every
+// row: isbn_isbn is the ISBN in the Form of the Processor, isbn_status is
+// one of the Status values, isbn_error is why the ISBN is invalid and
+// isbn_suggestions lists the ISBNs it may have been meant as.
+package csvx
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/adoublef-go/isbn"
+)
+
+// ErrColumn is returned by Process for a column that is not in the header,
+// or by name when there is no header.
+var ErrColumn = errors.New("csvx: no such column")
+
+// Form is a form of ISBN written by a Processor.
+type Form int
+
+const (
+	// ISBN13 is the ISBN 13 without hyphens, e.g. 9780140328721.
+	ISBN13 Form = iota
+	// ISBN10 is the ISBN 10 without hyphens, e.g. 0140328726. An ISBN
+	// that has no ISBN 10 form is written as ISBN 13.
+	ISBN10
+	// Hyphenated is the hyphenated ISBN 13, e.g. 978-0-14-032872-1. An ISBN
+	// that cannot be hyphenated is written as ISBN 13.
+	Hyphenated
+)
+
+func (f Form) format(v isbn.ISBN) string {
+	switch f {
+	case ISBN10:
+		if s, err := v.ISBN10(); err == nil {
+			return s
+		}
+	case Hyphenated:
+		if s, err := v.Hyphenate(); err == nil {
+			return s
+		}
+	}
+	return v.String()
+}
+
+// Status is the outcome of an ISBN cell.
+type Status string
+
+const (
+	Valid    Status = "valid"
+	Repaired Status = "repaired"
+	Invalid  Status = "invalid"
+	Empty    Status = "empty"
+)
+
+// Processor processes the ISBN columns of a CSV file.
+type Processor struct {
+	// Columns are the ISBN columns, by header name or 1-based index.
+	Columns []string
+	// NoHeader is set if the first row is not a header, in which case
+	// Columns must be indexes and no status header is written.
+	NoHeader bool
+	// Form is the form of ISBN written to the status column, and to the ISBN
+	// column itself if repairing.
+	Form Form
+	// Repair rewrites valid ISBN columns in Form, reading them leniently
+	// (see Clean), and suggests the ISBNs meant by those with an invalid
+	// check digit. Without Repair, ISBN columns are left as they are.
+	Repair bool
+	// Assigned rejects ISBNs whose registration group or registrant is not
+	// assigned.
+	Assigned bool
+	// Rejects, if not nil, is written the rows with an invalid ISBN, which
+	// are then left out of the output. The header is written to both.
+	Rejects io.Writer
+	// Workers is the number of rows processed at a time, runtime.NumCPU() if
+	// zero.
+	Workers int
+}
+
+// Stats counts the rows and ISBN cells processed.
+type Stats struct {
+	Rows     int
+	Rejected int
+
+	Valid    int
+	Repaired int
+	Invalid  int
+	Empty    int
+}
+
+func (s *Stats) add(status Status) {
+	switch status {
+	case Valid:
+		s.Valid++
+	case Repaired:
+		s.Repaired++
+	case Invalid:
+		s.Invalid++
+	case Empty:
+		s.Empty++
+	}
+}
+
+type row struct {
+	seq    int
+	record []string
+	// statuses of the ISBN columns of the processed record
+	statuses []Status
+}
+
+// Process reads CSV from r and writes it to w with the status columns of
+// each ISBN column appended, in the order it was read. It stops with the
+// error of ctx when ctx is done.
+func (p *Processor) Process(ctx context.Context, r io.Reader, w io.Writer) (Stats, error) {
+	var stats Stats
+
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	out := csv.NewWriter(w)
+	var rejects *csv.Writer
+	if p.Rejects != nil {
+		rejects = csv.NewWriter(p.Rejects)
+	}
+
+	var header []string
+	if !p.NoHeader {
+		var err error
+		if header, err = cr.Read(); err == io.EOF {
+			return stats, nil
+		} else if err != nil {
+			return stats, err
+		}
+	}
+	cols, err := p.columns(header)
+	if err != nil {
+		return stats, err
+	}
+
+	// rows shorter than the header are padded, so that the status columns
+	// line up
+	width := len(header)
+	if header != nil {
+		for _, c := range cols {
+			name := header[c]
+			header = append(header, name+"_isbn", name+"_status", name+"_error", name+"_suggestions")
+		}
+		out.Write(header)
+		if rejects != nil {
+			rejects.Write(header)
+		}
+	}
+
+	workers := p.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// rows are read in order, processed by the workers in any order, and
+	// written in order again; slots bounds the rows in flight
+	jobs := make(chan *row)
+	done := make(chan *row)
+	slots := make(chan struct{}, 2*workers)
+
+	// read is sent the number of rows read, and why reading stopped if not
+	// at the end of r, before jobs is closed
+	type result struct {
+		n   int
+		err error
+	}
+	read := make(chan result, 1)
+	go func() {
+		defer close(jobs)
+		for n := 0; ; n++ {
+			record, err := cr.Read()
+			if err == io.EOF {
+				read <- result{n, nil}
+				return
+			}
+			var perr *csv.ParseError
+			if errors.As(err, &perr) {
+				err = fmt.Errorf("csvx: line %d: %w", perr.Line, perr.Err)
+			}
+			if err != nil {
+				read <- result{n, err}
+				cancel()
+				return
+			}
+
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				read <- result{n, ctx.Err()}
+				return
+			}
+			select {
+			case jobs <- &row{seq: n, record: record}:
+			case <-ctx.Done():
+				read <- result{n, ctx.Err()}
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				// jobs is left open if r blocks after ctx is done
+				var row *row
+				select {
+				case row = <-jobs:
+				case <-ctx.Done():
+					return
+				}
+				if row == nil {
+					return
+				}
+
+				p.process(row, cols, width)
+				select {
+				case done <- row:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	pending := map[int]*row{}
+	next := 0
+	for row := range done {
+		pending[row.seq] = row
+		for {
+			row, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			<-slots
+
+			stats.Rows++
+			rejected := false
+			for _, s := range row.statuses {
+				stats.add(s)
+				rejected = rejected || s == Invalid
+			}
+			if rejected && rejects != nil {
+				stats.Rejected++
+				rejects.Write(row.record)
+			} else {
+				out.Write(row.record)
+			}
+		}
+	}
+
+	select {
+	case res := <-read:
+		if res.err != nil {
+			return stats, res.err
+		}
+		if next < res.n {
+			// rows were dropped by the workers when ctx was done
+			return stats, ctx.Err()
+		}
+	default:
+		// the workers stopped when ctx was done, while r is blocked
+		return stats, ctx.Err()
+	}
+
+	out.Flush()
+	if err := out.Error(); err != nil {
+		return stats, err
+	}
+	if rejects != nil {
+		rejects.Flush()
+		if err := rejects.Error(); err != nil {
+			return stats, err
+		}
+	}
+	return stats, nil
+}
+
+// columns returns the indexes of the ISBN columns.
+func (p *Processor) columns(header []string) ([]int, error) {
+	if len(p.Columns) == 0 {
+		return nil, fmt.Errorf("%w: no ISBN columns given", ErrColumn)
+	}
+
+	var cols []int
+	for _, c := range p.Columns {
+		if i, err := strconv.Atoi(c); err == nil {
+			if i < 1 || header != nil && i > len(header) {
+				return nil, fmt.Errorf("%w: %d", ErrColumn, i)
+			}
+			cols = append(cols, i-1)
+			continue
+		}
+
+		found := -1
+		for i, name := range header {
+			if strings.EqualFold(strings.TrimSpace(name), c) {
+				found = i
+				break
+			}
+		}
+		if found < 0 {
+			return nil, fmt.Errorf("%w: %q", ErrColumn, c)
+		}
+		cols = append(cols, found)
+	}
+	return cols, nil
+}
+
+// process validates the ISBN columns of a row and appends their status
+// columns, after padding it to width.
+func (p *Processor) process(row *row, cols []int, width int) {
+	parse := isbn.Parse
+	if p.Assigned {
+		parse = isbn.ParseAssigned
+	}
+
+	// the reader's record is not reused, so it is safe to modify
+	record := row.record
+	for len(record) < width {
+		record = append(record, "")
+	}
+	for _, c := range cols {
+		var value string
+		if c < len(record) {
+			value = record[c]
+		}
+
+		var (
+			status      Status
+			form, cause string
+			suggestions []string
+		)
+		s := strings.TrimSpace(value)
+		if p.Repair {
+			s = Clean(s)
+		}
+		v, err := parse(s)
+		switch {
+		case s == "":
+			status = Empty
+		case err != nil:
+			status, cause = Invalid, err.Error()
+			if p.Repair && errors.Is(err, isbn.ErrValue) {
+				for _, v := range Suggest(s) {
+					suggestions = append(suggestions, p.Form.format(v))
+				}
+			}
+		default:
+			status, form = Valid, p.Form.format(v)
+			if p.Repair && form != value && c < len(record) {
+				record[c], status = form, Repaired
+			}
+		}
+
+		row.statuses = append(row.statuses, status)
+		record = append(record, form, string(status), cause, strings.Join(suggestions, " "))
+	}
+	row.record = record
+}
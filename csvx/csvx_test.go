@@ -0,0 +1,161 @@
+package csvx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/adoublef-go/isbn"
+	"github.com/hyphengolang/prelude/testing/is"
+)
+
+const books = `title,isbn,ean
+Fantastic Mr Fox,0-14-032872-6,9780140328721
+The Panda's Thumb,ISBN 0716703441,
+Short
+Les Misérables,978 2 07 040922 8,
+`
+
+func TestProcess(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	var out, rejects bytes.Buffer
+	p := &Processor{
+		Columns: []string{"ISBN", "3"},
+		Repair:  true,
+		Rejects: &rejects,
+		Workers: 2,
+	}
+	stats, err := p.Process(context.Background(), strings.NewReader(books), &out)
+	is.NoErr(err) // processing csv
+
+	header := "title,isbn,ean," +
+		"isbn_isbn,isbn_status,isbn_error,isbn_suggestions," +
+		"ean_isbn,ean_status,ean_error,ean_suggestions\n"
+	is.Equal(out.String(), header+
+		"Fantastic Mr Fox,9780140328721,9780140328721,9780140328721,repaired,,,9780140328721,valid,,\n"+
+		"Short,,,,empty,,,,empty,,\n"+
+		"Les Misérables,9782070409228,,9782070409228,repaired,,,,empty,,\n") // repaired rows
+	is.Equal(rejects.String(), header+
+		"The Panda's Thumb,ISBN 0716703441,,,invalid,invalid ISBN value,9780716703440 9781716703447 9780216703445 9780756703448 9780719703447 9780716503446 9780716723448 9780716700449 9780716703044 9780716703495,,empty,,\n") // rejected rows
+	is.Equal(stats, Stats{Rows: 4, Rejected: 1, Valid: 1, Repaired: 2, Invalid: 1, Empty: 4}) // stats
+}
+
+func TestProcessValidate(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	var out bytes.Buffer
+	p := &Processor{Columns: []string{"2"}, NoHeader: true, Form: Hyphenated}
+	stats, err := p.Process(context.Background(), strings.NewReader("Fox,0140328726\nPanda,0716703441\n"), &out)
+	is.NoErr(err) // processing csv
+	is.Equal(out.String(), "Fox,0140328726,978-0-14-032872-1,valid,,\n"+
+		"Panda,0716703441,,invalid,invalid ISBN value,\n") // values left as they are
+	is.Equal(stats.Invalid, 1) // invalid isbn
+
+	tt := []struct {
+		desc    string
+		columns []string
+	}{
+		{"none", nil},
+		{"unknown name", []string{"isbn13"}},
+		{"index out of range", []string{"4"}},
+		{"zero index", []string{"0"}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			p := &Processor{Columns: tc.columns}
+			_, err := p.Process(context.Background(), strings.NewReader(books), io.Discard)
+			is.True(errors.Is(err, ErrColumn)) // invalid column
+		})
+	}
+}
+
+func TestProcessOrder(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	// many rows through few workers come out in the order they went in
+	var in, want strings.Builder
+	in.WriteString("n,isbn\n")
+	for i := 0; i < 1000; i++ {
+		fmt.Fprintf(&in, "%d,0140328726\n", i)
+		fmt.Fprintf(&want, "%d,0140328726,9780140328721,valid,,\n", i)
+	}
+
+	var out bytes.Buffer
+	p := &Processor{Columns: []string{"isbn"}, Workers: 3}
+	stats, err := p.Process(context.Background(), strings.NewReader(in.String()), &out)
+	is.NoErr(err)              // processing csv
+	is.Equal(stats.Rows, 1000) // rows processed
+	_, body, _ := strings.Cut(out.String(), "\n")
+	is.Equal(body, want.String()) // rows in order
+}
+
+func TestProcessCancel(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	pr, pw := io.Pipe()
+	t.Cleanup(func() { pr.Close() })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		io.WriteString(pw, "isbn\n0140328726\n")
+		cancel()
+	}()
+
+	p := &Processor{Columns: []string{"isbn"}}
+	_, err := p.Process(ctx, pr, io.Discard)
+	is.True(errors.Is(err, context.Canceled)) // cancelled while reading
+}
+
+func TestProcessInvalidCSV(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	p := &Processor{Columns: []string{"isbn"}}
+	_, err := p.Process(context.Background(), strings.NewReader("isbn\n\"0140328726\n"), io.Discard)
+	is.True(err != nil) // unterminated quote
+}
+
+func TestSuggest(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	tt := []struct {
+		desc  string
+		value string
+		first string
+	}{
+		{"isbn 10 check digit", "0-14-032872-7", "9780140328721"},
+		{"isbn 13 check digit", "9780140328722", "9780140328721"},
+		{"labelled", "ISBN-10: 0716703441", "9780716703440"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			suggestions := Suggest(tc.value)
+			is.True(len(suggestions) > 0)               // suggestions made
+			is.Equal(suggestions[0].String(), tc.first) // check digit fixed first
+
+			for _, v := range suggestions {
+				is.True(v.Assigned()) // only assigned isbns
+			}
+		})
+	}
+
+	is.Equal(len(Suggest("014032872")), 0)                         // too short
+	is.Equal(Clean(" isbn13 978-0 14.032872 1 "), "9780140328721") // cleaned
+	is.Equal(Clean("0-14-032872-x"), "014032872X")                 // lower case check digit
+
+	v, err := isbn.Parse(Clean("ISBN: 0 14 032872 6"))
+	is.NoErr(err)                         // cleaned isbn parses
+	is.Equal(v.String(), "9780140328721") // isbn 13
+}
@@ -0,0 +1,78 @@
+package csvx
+
+import (
+	"strings"
+
+	"github.com/adoublef-go/isbn"
+)
+
+// Clean strips an ISBN of the separators and labels that are common in
+// spreadsheets, e.g. "ISBN: 0-14-032872-6" or "978 0 14 032872 1", leaving
+// its digits and any X check digit.
+func Clean(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 4 && strings.EqualFold(s[:4], "isbn") {
+		s = s[4:]
+		for _, prefix := range []string{"-13", "-10", "13", "10"} {
+			if strings.HasPrefix(s, prefix) {
+				s = s[len(prefix):]
+				break
+			}
+		}
+	}
+
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == 'X' || r == 'x':
+			b.WriteByte('X')
+		case r == '-' || r == ' ' || r == '.' || r == ':' || r == '\u00a0' || r == '\u2010' || r == '\u2011':
+		default:
+			// keep anything else, so that the value is rejected
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Suggest returns the assigned ISBNs that differ from the ISBN 10 or ISBN
+// 13 in s by a single digit, for an s with an invalid check digit. An error
+// in the check digit itself is suggested first.
+func Suggest(s string) []isbn.ISBN {
+	s = Clean(s)
+	if len(s) != 10 && len(s) != 13 {
+		return nil
+	}
+
+	var suggestions []isbn.ISBN
+	seen := map[isbn.ISBN]bool{}
+	try := func(i int, c byte) {
+		b := []byte(s)
+		if b[i] == c {
+			return
+		}
+		b[i] = c
+		v, err := isbn.ParseBytes(b)
+		if err == nil && !seen[v] && v.Assigned() {
+			seen[v] = true
+			suggestions = append(suggestions, v)
+		}
+	}
+
+	digits := "0123456789"
+	// the check digit first
+	last := len(s) - 1
+	for _, c := range []byte(digits + "X") {
+		if c != 'X' || len(s) == 10 {
+			try(last, c)
+		}
+	}
+	for i := 0; i < last; i++ {
+		for _, c := range []byte(digits) {
+			try(i, c)
+		}
+	}
+	return suggestions
+}
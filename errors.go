@@ -8,12 +8,18 @@ import (
 
 var ErrTodo = errors.New("todo")
 
+// ErrLength matches, using errors.Is, the error returned for an input that
+// is not the length of any ISBN form.
+var ErrLength = errors.New("invalid ISBN length")
+
 type invalidLengthError struct{ len int }
 
 func (err invalidLengthError) Error() string {
 	return fmt.Sprintf("invalid ISBN length %d", err.len)
 }
 
+func (err invalidLengthError) Is(target error) bool { return target == ErrLength }
+
 type invalidTypeError struct{ value reflect.Type }
 
 func (err invalidTypeError) Error() string {
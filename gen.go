@@ -0,0 +1,44 @@
+//go:build ignore
+
+// gen replaces RangeMessage.xml with the range message currently published
+// by the International ISBN Agency.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/adoublef-go/isbn"
+)
+
+const url = "https://www.isbn-international.org/export_rangemessage.xml"
+
+func main() {
+	resp, err := http.Get(url)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		log.Fatalf("%s: %s", url, resp.Status)
+	}
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Fatal(err)
+	}
+	rs, err := isbn.ParseRanges(bytes.NewReader(b))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// the message is embedded as published
+	if err := os.WriteFile("RangeMessage.xml", b, 0o644); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("range message %s (%s), %d registration groups\n", rs.Serial, rs.Date, len(rs.Groups))
+}
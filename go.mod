@@ -5,7 +5,7 @@ go 1.18
 require (
 	github.com/hyphengolang/prelude v0.1.1
 	github.com/jackc/pgx/v5 v5.0.3
-	github.com/mattn/go-sqlite3 v1.14.13
+	github.com/mattn/go-sqlite3 v1.14.15
 )
 
 require (
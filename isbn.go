@@ -1,8 +1,8 @@
 package isbn
 
 import (
+	"bytes"
 	"fmt"
-	"strings"
 )
 
 type ISBN [13]byte
@@ -11,6 +11,13 @@ func (isbn ISBN) String() string {
 	return string(isbn[:])
 }
 
+// Compare returns an integer comparing two ISBNs by their ISBN 13 form.
+// The result will be 0 if a == b, -1 if a < b, and +1 if a > b.
+func Compare(a, b ISBN) int { return bytes.Compare(a[:], b[:]) }
+
+// Less reports whether a sorts before b, for use with sort.Slice.
+func Less(a, b ISBN) bool { return Compare(a, b) < 0 }
+
 var (
 	defaultPrefix = "978"
 )
@@ -18,51 +25,202 @@ var (
 var (
 	ErrValue  = fmt.Errorf("invalid ISBN value")
 	ErrFormat = fmt.Errorf("invalid ISBN format")
+	ErrRange  = fmt.Errorf("ISBN not in a registered range")
+	ErrPrefix = fmt.Errorf("ISBN has no ISBN 10 form")
+
+	// ErrGroup and ErrRegistrant wrap ErrRange, telling apart an ISBN
+	// whose registration group is not assigned from one whose registrant
+	// is not assigned.
+	ErrGroup      = fmt.Errorf("%w: registration group not assigned", ErrRange)
+	ErrRegistrant = fmt.Errorf("%w: registrant not assigned", ErrRange)
 )
 
 // Parse s into an ISBN 13 or returns an error. Supports the forms
 // ISBN 13 XXXXXXXXXXXXX (XXX-X-XXXX-XXXX-X) and
 // ISBN 10 XXXXXXXXXX (X-XXXX-XXXX-X)
-func Parse(s string) (isbn ISBN, err error) {
+//
+// An ISBN 10 is validated by its own check digit, which may be X, and
+// converted to the ISBN 13 with the 978 prefix and a new check digit.
+func Parse(s string) (isbn ISBN, err error) { return parse(s) }
+
+// ParseBytes is like Parse, except it parses a byte slice instead of a string.
+func ParseBytes(b []byte) (isbn ISBN, err error) { return parse(b) }
+
+// ParseAssigned is like Parse, but also rejects an ISBN whose registration
+// group or registrant is not assigned in the active range table, with
+// ErrGroup or ErrRegistrant respectively.
+func ParseAssigned(s string) (isbn ISBN, err error) { return parseAssigned(s) }
+
+// ParseBytesAssigned is like ParseAssigned, except it parses a byte slice
+// instead of a string.
+func ParseBytesAssigned(b []byte) (isbn ISBN, err error) { return parseAssigned(b) }
+
+func parseAssigned[T string | []byte](s T) (isbn ISBN, err error) {
+	if isbn, err = parse(s); err != nil {
+		return isbn, err
+	}
+	_, _, _, err = CurrentRanges().split(isbn)
+	return isbn, err
+}
+
+// parse is shared by Parse and ParseBytes so that neither has to convert
+// its input, nor allocate when stripping hyphens.
+func parse[T string | []byte](s T) (isbn ISBN, err error) {
 	switch len(s) {
 	case 13 + 4: //XXX-X-XXXX-XXXX-X
+		return check13(s)
 	case 10: //XXXXXXXXXX
-		s = defaultPrefix + s
-		fallthrough
-	case 13: //XXXXXXXXXXXXX or XXX-XX-XXX-XXXX-X
-		if !strings.Contains(s, "-") {
-			return check13(s)
+		return check10(s)
+	case 13: //XXXXXXXXXXXXX or X-XXXX-XXXX-X
+		for i := 0; i < len(s); i++ {
+			if s[i] == '-' {
+				return check10(s)
+			}
 		}
-		s = defaultPrefix + "-" + s
+		return check13(s)
 	default:
 		return isbn, invalidLengthError{len(s)}
 	}
-	// s is now in the format XXX-X-XXXX-XXXX-X
-	return check13(strings.ReplaceAll(s, "-", ""))
 }
 
-// ParseBytes is like Parse, except it parses a byte slice instead of a string.
-func ParseBytes(b []byte) (isbn ISBN, err error) { return Parse(string(b)) }
+// Assigned reports whether the registration group and registrant of the ISBN
+// are assigned in the active range table. A valid check digit alone does
+// not make an ISBN assigned.
+func (isbn ISBN) Assigned() bool {
+	_, _, _, err := CurrentRanges().split(isbn)
+	return err == nil
+}
 
-func check13(s string) (isbn ISBN, err error) {
+// Hyphenate returns the ISBN 13 with hyphens between the prefix, registration
+// group, registrant, publication and check digit (XXX-X-XXXX-XXXX-X).
+func (isbn ISBN) Hyphenate() (string, error) {
+	return CurrentRanges().Hyphenate(isbn)
+}
+
+// Parts splits the ISBN into its prefix, registration group, registrant,
+// publication and check digit.
+func (isbn ISBN) Parts() (Parts, error) {
+	return CurrentRanges().Parts(isbn)
+}
+
+// ISBN10 returns the ISBN 10 form (XXXXXXXXXX) of an ISBN with the
+// default 978 prefix.
+func (isbn ISBN) ISBN10() (string, error) {
+	if string(isbn[:3]) != defaultPrefix {
+		return "", ErrPrefix
+	}
+
+	var b [10]byte
+	var sum int
+	for i := 0; i < 9; i++ {
+		b[i] = isbn[i+3]
+		sum += int(b[i]-'0') * (10 - i)
+	}
+
+	switch check := (11 - sum%11) % 11; check {
+	case 10:
+		b[9] = 'X'
+	default:
+		b[9] = byte('0' + check)
+	}
+	return string(b[:]), nil
+}
+
+// Uint64 returns the compact integer form of the ISBN, the number formed by
+// the first twelve digits of its ISBN 13 form. The check digit is left out as
+// it follows from the others, so the form fits in 40 bits, and ISBNs compare
+// in the same order as their integers.
+func (isbn ISBN) Uint64() uint64 {
+	var n uint64
+	for _, c := range isbn[:12] {
+		n = n*10 + uint64(c-'0')
+	}
+	return n
+}
+
+// FromUint64 returns the ISBN whose compact integer form is n, as returned by
+// Uint64, or ErrFormat if n has more than twelve digits.
+func FromUint64(n uint64) (isbn ISBN, err error) {
+	if n >= 1e12 {
+		return isbn, ErrFormat
+	}
+
+	var sum int
+	for i := 11; i >= 0; i-- {
+		d := int(n % 10)
+		n /= 10
+		isbn[i] = byte('0' + d)
+		sum += d * (1 + 2*(i%2))
+	}
+	isbn[12] = byte('0' + (10-sum%10)%10)
+	return isbn, nil
+}
+
+// check13 validates the ISBN 13 in s, ignoring any hyphens.
+func check13[T string | []byte](s T) (isbn ISBN, err error) {
 	var acc [2]int
+	var n int
 
 	for i := 0; i < len(s); i++ {
 		switch v := int(s[i] - '0'); {
-		case v >= 10:
+		case s[i] == '-':
+		case v >= 10 || n == len(isbn):
 			return isbn, ErrFormat
 		default:
-			acc[i%2] += v
-			isbn[i] = s[i]
+			acc[n%2] += v
+			isbn[n] = s[i]
+			n++
 		}
 	}
 
+	if n != len(isbn) {
+		return isbn, ErrFormat
+	}
 	if (acc[0]+acc[1]*3)%10 != 0 {
 		return isbn, ErrValue
 	}
 	return isbn, nil
 }
 
+// check10 validates the ISBN 10 in s, ignoring any hyphens, and converts it
+// to an ISBN 13.
+func check10[T string | []byte](s T) (isbn ISBN, err error) {
+	var acc, acc13 int
+	var n int
+
+	copy(isbn[:], defaultPrefix)
+	for i := 0; i < len(s); i++ {
+		switch v := int(s[i] - '0'); {
+		case s[i] == '-':
+		case n == 10:
+			return isbn, ErrFormat
+		case n == 9 && (s[i] == 'X' || s[i] == 'x'):
+			acc += 10
+			n++
+		case v >= 10:
+			return isbn, ErrFormat
+		default:
+			acc += v * (10 - n)
+			if n < 9 {
+				isbn[n+3] = s[i]
+				acc13 += v * (1 + 2*((n+3)%2))
+			}
+			n++
+		}
+	}
+
+	if n != 10 {
+		return isbn, ErrFormat
+	}
+	if acc%11 != 0 {
+		return isbn, ErrValue
+	}
+
+	// 978 contributes 9 + 7*3 + 8 to the ISBN 13 checksum
+	isbn[12] = byte('0' + (10-(38+acc13)%10)%10)
+	return isbn, nil
+}
+
 // Support for in future implentations would be nice
 // type ISBN struct {
 // 	group
@@ -5,9 +5,15 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/hyphengolang/prelude/testing/is"
 	"github.com/jackc/pgx/v5"
@@ -31,6 +37,10 @@ func TestIsbnValidation(t *testing.T) {
 		{desc: "valid: isbn 10", data: "0716703440"},
 		{desc: "valid: isbn 10 w/ dashes", data: "0-7167-0344-0"},
 		{desc: "valid: isbn 13 w/ dashes", data: "978-0-7167-0344-0"},
+		{desc: "valid: isbn 10 w/ check digit X", data: "080442957X"},
+		{desc: "invalid: isbn 10", data: "0716703441", err: "invalid ISBN value"},
+		{desc: "invalid: isbn 13 w/ misplaced dashes", data: "9780-7167-0344-01", err: "invalid ISBN format"},
+		{desc: "invalid: isbn 13 w/ too many dashes", data: "978-0-7167-034--0", err: "invalid ISBN format"},
 	}
 
 	for _, tc := range tt {
@@ -46,6 +56,295 @@ func TestIsbnValidation(t *testing.T) {
 	}
 }
 
+func TestIsbn10CheckDigit(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	// prefixed is how an ISBN 10 was parsed before its check digit was
+	// validated: as an ISBN 13 with the 978 prefix, keeping the ISBN 10
+	// check digit.
+	prefixed := func(s string) (ISBN, error) { return check13(defaultPrefix + strings.ReplaceAll(s, "-", "")) }
+
+	tt := []struct {
+		desc   string
+		data   string
+		before error
+		want   string
+		err    error
+	}{
+		{desc: "same check digit in both forms", data: "0716703440", want: "9780716703440"},
+		{desc: "valid isbn 10", data: "0-14-032872-6", before: ErrValue, want: "9780140328721"},
+		{desc: "check digit X", data: "080442957X", before: ErrFormat, want: "9780804429573"},
+		{desc: "invalid isbn 10", data: "0140328727", before: ErrValue, err: ErrValue},
+		{desc: "isbn 13 check digit", data: "0140328721", want: "9780140328721", err: ErrValue},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			v, err := prefixed(tc.data)
+			is.Equal(err, tc.before) // parsed as prefixed isbn 13
+			if err == nil {
+				is.Equal(v.String(), tc.want) // prefixed isbn 13
+			}
+
+			v, err = Parse(tc.data)
+			is.Equal(err, tc.err) // parsed as isbn 10
+			if err == nil {
+				is.Equal(v.String(), tc.want) // converted isbn 13
+			}
+		})
+	}
+}
+
+func TestIsbnHyphenate(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	tt := []struct {
+		desc   string
+		data   string
+		hyphen string
+		isbn10 string
+		err    error
+	}{
+		{desc: "english language group 0", data: "9780716703440", hyphen: "978-0-7167-0344-0", isbn10: "0716703440"},
+		{desc: "english language group 1", data: "9781861972712", hyphen: "978-1-86197-271-2", isbn10: "1861972717"},
+		{desc: "isbn 10 w/ check digit X", data: "080442957X", hyphen: "978-0-8044-2957-3", isbn10: "080442957X"},
+		{desc: "five digit group", data: "9789990123456", hyphen: "978-99901-23-45-6", isbn10: "9990123454"},
+		{desc: "979 prefix", data: "9791032305690", hyphen: "979-10-323-0569-0", err: ErrPrefix},
+		{desc: "unassigned registrant", data: "9789990190007", err: ErrRegistrant},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			isbn, err := Parse(tc.data)
+			is.NoErr(err) // parse isbn
+
+			hyphen, err := isbn.Hyphenate()
+			if tc.hyphen == "" {
+				is.Equal(err, tc.err) // not in a registered range
+				return
+			}
+			is.NoErr(err)               // hyphenate isbn
+			is.Equal(hyphen, tc.hyphen) // hyphenated form
+
+			isbn10, err := isbn.ISBN10()
+			is.Equal(err, tc.err)       // no isbn 10 form for 979
+			is.Equal(isbn10, tc.isbn10) // isbn 10 form
+		})
+	}
+}
+
+func TestIsbnAssigned(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	tt := []struct {
+		desc string
+		data string
+		err  error
+		// full is set for groups left out of a trimmed range message
+		full bool
+	}{
+		{desc: "assigned", data: "978-0-7167-0344-0"},
+		{desc: "assigned isbn 10", data: "0-7167-0344-0"},
+		{desc: "france", data: "9782070409228"},
+		{desc: "germany", data: "9783161484100"},
+		{desc: "france 979 group", data: "9791032305690"},
+		{desc: "italy", data: "9788804668237", full: true},
+		{desc: "netherlands", data: "9789027439642", full: true},
+		{desc: "india", data: "9788131709238", full: true},
+		{desc: "unassigned group", data: "9786600000008", err: ErrGroup},
+		{desc: "unassigned 979 group", data: "9790000000001", err: ErrGroup},
+		{desc: "unassigned registrant", data: "9789990190007", err: ErrRegistrant},
+		{desc: "invalid check digit", data: "9789990190000", err: ErrValue},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			if tc.full && DefaultRanges().Serial == "trimmed" {
+				t.Skip("group is not in the trimmed range message, run go generate")
+			}
+
+			_, err := ParseAssigned(tc.data)
+			is.Equal(err, tc.err) // validate assigned ranges
+			_, err = ParseBytesAssigned([]byte(tc.data))
+			is.Equal(err, tc.err) // validate assigned ranges of bytes
+
+			isbn, err := ParseBytes([]byte(tc.data))
+			if tc.err == ErrValue {
+				is.Equal(err, ErrValue) // check digit is validated first
+				return
+			}
+			is.NoErr(err)                                        // checksum valid without option
+			is.Equal(isbn.Assigned(), tc.err == nil)             // assigned
+			is.Equal(errors.Is(tc.err, ErrRange), tc.err != nil) // wraps ErrRange
+		})
+	}
+}
+
+func TestIsbnCompare(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	a, _ := Parse("0-7167-0344-0")
+	b, _ := Parse("9780716703440")
+	c, _ := Parse("9781861972712")
+
+	is.Equal(Compare(a, b), 0)  // isbn 10 and isbn 13 forms are equal
+	is.Equal(Compare(a, c), -1) // a < c
+	is.Equal(Compare(c, a), 1)  // c > a
+	is.True(Less(b, c))         // b sorts before c
+	is.True(!Less(b, a))        // b does not sort before a
+
+	isbns := []ISBN{c, a, b}
+	sort.Slice(isbns, func(i, j int) bool { return Less(isbns[i], isbns[j]) })
+	is.Equal(isbns, []ISBN{a, b, c}) // sorted ascending
+}
+
+func TestIsbnUint64(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	tt := []struct {
+		desc string
+		data string
+		n    uint64
+	}{
+		{desc: "isbn 13", data: "9780716703440", n: 978071670344},
+		{desc: "isbn 10", data: "080442957X", n: 978080442957},
+		{desc: "979 prefix", data: "9791032305690", n: 979103230569},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			isbn, err := Parse(tc.data)
+			is.NoErr(err)                 // parse isbn
+			is.Equal(isbn.Uint64(), tc.n) // compact integer form
+			v, err := FromUint64(tc.n)
+			is.NoErr(err)     // from compact integer form
+			is.Equal(v, isbn) // same isbn with check digit
+		})
+	}
+
+	a, _ := Parse("9780716703440")
+	b, _ := Parse("9781861972712")
+	is.True(a.Uint64() < b.Uint64()) // same order as Compare
+
+	_, err := FromUint64(1e12)
+	is.True(errors.Is(err, ErrFormat)) // more than twelve digits
+}
+
+func TestIsbnSet(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	var isbns []ISBN
+	for _, v := range []string{"0716703440", "9780716703440", "978-1-86197-271-2", "080442957X", "9791032305690"} {
+		isbn, err := Parse(v)
+		is.NoErr(err) // parse isbn
+		isbns = append(isbns, isbn)
+	}
+
+	s := NewISBNSet(isbns[:3]...)
+	is.Equal(s.Len(), 2)           // isbn 10 and isbn 13 forms are deduplicated
+	is.True(s.Contains(isbns[1]))  // contains isbn 13
+	is.True(!s.Contains(isbns[3])) // does not contain other isbn
+
+	u := NewISBNSet(isbns[2:]...)
+	is.Equal(s.Union(u).Slice(), []ISBN{isbns[0], isbns[3], isbns[2], isbns[4]}) // union
+	is.Equal(s.Intersection(u).Slice(), []ISBN{isbns[2]})                        // intersection
+	is.Equal(s.Diff(u).Slice(), []ISBN{isbns[0]})                                // difference
+
+	s.Remove(isbns[0])
+	is.Equal(s.Slice(), []ISBN{isbns[2]}) // removed
+}
+
+func TestIsbnGroupBy(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	var isbns []ISBN
+	for _, v := range []string{"9780716703440", "9780716703457", "9781861972712", "9791032305690", "9789990190007"} {
+		isbn, err := Parse(v)
+		is.NoErr(err) // parse isbn
+		isbns = append(isbns, isbn)
+	}
+
+	is.Equal(GroupByRegistrationGroup(isbns), map[string][]ISBN{
+		"978-0":  isbns[0:2],
+		"978-1":  isbns[2:3],
+		"979-10": isbns[3:4],
+		"":       isbns[4:5],
+	}) // grouped by registration group
+
+	is.Equal(GroupByRegistrant(isbns), map[string][]ISBN{
+		"978-0-7167":  isbns[0:2],
+		"978-1-86197": isbns[2:3],
+		"979-10-323":  isbns[3:4],
+		"":            isbns[4:5],
+	}) // grouped by registrant
+
+	p, err := isbns[0].Parts()
+	is.NoErr(err)                             // split isbn
+	is.Equal(p.Agency, "English language")    // agency of registration group
+	is.Equal(p.String(), "978-0-7167-0344-0") // parts joined by hyphens
+}
+
+func TestIsbnRanges(t *testing.T) {
+	// not parallel, as the active range table is shared by every test
+	is := is.New(t)
+
+	rs, err := LoadRanges("testdata/RangeMessage.xml")
+	is.NoErr(err)               // load range message
+	is.Equal(rs.Serial, "test") // serial number of message
+
+	date, err := rs.Time()
+	is.NoErr(err)                                                               // parse message date
+	is.True(date.Equal(time.Date(2024, time.October, 1, 9, 0, 0, 0, time.UTC))) // date of message
+
+	date, err = (&Ranges{Date: "Mon, 2 Sep 2024 10:17:35 BST"}).Time()
+	is.NoErr(err)                                                                   // parse summer date
+	is.True(date.Equal(time.Date(2024, time.September, 2, 9, 17, 35, 0, time.UTC))) // british summer time
+
+	isbn, _ := Parse("9780716703440")
+	t.Cleanup(func() { SetRanges(nil) })
+
+	var wg sync.WaitGroup
+	var failed int32
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				// hyphenated by either table
+				if hyphen, _ := isbn.Hyphenate(); hyphen != "978-0-7167-0344-0" && hyphen != "978-0-71-670344-0" {
+					atomic.AddInt32(&failed, 1)
+				}
+			}
+		}()
+	}
+	for j := 0; j < 100; j++ {
+		SetRanges(rs)
+		SetRanges(nil)
+	}
+	wg.Wait()
+	is.Equal(failed, int32(0)) // hyphenate while swapping tables
+
+	err = ReloadRanges("testdata/RangeMessage.xml")
+	is.NoErr(err) // make loaded table active
+
+	hyphen, _ := isbn.Hyphenate()
+	is.Equal(hyphen, "978-0-71-670344-0") // hyphenated with loaded table
+
+	err = ReloadRanges("testdata/missing.xml")
+	is.True(err != nil)                      // missing file
+	is.Equal(CurrentRanges().Serial, "test") // active table untouched
+
+	SetRanges(nil)
+	is.Equal(CurrentRanges(), DefaultRanges()) // embedded table restored
+}
+
 func TestIsbnJSON(t *testing.T) {
 	t.Parallel()
 	is := is.New(t)
@@ -135,3 +434,176 @@ func TestIsbnPsql(t *testing.T) {
 		is.Equal(tc.Isbn.String(), isbn.String())
 	})
 }
+
+func TestIsbnAllocs(t *testing.T) {
+	is := is.New(t)
+
+	tt := []struct {
+		desc string
+		data string
+	}{
+		{desc: "isbn 13", data: "9780716703440"},
+		{desc: "isbn 13 w/ dashes", data: "978-0-7167-0344-0"},
+		{desc: "isbn 10", data: "0716703440"},
+		{desc: "isbn 10 w/ dashes", data: "0-7167-0344-0"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			b := []byte(tc.data)
+			var isbn ISBN
+
+			is.Equal(testing.AllocsPerRun(100, func() { Parse(tc.data) }), 0.0)     // Parse does not allocate
+			is.Equal(testing.AllocsPerRun(100, func() { ParseBytes(b) }), 0.0)      // ParseBytes does not allocate
+			is.Equal(testing.AllocsPerRun(100, func() { isbn.Scan(b) }), 0.0)       // Scan does not allocate
+			is.Equal(testing.AllocsPerRun(100, func() { isbn.Scan(tc.data) }), 0.0) // Scan does not allocate
+		})
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	for _, s := range []string{"9780716703440", "978-0-7167-0344-0", "0716703440", "0-7167-0344-0"} {
+		b.Run(s, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				Parse(s)
+			}
+		})
+	}
+}
+
+func BenchmarkParseBytes(b *testing.B) {
+	for _, s := range []string{"9780716703440", "978-0-7167-0344-0", "0716703440", "0-7167-0344-0"} {
+		data := []byte(s)
+		b.Run(s, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				ParseBytes(data)
+			}
+		})
+	}
+}
+
+func BenchmarkScan(b *testing.B) {
+	data := []byte("978-0-7167-0344-0")
+	var isbn ISBN
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		isbn.Scan(data)
+	}
+}
+
+// FuzzParse checks Parse and ParseBytes against the string based
+// implementation of the baseline, except where their behaviour was changed
+// on purpose:
+//
+//   - an ISBN 10 is validated by its own check digit, rather than as an
+//     ISBN 13 with the 978 prefix, as tested by TestIsbn10CheckDigit;
+//   - hyphens that do not leave 13 digits are ErrFormat, where the baseline
+//     panicked or returned a short ISBN, as tested by TestIsbnValidation.
+func FuzzParse(f *testing.F) {
+	for _, s := range []string{
+		"9780716703440", "978-0-7167-0344-0", "0716703440", "0-7167-0344-0",
+		"080442957X", "0-8044-2957-x", "9780716703410", "978071670344a",
+		"9780-7167-0344-01", "------------0", "0-7167-0344-X-", "X716703440",
+		"978-0-7167-034--0", "0-7167-034",
+	} {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		want, wantErr := parseReference(s)
+		if isbn10Form(s) {
+			want, wantErr = check10Reference(s)
+		}
+
+		for _, parse := range []func(string) (ISBN, error){Parse, func(s string) (ISBN, error) { return ParseBytes([]byte(s)) }} {
+			got, err := parse(s)
+			if fmt.Sprint(err) != fmt.Sprint(wantErr) {
+				t.Fatalf("parse(%q) error = %v, want %v", s, err, wantErr)
+			}
+			if err == nil && got != want {
+				t.Fatalf("parse(%q) = %s, want %s", s, got, want)
+			}
+		}
+	})
+}
+
+// parseReference is Parse as it was in the baseline.
+func parseReference(s string) (isbn ISBN, err error) {
+	switch len(s) {
+	case 13 + 4: //XXX-X-XXXX-XXXX-X
+	case 10: //XXXXXXXXXX
+		s = defaultPrefix + s
+		fallthrough
+	case 13: //XXXXXXXXXXXXX or XXX-XX-XXX-XXXX-X
+		if !strings.Contains(s, "-") {
+			return check13Reference(s)
+		}
+		s = defaultPrefix + "-" + s
+	default:
+		return isbn, invalidLengthError{len(s)}
+	}
+	// s is now in the format XXX-X-XXXX-XXXX-X
+	return check13Reference(strings.ReplaceAll(s, "-", ""))
+}
+
+// check13Reference is check13 as it was in the baseline, but returns
+// ErrFormat for s that is not 13 long instead of panicking or returning a
+// short ISBN.
+func check13Reference(s string) (isbn ISBN, err error) {
+	var acc [2]int
+
+	if len(s) != 13 {
+		return isbn, ErrFormat
+	}
+	for i := 0; i < len(s); i++ {
+		switch v := int(s[i] - '0'); {
+		case v >= 10:
+			return isbn, ErrFormat
+		default:
+			acc[i%2] += v
+			isbn[i] = s[i]
+		}
+	}
+
+	if (acc[0]+acc[1]*3)%10 != 0 {
+		return isbn, ErrValue
+	}
+	return isbn, nil
+}
+
+// isbn10Form reports whether Parse reads s as an ISBN 10.
+func isbn10Form(s string) bool {
+	return len(s) == 10 || len(s) == 13 && strings.Contains(s, "-")
+}
+
+// check10Reference validates the ISBN 10 in s, ignoring any hyphens, by its
+// own check digit, and returns the ISBN 13 with the same first nine digits.
+func check10Reference(s string) (isbn ISBN, err error) {
+	var acc int
+	s = strings.ReplaceAll(s, "-", "")
+	if len(s) != 10 {
+		return isbn, ErrFormat
+	}
+	for i := 0; i < len(s); i++ {
+		switch v := int(s[i] - '0'); {
+		case i == 9 && (s[i] == 'X' || s[i] == 'x'):
+			acc += 10
+		case v >= 10:
+			return isbn, ErrFormat
+		default:
+			acc += v * (10 - i)
+		}
+	}
+	if acc%11 != 0 {
+		return isbn, ErrValue
+	}
+	for d := '0'; d <= '9'; d++ {
+		if isbn, err = check13Reference(defaultPrefix + s[:9] + string(d)); err == nil {
+			break
+		}
+	}
+	return isbn, err
+}
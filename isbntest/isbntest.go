@@ -0,0 +1,291 @@
+// Package isbntest generates random ISBNs for tests, from the active range
+// table of the isbn package.
+//
+//	g := isbntest.New(1)
+//	v := g.ISBN13()                          // valid and assigned
+//	s := g.Invalid(isbntest.InvalidCheckDigit) // fails isbn.Parse with isbn.ErrValue
+//
+// ISBN and Invalid implement testing/quick.Generator:
+//
+//	quick.Check(func(v isbntest.ISBN) bool { ... }, nil)
+package isbntest
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/adoublef-go/isbn"
+)
+
+// maxAttempts bounds the search for an ISBN matching a Generator's Prefix.
+const maxAttempts = 1000
+
+// Generator produces random ISBNs.
+type Generator struct {
+	Rand *rand.Rand
+
+	// Prefix constrains the ISBNs generated to a prefix, registration group
+	// or registrant, e.g. "978", "978-0" or "978-0-7167". Empty allows any
+	// assigned range.
+	Prefix string
+}
+
+// New returns a Generator seeded with seed.
+func New(seed int64) *Generator {
+	return &Generator{Rand: rand.New(rand.NewSource(seed))}
+}
+
+// ISBN13 returns a valid ISBN that is assigned in the active range table.
+// It panics if no assigned range matches Prefix.
+func (g *Generator) ISBN13() isbn.ISBN {
+	rs := isbn.CurrentRanges()
+	want := strings.Split(g.Prefix, "-")
+
+	for i := 0; i < maxAttempts; i++ {
+		if v, ok := g.try(rs, want); ok {
+			return v
+		}
+	}
+	panic(fmt.Sprintf("isbntest: no assigned range matches prefix %q", g.Prefix))
+}
+
+// ISBN10 returns the ISBN 10 form of a valid and assigned ISBN. Prefix
+// defaults to "978", the only prefix with an ISBN 10 form.
+func (g *Generator) ISBN10() string {
+	h := *g
+	if h.Prefix == "" {
+		h.Prefix = "978"
+	}
+
+	s, err := h.ISBN13().ISBN10()
+	if err != nil {
+		panic(fmt.Sprintf("isbntest: prefix %q has no ISBN 10 form", g.Prefix))
+	}
+	return s
+}
+
+// Hyphenated returns the hyphenated form of a valid and assigned ISBN.
+func (g *Generator) Hyphenated() string {
+	s, err := g.ISBN13().Hyphenate()
+	if err != nil {
+		// ISBN13 only returns assigned ISBNs
+		panic(err)
+	}
+	return s
+}
+
+func (g *Generator) try(rs *isbn.Ranges, want []string) (v isbn.ISBN, ok bool) {
+	var groups []isbn.RangeGroup
+	for _, rg := range rs.Groups {
+		p := strings.Split(rg.Prefix, "-")
+		if want[0] != "" && want[0] != p[0] || len(want) > 1 && want[1] != p[1] {
+			continue
+		}
+		groups = append(groups, rg)
+	}
+	if len(groups) == 0 {
+		return v, false
+	}
+
+	rg := groups[g.intn(len(groups))]
+	prefix := strings.ReplaceAll(rg.Prefix, "-", "")
+
+	// digits between the registration group and the check digit
+	n := 12 - len(prefix)
+
+	var body string
+	if len(want) > 2 {
+		if len(want[2]) >= n {
+			return v, false
+		}
+		body = want[2] + g.digits(n-len(want[2]))
+	} else {
+		var rules []isbn.RangeRule
+		for _, r := range rg.Rules {
+			if r.Length > 0 {
+				rules = append(rules, r)
+			}
+		}
+		if len(rules) == 0 {
+			return v, false
+		}
+		r := rules[g.intn(len(rules))]
+		body = fmt.Sprintf("%07d", r.Start+g.intn(r.End-r.Start+1)) + g.digits(n)
+	}
+
+	v = withCheckDigit(prefix + body[:n])
+	parts, err := rs.Parts(v)
+	if err != nil || len(want) > 2 && parts.Registrant != want[2] {
+		return v, false
+	}
+	return v, true
+}
+
+// ErrorClass is a class of invalid value produced by Generator.Invalid.
+type ErrorClass int
+
+const (
+	// InvalidLength is a value of no ISBN length, failing with isbn.ErrLength.
+	InvalidLength ErrorClass = iota
+	// InvalidFormat is an ISBN with a non digit, failing with isbn.ErrFormat.
+	InvalidFormat
+	// InvalidCheckDigit is an ISBN with the wrong check digit, failing with
+	// isbn.ErrValue.
+	InvalidCheckDigit
+	// UnassignedGroup is an ISBN in an unassigned registration group. It
+	// only fails with isbn.ParseAssigned, with isbn.ErrGroup.
+	UnassignedGroup
+	// UnassignedRegistrant is an ISBN in an unassigned registrant range. It
+	// only fails with isbn.ParseAssigned, with isbn.ErrRegistrant.
+	UnassignedRegistrant
+
+	numErrorClasses
+)
+
+// Err returns the error isbn.ParseAssigned reports for the class. isbn.Parse
+// reports the same error, except that it accepts the Unassigned classes.
+func (c ErrorClass) Err() error {
+	switch c {
+	case InvalidLength:
+		return isbn.ErrLength
+	case InvalidFormat:
+		return isbn.ErrFormat
+	case InvalidCheckDigit:
+		return isbn.ErrValue
+	case UnassignedGroup:
+		return isbn.ErrGroup
+	case UnassignedRegistrant:
+		return isbn.ErrRegistrant
+	default:
+		return nil
+	}
+}
+
+func (c ErrorClass) String() string {
+	switch c {
+	case InvalidLength:
+		return "invalid length"
+	case InvalidFormat:
+		return "invalid format"
+	case InvalidCheckDigit:
+		return "invalid check digit"
+	case UnassignedGroup:
+		return "unassigned group"
+	case UnassignedRegistrant:
+		return "unassigned registrant"
+	default:
+		return "ErrorClass(" + strconv.Itoa(int(c)) + ")"
+	}
+}
+
+// Invalid returns a value that isbn.Parse rejects with the error of class c.
+// It panics if the active range table has no unassigned range for c.
+func (g *Generator) Invalid(c ErrorClass) string {
+	switch c {
+	case InvalidLength:
+		for {
+			if n := 1 + g.intn(20); n != 10 && n != 13 && n != 17 {
+				return g.digits(n)
+			}
+		}
+	case InvalidFormat:
+		s := []byte(g.ISBN13().String())
+		s[g.intn(len(s))] = byte('a' + g.intn(26))
+		return string(s)
+	case InvalidCheckDigit:
+		s := []byte(g.ISBN13().String())
+		s[12] = byte('0' + (int(s[12]-'0')+1+g.intn(9))%10)
+		return string(s)
+	case UnassignedGroup:
+		return g.unassigned(isbn.CurrentRanges().Prefixes, c)
+	case UnassignedRegistrant:
+		return g.unassigned(isbn.CurrentRanges().Groups, c)
+	default:
+		panic(fmt.Sprintf("isbntest: unknown %v", c))
+	}
+}
+
+// unassigned returns an ISBN in one of the unused ranges of groups.
+func (g *Generator) unassigned(groups []isbn.RangeGroup, c ErrorClass) string {
+	type unused struct {
+		prefix string
+		rule   isbn.RangeRule
+	}
+
+	var ranges []unused
+	for _, rg := range groups {
+		for _, r := range rg.Rules {
+			if r.Length == 0 {
+				ranges = append(ranges, unused{strings.ReplaceAll(rg.Prefix, "-", ""), r})
+			}
+		}
+	}
+	if len(ranges) == 0 {
+		panic(fmt.Sprintf("isbntest: no ranges for %v", c))
+	}
+
+	for i := 0; i < maxAttempts; i++ {
+		u := ranges[g.intn(len(ranges))]
+		n := 12 - len(u.prefix)
+		body := fmt.Sprintf("%07d", u.rule.Start+g.intn(u.rule.End-u.rule.Start+1)) + g.digits(n)
+
+		// the digits following a short prefix may fall outside of the rule
+		s := withCheckDigit(u.prefix + body[:n]).String()
+		if _, err := isbn.ParseAssigned(s); err == c.Err() {
+			return s
+		}
+	}
+	panic(fmt.Sprintf("isbntest: no ranges for %v", c))
+}
+
+func (g *Generator) intn(n int) int {
+	if g.Rand == nil {
+		return rand.Intn(n)
+	}
+	return g.Rand.Intn(n)
+}
+
+func (g *Generator) digits(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte('0' + g.intn(10))
+	}
+	return string(b)
+}
+
+func withCheckDigit(s string) (v isbn.ISBN) {
+	var acc int
+	for i := 0; i < 12; i++ {
+		acc += int(s[i]-'0') * (1 + 2*(i%2))
+	}
+	copy(v[:], s)
+	v[12] = byte('0' + (10-acc%10)%10)
+	return v
+}
+
+// ISBN is an isbn.ISBN that implements testing/quick.Generator, generating
+// valid and assigned ISBNs.
+type ISBN isbn.ISBN
+
+// Generate implements testing/quick.Generator.
+func (ISBN) Generate(r *rand.Rand, size int) reflect.Value {
+	g := Generator{Rand: r}
+	return reflect.ValueOf(ISBN(g.ISBN13()))
+}
+
+// Invalid is a value rejected by isbn.Parse with an error of Class. It
+// implements testing/quick.Generator.
+type Invalid struct {
+	Value string
+	Class ErrorClass
+}
+
+// Generate implements testing/quick.Generator.
+func (Invalid) Generate(r *rand.Rand, size int) reflect.Value {
+	g := Generator{Rand: r}
+	c := ErrorClass(r.Intn(int(numErrorClasses)))
+	return reflect.ValueOf(Invalid{Value: g.Invalid(c), Class: c})
+}
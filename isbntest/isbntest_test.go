@@ -0,0 +1,91 @@
+package isbntest
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"github.com/adoublef-go/isbn"
+	"github.com/hyphengolang/prelude/testing/is"
+)
+
+func TestGenerator(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	tt := []struct {
+		desc   string
+		prefix string
+	}{
+		{desc: "any assigned range", prefix: ""},
+		{desc: "prefix", prefix: "979"},
+		{desc: "registration group", prefix: "978-1"},
+		{desc: "registrant", prefix: "978-0-7167"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			g := New(1)
+			g.Prefix = tc.prefix
+
+			for i := 0; i < 100; i++ {
+				v := g.ISBN13()
+				_, err := isbn.ParseAssigned(v.String())
+				is.NoErr(err) // valid and assigned
+
+				hyphen := g.Hyphenated()
+				is.True(strings.HasPrefix(hyphen, tc.prefix)) // constrained to prefix
+
+				parsed, err := isbn.Parse(strings.ReplaceAll(hyphen, "-", ""))
+				is.NoErr(err) // hyphenated form parses
+
+				want, _ := parsed.Hyphenate()
+				is.Equal(hyphen, want) // hyphenated correctly
+			}
+		})
+	}
+
+	t.Run("isbn 10", func(t *testing.T) {
+		g := New(1)
+		for i := 0; i < 100; i++ {
+			s := g.ISBN10()
+			is.Equal(len(s), 10) // isbn 10 length
+
+			_, err := isbn.ParseAssigned(s)
+			is.NoErr(err) // valid and assigned
+		}
+	})
+}
+
+func TestInvalid(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	g := New(1)
+	for c := InvalidLength; c < numErrorClasses; c++ {
+		t.Run(c.String(), func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				_, err := isbn.ParseAssigned(g.Invalid(c))
+				is.True(errors.Is(err, c.Err())) // error of class
+			}
+		})
+	}
+}
+
+func TestQuick(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	err := quick.Check(func(v ISBN) bool {
+		_, err := isbn.ParseAssigned(isbn.ISBN(v).String())
+		return err == nil
+	}, nil)
+	is.NoErr(err) // generated ISBNs are valid
+
+	err = quick.Check(func(v Invalid) bool {
+		_, err := isbn.ParseAssigned(v.Value)
+		return errors.Is(err, v.Class.Err())
+	}, nil)
+	is.NoErr(err) // generated values are invalid
+}
@@ -0,0 +1,135 @@
+package linkeddata
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/adoublef-go/isbn"
+)
+
+// Namespaces of a Dublin Core record.
+const (
+	DCNamespace    = "http://purl.org/dc/elements/1.1/"
+	OAIDCNamespace = "http://www.openarchives.org/OAI/2.0/oai_dc/"
+)
+
+// titleSeparator separates a title from its subtitle in dc:title, as in
+// ISBD punctuation.
+const titleSeparator = " : "
+
+// MarshalXML writes e as an oai_dc Dublin Core record:
+//
+//	<oai_dc:dc xmlns:oai_dc="..." xmlns:dc="http://purl.org/dc/elements/1.1/">
+//	  <dc:title>Fantastic Mr Fox</dc:title>
+//	  <dc:creator>Roald Dahl</dc:creator>
+//	  <dc:identifier>urn:isbn:9780140328721</dc:identifier>
+//	  ...
+//	</oai_dc:dc>
+//
+// A subtitle is written after the title, separated by " : ". Simple Dublin
+// Core has no elements for the edition, number of pages or cover of a book,
+// which are not written.
+func (e Edition) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	// encoding/xml does not write namespace prefixes, so they are written as
+	// part of the local names
+	start = xml.StartElement{
+		Name: xml.Name{Local: "oai_dc:dc"},
+		Attr: []xml.Attr{
+			{Name: xml.Name{Local: "xmlns:oai_dc"}, Value: OAIDCNamespace},
+			{Name: xml.Name{Local: "xmlns:dc"}, Value: DCNamespace},
+		},
+	}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+
+	title := e.Title
+	if e.Subtitle != "" {
+		title += titleSeparator + e.Subtitle
+	}
+
+	var err error
+	element := func(name, value string) {
+		if value != "" && err == nil {
+			err = enc.EncodeElement(value, xml.StartElement{Name: xml.Name{Local: "dc:" + name}})
+		}
+	}
+	element("title", title)
+	for _, a := range e.Authors {
+		element("creator", a)
+	}
+	element("publisher", e.Publisher)
+	element("date", e.Published)
+	element("type", "Text")
+	element("format", string(e.BookFormat))
+	if e.ISBN != (isbn.ISBN{}) {
+		element("identifier", urn(e.ISBN))
+	}
+	element("language", e.Language)
+	for _, s := range e.Subjects {
+		element("subject", s)
+	}
+	element("description", e.Description)
+	if err != nil {
+		return err
+	}
+
+	return enc.EncodeToken(start.End())
+}
+
+type dcRecord struct {
+	Title       []string `xml:"http://purl.org/dc/elements/1.1/ title"`
+	Creator     []string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Publisher   []string `xml:"http://purl.org/dc/elements/1.1/ publisher"`
+	Date        []string `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Format      []string `xml:"http://purl.org/dc/elements/1.1/ format"`
+	Identifier  []string `xml:"http://purl.org/dc/elements/1.1/ identifier"`
+	Language    []string `xml:"http://purl.org/dc/elements/1.1/ language"`
+	Subject     []string `xml:"http://purl.org/dc/elements/1.1/ subject"`
+	Description []string `xml:"http://purl.org/dc/elements/1.1/ description"`
+}
+
+// UnmarshalXML reads a Dublin Core record of any container element. The
+// ISBN is that of the first identifier that is an ISBN URN, or that starts
+// with "ISBN"; a record without one is read with the zero ISBN.
+func (e *Edition) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	var r dcRecord
+	if err := dec.DecodeElement(&r, &start); err != nil {
+		return err
+	}
+
+	*e = Edition{}
+	for _, id := range r.Identifier {
+		if !isISBN(id) {
+			continue
+		}
+		v, err := parseURN(id)
+		if err != nil {
+			return fmt.Errorf("linkeddata: identifier %q: %w", id, err)
+		}
+		e.ISBN = v
+		break
+	}
+
+	first := func(vs []string) string {
+		if len(vs) == 0 {
+			return ""
+		}
+		return strings.TrimSpace(vs[0])
+	}
+	title := first(r.Title)
+	if t, sub, ok := strings.Cut(title, titleSeparator); ok {
+		e.Title, e.Subtitle = t, sub
+	} else {
+		e.Title = title
+	}
+	e.Authors = r.Creator
+	e.Publisher = first(r.Publisher)
+	e.Published = first(r.Date)
+	e.BookFormat = parseFormat(first(r.Format))
+	e.Language = first(r.Language)
+	e.Subjects = r.Subject
+	e.Description = first(r.Description)
+	return nil
+}
@@ -0,0 +1,287 @@
+package linkeddata
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/adoublef-go/isbn"
+)
+
+// Context is the JSON-LD context of a Work.
+const Context = "https://schema.org"
+
+type node struct {
+	Context             string   `json:"@context,omitempty"`
+	Type                string   `json:"@type"`
+	ID                  string   `json:"@id,omitempty"`
+	Name                string   `json:"name,omitempty"`
+	AlternativeHeadline string   `json:"alternativeHeadline,omitempty"`
+	Author              []thing  `json:"author,omitempty"`
+	ISBN                string   `json:"isbn,omitempty"`
+	BookEdition         string   `json:"bookEdition,omitempty"`
+	BookFormat          string   `json:"bookFormat,omitempty"`
+	Publisher           *thing   `json:"publisher,omitempty"`
+	DatePublished       string   `json:"datePublished,omitempty"`
+	NumberOfPages       int      `json:"numberOfPages,omitempty"`
+	InLanguage          string   `json:"inLanguage,omitempty"`
+	Keywords            []string `json:"keywords,omitempty"`
+	Description         string   `json:"description,omitempty"`
+	Image               string   `json:"image,omitempty"`
+	WorkExample         []node   `json:"workExample,omitempty"`
+}
+
+type thing struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+}
+
+func people(names []string) []thing {
+	var things []thing
+	for _, name := range names {
+		things = append(things, thing{"Person", name})
+	}
+	return things
+}
+
+// MarshalJSON returns w as a JSON-LD schema.org Book, with its editions as
+// workExample. The authors of an edition are only given if they differ from
+// those of the work.
+func (w Work) MarshalJSON() ([]byte, error) {
+	n := node{
+		Context: Context,
+		Type:    "Book",
+		Name:    w.Name,
+		Author:  people(w.Authors),
+	}
+	for _, e := range w.Editions {
+		ed := node{
+			Type:                "Book",
+			Name:                e.Title,
+			AlternativeHeadline: e.Subtitle,
+			BookEdition:         e.BookEdition,
+			DatePublished:       e.Published,
+			NumberOfPages:       e.Pages,
+			InLanguage:          e.Language,
+			Keywords:            e.Subjects,
+			Description:         e.Description,
+			Image:               e.CoverURL,
+		}
+		if e.ISBN != (isbn.ISBN{}) {
+			ed.ID, ed.ISBN = urn(e.ISBN), e.ISBN.String()
+		}
+		if !equal(e.Authors, w.Authors) {
+			ed.Author = people(e.Authors)
+		}
+		if e.BookFormat != "" {
+			ed.BookFormat = Context + "/" + string(e.BookFormat)
+		}
+		if e.Publisher != "" {
+			ed.Publisher = &thing{"Organization", e.Publisher}
+		}
+		n.WorkExample = append(n.WorkExample, ed)
+	}
+	return json.Marshal(n)
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// rawNode is a node as it may be written by others, where most properties
+// may be a single value or an array, and a text or a thing.
+type rawNode struct {
+	ID                  string          `json:"@id"`
+	Name                string          `json:"name"`
+	AlternativeHeadline string          `json:"alternativeHeadline"`
+	Author              json.RawMessage `json:"author"`
+	ISBN                string          `json:"isbn"`
+	BookEdition         string          `json:"bookEdition"`
+	BookFormat          string          `json:"bookFormat"`
+	Publisher           json.RawMessage `json:"publisher"`
+	DatePublished       string          `json:"datePublished"`
+	NumberOfPages       json.RawMessage `json:"numberOfPages"`
+	InLanguage          string          `json:"inLanguage"`
+	Keywords            json.RawMessage `json:"keywords"`
+	Description         string          `json:"description"`
+	Image               json.RawMessage `json:"image"`
+	WorkExample         json.RawMessage `json:"workExample"`
+}
+
+// UnmarshalJSON reads a JSON-LD schema.org Book. A Book without workExample
+// is read as a work of a single edition, itself. Editions without authors or
+// a name take those of the work, and editions without an ISBN have the zero
+// ISBN, as MarshalJSON writes them.
+func (w *Work) UnmarshalJSON(b []byte) error {
+	var n rawNode
+	if err := json.Unmarshal(b, &n); err != nil {
+		return err
+	}
+
+	authors, err := names(n.Author)
+	if err != nil {
+		return fmt.Errorf("linkeddata: author: %w", err)
+	}
+	*w = Work{Name: n.Name, Authors: authors}
+
+	examples, err := many(n.WorkExample)
+	if err != nil {
+		return fmt.Errorf("linkeddata: workExample: %w", err)
+	}
+	if len(examples) == 0 {
+		examples = []json.RawMessage{b}
+	}
+
+	for _, raw := range examples {
+		var n rawNode
+		if err := json.Unmarshal(raw, &n); err != nil {
+			return err
+		}
+		e, err := n.edition()
+		if err != nil {
+			return err
+		}
+		if e.Title == "" {
+			e.Title = w.Name
+		}
+		if e.Authors == nil {
+			e.Authors = w.Authors
+		}
+		w.Editions = append(w.Editions, e)
+	}
+	return nil
+}
+
+func (n *rawNode) edition() (e Edition, err error) {
+	switch {
+	case n.ISBN != "":
+		e.ISBN, err = parseURN(n.ISBN)
+	case isISBN(n.ID):
+		e.ISBN, err = parseURN(n.ID)
+	}
+	if err != nil {
+		return e, fmt.Errorf("linkeddata: isbn: %w", err)
+	}
+
+	e.Title = n.Name
+	e.Subtitle = n.AlternativeHeadline
+	e.BookEdition = n.BookEdition
+	e.BookFormat = parseFormat(n.BookFormat)
+	e.Published = n.DatePublished
+	e.Language = n.InLanguage
+	e.Description = n.Description
+
+	if e.Authors, err = names(n.Author); err != nil {
+		return e, fmt.Errorf("linkeddata: author: %w", err)
+	}
+	publishers, err := names(n.Publisher)
+	if err != nil {
+		return e, fmt.Errorf("linkeddata: publisher: %w", err)
+	}
+	if len(publishers) > 0 {
+		e.Publisher = publishers[0]
+	}
+	if e.Subjects, err = keywords(n.Keywords); err != nil {
+		return e, fmt.Errorf("linkeddata: keywords: %w", err)
+	}
+	if e.Pages, err = pages(n.NumberOfPages); err != nil {
+		return e, fmt.Errorf("linkeddata: numberOfPages: %w", err)
+	}
+	images, err := urls(n.Image)
+	if err != nil {
+		return e, fmt.Errorf("linkeddata: image: %w", err)
+	}
+	if len(images) > 0 {
+		e.CoverURL = images[0]
+	}
+	return e, nil
+}
+
+// many returns the values of a property, which may be a single value or an
+// array.
+func many(raw json.RawMessage) ([]json.RawMessage, error) {
+	raw = json.RawMessage(strings.TrimSpace(string(raw)))
+	switch {
+	case len(raw) == 0 || string(raw) == "null":
+		return nil, nil
+	case raw[0] == '[':
+		var vs []json.RawMessage
+		err := json.Unmarshal(raw, &vs)
+		return vs, err
+	default:
+		return []json.RawMessage{raw}, nil
+	}
+}
+
+// names returns the names of a property whose values are text or things,
+// such as author or publisher.
+func names(raw json.RawMessage) ([]string, error) { return texts(raw, "name") }
+
+// urls returns the URLs of a property whose values are text or things, such
+// as image, which may be an ImageObject.
+func urls(raw json.RawMessage) ([]string, error) { return texts(raw, "url") }
+
+func texts(raw json.RawMessage, key string) ([]string, error) {
+	vs, err := many(raw)
+	if err != nil {
+		return nil, err
+	}
+	var ss []string
+	for _, v := range vs {
+		if len(v) > 0 && v[0] == '{' {
+			var t map[string]json.RawMessage
+			if err := json.Unmarshal(v, &t); err != nil {
+				return nil, err
+			}
+			if v = t[key]; v == nil {
+				continue
+			}
+		}
+		var s string
+		if err := json.Unmarshal(v, &s); err != nil {
+			return nil, err
+		}
+		if s != "" {
+			ss = append(ss, s)
+		}
+	}
+	return ss, nil
+}
+
+// keywords returns an array of keywords, or a single comma separated text.
+func keywords(raw json.RawMessage) ([]string, error) {
+	ss, err := texts(raw, "name")
+	if err != nil || len(ss) != 1 || raw[0] == '[' {
+		return ss, err
+	}
+	var kws []string
+	for _, s := range strings.Split(ss[0], ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			kws = append(kws, s)
+		}
+	}
+	return kws, nil
+}
+
+// pages returns a number of pages given as a number or text.
+func pages(raw json.RawMessage) (int, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return 0, nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return strconv.Atoi(strings.TrimSpace(s))
+	}
+	var n int
+	err := json.Unmarshal(raw, &n)
+	return n, err
+}
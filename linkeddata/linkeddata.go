@@ -0,0 +1,105 @@
+// Package linkeddata renders book metadata as linked data: schema.org JSON-LD
+// for search engines, and Dublin Core XML for library and archive feeds.
+//
+// A Work is a title and its editions, each a book with its own ISBN. As
+// JSON-LD, the work is a schema.org Book whose editions are given by
+// workExample:
+//
+//	{
+//	  "@context": "https://schema.org",
+//	  "@type": "Book",
+//	  "name": "Fantastic Mr Fox",
+//	  "author": [{"@type": "Person", "name": "Roald Dahl"}],
+//	  "workExample": [{
+//	    "@type": "Book",
+//	    "@id": "urn:isbn:9780140328721",
+//	    "isbn": "9780140328721",
+//	    "bookEdition": "1st",
+//	    "bookFormat": "https://schema.org/Paperback",
+//	    ...
+//	  }]
+//	}
+//
+// As Dublin Core, each edition is an oai_dc record identified by the URN of
+// its ISBN, e.g. <dc:identifier>urn:isbn:9780140328721</dc:identifier>.
+package linkeddata
+
+import (
+	"strings"
+
+	"github.com/adoublef-go/isbn"
+	"github.com/adoublef-go/isbn/metadata"
+)
+
+// Work is a book, as a creative work, and its editions.
+type Work struct {
+	Name    string
+	Authors []string
+
+	Editions []Edition
+}
+
+// NewWork returns the work of books, which are taken to be editions of the
+// same title. The name and authors of the work are those of the first book.
+func NewWork(books ...metadata.Book) Work {
+	var w Work
+	if len(books) > 0 {
+		w.Name, w.Authors = books[0].Title, books[0].Authors
+	}
+	for _, book := range books {
+		w.Editions = append(w.Editions, Edition{Book: book})
+	}
+	return w
+}
+
+// Edition is an edition of a work.
+type Edition struct {
+	metadata.Book
+
+	// BookEdition is the edition statement, e.g. "2nd" or "Revised".
+	BookEdition string
+	BookFormat  Format
+}
+
+// Format is the schema.org BookFormatType of an edition.
+type Format string
+
+const (
+	AudiobookFormat Format = "AudiobookFormat"
+	EBook           Format = "EBook"
+	GraphicNovel    Format = "GraphicNovel"
+	Hardcover       Format = "Hardcover"
+	Paperback       Format = "Paperback"
+)
+
+// parseFormat returns the format of a schema.org URL or name, e.g.
+// "https://schema.org/Paperback" or "Paperback".
+func parseFormat(s string) Format {
+	for _, prefix := range []string{"https://schema.org/", "http://schema.org/", "schema:"} {
+		s = strings.TrimPrefix(s, prefix)
+	}
+	return Format(s)
+}
+
+const urnPrefix = "urn:isbn:"
+
+// urn returns the URN of an ISBN, e.g. urn:isbn:9780140328721.
+func urn(v isbn.ISBN) string { return urnPrefix + v.String() }
+
+// parseURN parses an ISBN given either as a URN or plainly, with an optional
+// "ISBN" prefix as is common in Dublin Core identifiers.
+func parseURN(s string) (isbn.ISBN, error) {
+	s = strings.TrimSpace(s)
+	if len(s) >= len(urnPrefix) && strings.EqualFold(s[:len(urnPrefix)], urnPrefix) {
+		s = s[len(urnPrefix):]
+	} else if len(s) > 4 && strings.EqualFold(s[:4], "isbn") {
+		s = strings.TrimLeft(s[4:], ": ")
+	}
+	return isbn.Parse(s)
+}
+
+// isISBN reports whether a Dublin Core identifier is meant as an ISBN.
+func isISBN(s string) bool {
+	s = strings.ToLower(strings.TrimSpace(s))
+	return strings.HasPrefix(s, urnPrefix) || strings.HasPrefix(s, "isbn")
+}
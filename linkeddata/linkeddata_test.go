@@ -0,0 +1,188 @@
+package linkeddata
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/adoublef-go/isbn"
+	"github.com/adoublef-go/isbn/metadata"
+	"github.com/hyphengolang/prelude/testing/is"
+)
+
+var (
+	fox, _   = isbn.Parse("9780140328721")
+	panda, _ = isbn.Parse("9780716703440")
+)
+
+func work() Work {
+	w := NewWork(
+		metadata.Book{
+			ISBN:      fox,
+			Title:     "Fantastic Mr Fox",
+			Authors:   []string{"Roald Dahl"},
+			Publisher: "Puffin",
+			Published: "1988-10-01",
+			Pages:     96,
+			Language:  "en",
+			Subjects:  []string{"Foxes", "Farmers"},
+			CoverURL:  "https://covers.openlibrary.org/b/isbn/9780140328721-L.jpg",
+		},
+		metadata.Book{
+			// an illustrated edition, with a subtitle and another author
+			ISBN:        panda,
+			Title:       "Fantastic Mr Fox",
+			Subtitle:    "Illustrated Edition",
+			Authors:     []string{"Roald Dahl", "Quentin Blake"},
+			Publisher:   "Knopf",
+			Description: "Boggis, Bunce & Bean.",
+		},
+	)
+	w.Editions[0].BookFormat = Paperback
+	w.Editions[1].BookFormat = Hardcover
+	w.Editions[1].BookEdition = "2nd"
+	return w
+}
+
+func TestJSONLD(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	w := work()
+	b, err := json.Marshal(w)
+	is.NoErr(err) // marshalling work
+
+	var doc map[string]any
+	is.NoErr(json.Unmarshal(b, &doc)) // reading json-ld
+
+	examples := doc["workExample"].([]any)
+	first := examples[0].(map[string]any)
+	is.Equal(doc["@context"], "https://schema.org")               // context
+	is.Equal(len(examples), 2)                                    // editions as workExample
+	is.Equal(first["isbn"], "9780140328721")                      // isbn as text
+	is.Equal(first["@id"], "urn:isbn:9780140328721")              // isbn urn as id
+	is.Equal(first["bookFormat"], "https://schema.org/Paperback") // format url
+	is.Equal(first["author"], nil)                                // authors of work
+	is.Equal(examples[1].(map[string]any)["bookEdition"], "2nd")  // edition
+
+	var got Work
+	is.NoErr(json.Unmarshal(b, &got))  // unmarshalling work
+	is.True(reflect.DeepEqual(got, w)) // json-ld round trip
+}
+
+func TestZeroISBN(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	w := NewWork(metadata.Book{Title: "Fantastic Mr Fox"})
+
+	b, err := json.Marshal(w)
+	is.NoErr(err) // marshalling work
+
+	var doc map[string]any
+	is.NoErr(json.Unmarshal(b, &doc)) // reading json-ld
+
+	edition := doc["workExample"].([]any)[0].(map[string]any)
+	is.Equal(edition["@id"], nil)  // no isbn urn
+	is.Equal(edition["isbn"], nil) // no isbn
+
+	var got Work
+	is.NoErr(json.Unmarshal(b, &got))  // unmarshalling work
+	is.True(reflect.DeepEqual(got, w)) // json-ld round trip
+
+	b, err = xml.Marshal(w.Editions[0])
+	is.NoErr(err)                                       // marshalling edition
+	is.True(!strings.Contains(string(b), "identifier")) // no isbn urn
+
+	var e Edition
+	is.NoErr(xml.Unmarshal(b, &e))               // unmarshalling edition
+	is.True(reflect.DeepEqual(e, w.Editions[0])) // dublin core round trip
+}
+
+func TestJSONLDRead(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	b, err := os.ReadFile("testdata/book.jsonld")
+	is.NoErr(err) // reading json-ld
+
+	var w Work
+	is.NoErr(json.Unmarshal(b, &w))      // unmarshalling single book
+	is.Equal(w.Name, "Fantastic Mr Fox") // work name
+	is.Equal(len(w.Editions), 1)         // book is its own edition
+
+	e := w.Editions[0]
+	is.Equal(e.ISBN, fox)                              // isbn from @id
+	is.Equal(e.Authors, []string{"Roald Dahl"})        // author as text
+	is.Equal(e.Publisher, "Puffin")                    // publisher as organization
+	is.Equal(e.BookFormat, Paperback)                  // http format url
+	is.Equal(e.Pages, 96)                              // pages as text
+	is.Equal(e.Subjects, []string{"Foxes", "Farmers"}) // comma separated keywords
+	is.True(strings.HasSuffix(e.CoverURL, "-L.jpg"))   // image object
+
+	tt := []struct {
+		desc string
+		doc  string
+		err  error
+	}{
+		{"no isbn", `{"@type": "Book", "name": "Untitled"}`, nil},
+		{"invalid isbn", `{"@type": "Book", "isbn": "0140328727"}`, isbn.ErrValue},
+		{"edition without isbn", `{"@type": "Book", "workExample": [{"@type": "Book"}]}`, nil},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			var w Work
+			err := json.Unmarshal([]byte(tc.doc), &w)
+			is.True(errors.Is(err, tc.err)) // decoding error
+		})
+	}
+}
+
+func TestDublinCore(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	for _, e := range work().Editions {
+		b, err := xml.Marshal(e)
+		is.NoErr(err) // marshalling edition
+
+		s := string(b)
+		is.True(strings.HasPrefix(s, `<oai_dc:dc xmlns:oai_dc="`+OAIDCNamespace+`" xmlns:dc="`+DCNamespace+`">`)) // oai_dc record
+		is.True(strings.Contains(s, "<dc:identifier>"+urn(e.ISBN)+"</dc:identifier>"))                            // isbn urn
+
+		var got Edition
+		is.NoErr(xml.Unmarshal(b, &got)) // unmarshalling edition
+
+		// simple dublin core has no edition, pages or cover
+		e.BookEdition, e.Pages, e.CoverURL = "", 0, ""
+		is.True(reflect.DeepEqual(got, e)) // dublin core round trip
+	}
+}
+
+func TestDublinCoreRead(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	b, err := os.ReadFile("testdata/book.xml")
+	is.NoErr(err) // reading dublin core
+
+	var e Edition
+	is.NoErr(xml.Unmarshal(b, &e))                                   // unmarshalling record
+	is.Equal(e.ISBN, panda)                                          // isbn identifier
+	is.Equal(e.Title, "The panda's thumb")                           // title
+	is.Equal(e.Subtitle, "more reflections in natural history")      // subtitle
+	is.Equal(e.Subjects, []string{"Evolution.", "Natural history."}) // subjects
+
+	err = xml.Unmarshal([]byte(`<dc xmlns:dc="`+DCNamespace+`"><dc:identifier>urn:isbn:0716703441</dc:identifier></dc>`), &e)
+	is.True(errors.Is(err, isbn.ErrValue)) // invalid isbn urn
+
+	err = xml.Unmarshal([]byte(`<dc xmlns:dc="`+DCNamespace+`"><dc:title>Untitled</dc:title></dc>`), &e)
+	is.NoErr(err)                 // no isbn
+	is.Equal(e.ISBN, isbn.ISBN{}) // zero isbn
+	is.Equal(e.Title, "Untitled") // title
+}
@@ -0,0 +1,200 @@
+package marc
+
+import (
+	"strings"
+
+	"github.com/adoublef-go/isbn"
+)
+
+// ISBNField is an ISBN of a 020 field, from its $a or $z subfield.
+type ISBNField struct {
+	// Value is the subfield as given, e.g. "0716703440 (pbk.)".
+	Value string
+	// ISBN is the ISBN in Value, and Err the error parsing it.
+	ISBN isbn.ISBN
+	Err  error
+	// Cancelled is set for an ISBN from $z, one that is cancelled or
+	// invalid.
+	Cancelled bool
+	// Qualifiers are those in parentheses following the ISBN in Value, as
+	// was the practice before $q, then the $q subfields of the field, each
+	// without parentheses, e.g. "pbk.".
+	Qualifiers []string
+	// Terms is the $c subfield, the terms of availability, e.g. "£7.99".
+	Terms string
+}
+
+// ISBNs returns the ISBNs of the 020 fields of r.
+func (r *Record) ISBNs() []ISBNField {
+	var fields []ISBNField
+	for _, f := range r.Field("020") {
+		var qualifiers []string
+		var terms string
+		for _, sf := range f.Subfields {
+			switch sf.Code {
+			case 'q':
+				qualifiers = append(qualifiers, unparen(sf.Value))
+			case 'c':
+				terms = strings.TrimSpace(sf.Value)
+			}
+		}
+
+		for _, sf := range f.Subfields {
+			if sf.Code != 'a' && sf.Code != 'z' {
+				continue
+			}
+
+			number, inline := splitISBN(sf.Value)
+			field := ISBNField{
+				Value:      sf.Value,
+				Cancelled:  sf.Code == 'z',
+				Qualifiers: append(inline, qualifiers...),
+				Terms:      terms,
+			}
+			field.ISBN, field.Err = isbn.Parse(number)
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// Format is the form RewriteISBNs writes ISBNs in.
+type Format int
+
+const (
+	// ISBN13 is the ISBN 13 form, e.g. 9780140328721.
+	ISBN13 Format = iota
+	// Hyphenated is the hyphenated ISBN 13 form, e.g. 978-0-14-032872-1.
+	// An ISBN outside the registered ranges is written as ISBN13.
+	Hyphenated
+)
+
+func (format Format) string(v isbn.ISBN) string {
+	if format == Hyphenated {
+		if s, err := v.Hyphenate(); err == nil {
+			return s
+		}
+	}
+	return v.String()
+}
+
+// RewriteISBNs rewrites the 020 fields of r in the current style, and
+// returns the number of fields changed:
+//
+//   - a valid ISBN in $a or $z is written in format
+//   - an invalid ISBN in $a is moved to $z
+//   - qualifiers following an ISBN are moved to a $q after it
+//
+// Other subfields are left as they are.
+func (r *Record) RewriteISBNs(format Format) (changed int) {
+	for _, f := range r.Field("020") {
+		have := make(map[string]bool)
+		for _, sf := range f.Subfields {
+			if sf.Code == 'q' {
+				have[unparen(sf.Value)] = true
+			}
+		}
+
+		subfields := make([]Subfield, 0, len(f.Subfields))
+		for _, sf := range f.Subfields {
+			if sf.Code != 'a' && sf.Code != 'z' {
+				subfields = append(subfields, sf)
+				continue
+			}
+
+			number, qualifiers := splitISBN(sf.Value)
+			if number == "" {
+				// no ISBN to rewrite, such as a note in place of one
+				subfields = append(subfields, sf)
+				continue
+			}
+			if v, err := isbn.Parse(number); err == nil {
+				subfields = append(subfields, Subfield{Code: sf.Code, Value: format.string(v)})
+			} else {
+				// $z holds invalid ISBNs as given
+				subfields = append(subfields, Subfield{Code: 'z', Value: number})
+			}
+			for _, q := range qualifiers {
+				if !have[q] {
+					have[q] = true
+					subfields = append(subfields, Subfield{Code: 'q', Value: "(" + q + ")"})
+				}
+			}
+		}
+
+		if !equal(subfields, f.Subfields) {
+			f.Subfields = subfields
+			changed++
+		}
+	}
+	return changed
+}
+
+// splitISBN splits the value of a $a or $z into the ISBN it starts with and
+// the qualifiers that follow, such as "0716703440 (pbk. : alk. paper) :".
+// A label before the ISBN, such as "ISBN" or "ISBN-13:", is left out.
+func splitISBN(s string) (number string, qualifiers []string) {
+	s = trimLabel(strings.TrimSpace(s))
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= '0' && r <= '9' || r == 'X' || r == 'x' || r == '-')
+	})
+	if i < 0 {
+		return s, nil
+	}
+	number, rest := s[:i], s[i:]
+
+	for {
+		// ISBD punctuation preceding $c or another ISBN
+		rest = strings.Trim(rest, " :;,")
+		if rest == "" {
+			return number, qualifiers
+		}
+		if rest[0] != '(' {
+			qualifiers = append(qualifiers, rest)
+			return number, qualifiers
+		}
+		j := strings.IndexByte(rest, ')')
+		if j < 0 {
+			qualifiers = append(qualifiers, strings.TrimSpace(rest[1:]))
+			return number, qualifiers
+		}
+		qualifiers = append(qualifiers, strings.TrimSpace(rest[1:j]))
+		rest = rest[j+1:]
+	}
+}
+
+// trimLabel removes a leading "ISBN", "ISBN-10" or "ISBN-13" label from s,
+// with any colon following it.
+func trimLabel(s string) string {
+	if len(s) < 4 || !strings.EqualFold(s[:4], "ISBN") {
+		return s
+	}
+	rest := s[4:]
+	for _, form := range []string{"-10", "-13"} {
+		if strings.HasPrefix(rest, form) {
+			rest = rest[len(form):]
+			break
+		}
+	}
+	return strings.TrimLeft(rest, " :")
+}
+
+func unparen(s string) string {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		s = strings.TrimSpace(s[1 : len(s)-1])
+	}
+	return s
+}
+
+func equal(a, b []Subfield) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,172 @@
+package marc
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// A Reader reads records in ISO 2709 from a stream.
+type Reader struct {
+	r *bufio.Reader
+	n int
+}
+
+// NewReader returns a Reader reading from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// Next returns the next record, and io.EOF once there are no more.
+func (r *Reader) Next() (*Record, error) {
+	// the record length is checked against the terminator, rather than
+	// trusted, so a bad length fails only its own record
+	b, err := r.r.ReadBytes(recordTerminator)
+	if err == io.EOF {
+		if len(bytes.TrimSpace(b)) == 0 {
+			return nil, io.EOF
+		}
+		return nil, &FormatError{r.n, "missing record terminator"}
+	}
+	if err != nil {
+		return nil, err
+	}
+	// some files separate records with line breaks
+	b = bytes.TrimLeft(b, "\r\n")
+
+	rec, err := parseRecord(b)
+	if err != nil {
+		err = &FormatError{r.n, err.Error()}
+	}
+	r.n++
+	return rec, err
+}
+
+func parseRecord(b []byte) (*Record, error) {
+	if len(b) < 25 {
+		return nil, fmt.Errorf("record too short")
+	}
+	leader := string(b[:24])
+	if n, err := strconv.Atoi(leader[:5]); err != nil || n != len(b) {
+		return nil, fmt.Errorf("record length %q, read %d bytes", leader[:5], len(b))
+	}
+	base, err := strconv.Atoi(leader[12:17])
+	if err != nil || base < 25 || base > len(b) {
+		return nil, fmt.Errorf("invalid base address %q", leader[12:17])
+	}
+
+	dir := b[24 : base-1]
+	if len(dir)%12 != 0 || b[base-1] != fieldTerminator {
+		return nil, fmt.Errorf("invalid directory")
+	}
+	data := b[base:]
+
+	rec := &Record{Leader: leader}
+	for ; len(dir) > 0; dir = dir[12:] {
+		tag := string(dir[:3])
+		length, err1 := strconv.Atoi(string(dir[3:7]))
+		start, err2 := strconv.Atoi(string(dir[7:12]))
+		if err1 != nil || err2 != nil || length < 1 || start+length > len(data) {
+			return nil, fmt.Errorf("invalid directory entry %q", dir[:12])
+		}
+
+		fb := data[start : start+length]
+		if fb[len(fb)-1] != fieldTerminator {
+			return nil, fmt.Errorf("field %s: missing field terminator", tag)
+		}
+		fb = fb[:len(fb)-1]
+
+		f := Field{Tag: tag}
+		if f.IsControl() {
+			f.Value = string(fb)
+			rec.Fields = append(rec.Fields, f)
+			continue
+		}
+
+		if len(fb) < 2 {
+			return nil, fmt.Errorf("field %s: missing indicators", tag)
+		}
+		f.Ind1, f.Ind2 = fb[0], fb[1]
+		for _, sf := range bytes.Split(fb[2:], []byte{subfieldDelimiter})[1:] {
+			if len(sf) == 0 {
+				continue
+			}
+			f.Subfields = append(f.Subfields, Subfield{Code: sf[0], Value: string(sf[1:])})
+		}
+		rec.Fields = append(rec.Fields, f)
+	}
+	return rec, nil
+}
+
+// A Writer writes records in ISO 2709 to a stream.
+type Writer struct {
+	w io.Writer
+}
+
+// NewWriter returns a Writer writing to w.
+func NewWriter(w io.Writer) *Writer { return &Writer{w: w} }
+
+// Write writes rec, setting the lengths and addresses of its leader and
+// directory from its fields.
+func (w *Writer) Write(rec *Record) error {
+	b, err := rec.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	_, err = w.w.Write(b)
+	return err
+}
+
+// MarshalBinary returns rec in ISO 2709.
+func (rec *Record) MarshalBinary() ([]byte, error) {
+	var dir, data bytes.Buffer
+	for _, f := range rec.Fields {
+		if len(f.Tag) != 3 {
+			return nil, fmt.Errorf("marc: invalid tag %q", f.Tag)
+		}
+
+		start := data.Len()
+		if f.IsControl() {
+			data.WriteString(f.Value)
+		} else {
+			data.WriteByte(ind(f.Ind1))
+			data.WriteByte(ind(f.Ind2))
+			for _, sf := range f.Subfields {
+				data.WriteByte(subfieldDelimiter)
+				data.WriteByte(sf.Code)
+				data.WriteString(sf.Value)
+			}
+		}
+		data.WriteByte(fieldTerminator)
+
+		length := data.Len() - start
+		if length > 9999 || start > 99999 {
+			return nil, fmt.Errorf("marc: field %s too long", f.Tag)
+		}
+		fmt.Fprintf(&dir, "%s%04d%05d", f.Tag, length, start)
+	}
+	dir.WriteByte(fieldTerminator)
+	data.WriteByte(recordTerminator)
+
+	base := 24 + dir.Len()
+	length := base + data.Len()
+	if length > 99999 {
+		return nil, fmt.Errorf("marc: record too long")
+	}
+
+	leader := []byte(rec.Leader)
+	if len(leader) != 24 {
+		// a new bibliographic record of a book, coded as UTF-8
+		leader = []byte("00000nam a2200000 i 4500")
+	}
+	copy(leader[:5], fmt.Sprintf("%05d", length))
+	copy(leader[12:17], fmt.Sprintf("%05d", base))
+
+	b := make([]byte, 0, length)
+	b = append(b, leader...)
+	b = append(b, dir.Bytes()...)
+	b = append(b, data.Bytes()...)
+	return b, nil
+}
@@ -0,0 +1,119 @@
+// Package marc reads and writes MARC 21 bibliographic records, as binary
+// ISO 2709 and as MARCXML, with support for the ISBNs of their 020 fields.
+//
+//	r := marc.NewReader(f)
+//	for {
+//		rec, err := r.Next()
+//		if err == io.EOF {
+//			break
+//		}
+//		for _, f := range rec.ISBNs() {
+//			...
+//		}
+//	}
+//
+// Records are held as read. Only ISO 2709 records coded as UTF-8 (leader
+// position 09 'a') are fully supported, MARC-8 records are passed through
+// unconverted, which leaves the ASCII of their ISBNs intact.
+package marc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Delimiters of ISO 2709.
+const (
+	subfieldDelimiter = 0x1f
+	fieldTerminator   = 0x1e
+	recordTerminator  = 0x1d
+)
+
+// Record is a MARC record.
+type Record struct {
+	// Leader is the 24 character leader. The record length and base
+	// address of data (positions 00-04 and 12-16) are set when written.
+	Leader string
+	Fields []Field
+}
+
+// Field is a variable field of a record. A control field, of tag 001 to
+// 009, has a Value. A data field has indicators and subfields.
+type Field struct {
+	Tag   string
+	Value string
+
+	Ind1, Ind2 byte
+	Subfields  []Subfield
+}
+
+// Subfield is a subfield of a data field.
+type Subfield struct {
+	Code  byte
+	Value string
+}
+
+// IsControl reports whether f is a control field.
+func (f *Field) IsControl() bool { return strings.HasPrefix(f.Tag, "00") }
+
+// Subfield returns the value of the first subfield of f with code, and
+// false if there is none.
+func (f *Field) Subfield(code byte) (string, bool) {
+	for _, sf := range f.Subfields {
+		if sf.Code == code {
+			return sf.Value, true
+		}
+	}
+	return "", false
+}
+
+// String returns f in the style of a MARC display, e.g.
+// "020    $a9780140328721$q(pbk.)".
+func (f Field) String() string {
+	if f.IsControl() {
+		return f.Tag + "    " + f.Value
+	}
+	var b strings.Builder
+	b.WriteString(f.Tag + " " + string(ind(f.Ind1)) + string(ind(f.Ind2)) + " ")
+	for _, sf := range f.Subfields {
+		b.WriteString("$" + string(sf.Code) + sf.Value)
+	}
+	return b.String()
+}
+
+func ind(c byte) byte {
+	if c == 0 {
+		return ' '
+	}
+	return c
+}
+
+// Field returns the fields of r with tag.
+func (r *Record) Field(tag string) []*Field {
+	var fs []*Field
+	for i := range r.Fields {
+		if r.Fields[i].Tag == tag {
+			fs = append(fs, &r.Fields[i])
+		}
+	}
+	return fs
+}
+
+// ControlNumber returns the value of the 001 field of r.
+func (r *Record) ControlNumber() string {
+	if fs := r.Field("001"); len(fs) > 0 {
+		return fs[0].Value
+	}
+	return ""
+}
+
+// FormatError is returned for a record that is not valid ISO 2709 or MARCXML.
+type FormatError struct {
+	// Record is the index of the record in the stream.
+	Record int
+	Msg    string
+}
+
+func (err *FormatError) Error() string {
+	return fmt.Sprintf("marc: record %d: %s", err.Record, err.Msg)
+}
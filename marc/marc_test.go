@@ -0,0 +1,205 @@
+package marc
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/adoublef-go/isbn"
+	"github.com/hyphengolang/prelude/testing/is"
+)
+
+type reader interface {
+	Next() (*Record, error)
+}
+
+func readAll(t *testing.T, r reader) []*Record {
+	t.Helper()
+
+	var recs []*Record
+	for {
+		rec, err := r.Next()
+		if err == io.EOF {
+			return recs
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		recs = append(recs, rec)
+	}
+}
+
+func open(t *testing.T, name string) *os.File {
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+func TestRead(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	iso := readAll(t, NewReader(open(t, "testdata/records.mrc")))
+	xml := readAll(t, NewXMLReader(open(t, "testdata/records.xml")))
+
+	is.Equal(len(iso), 3)                // records in iso 2709
+	is.True(reflect.DeepEqual(iso, xml)) // same records in marcxml
+
+	rec := iso[0]
+	is.Equal(rec.ControlNumber(), "ocm00000001") // control number
+	is.Equal(len(rec.Fields), 7)                 // fields
+
+	title := rec.Field("245")[0]
+	is.Equal(title.Ind1, byte('1'))                                      // first indicator
+	is.Equal(title.Ind2, byte('0'))                                      // second indicator
+	is.Equal(title.String(), "245 10 $aFantastic Mr Fox /$cRoald Dahl.") // display form
+
+	v, _ := iso[2].Field("245")[0].Subfield('a')
+	is.Equal(v, "Les Misérables.") // utf 8 subfield
+}
+
+func TestReadInvalid(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	b, err := os.ReadFile("testdata/records.mrc")
+	is.NoErr(err) // reading records
+
+	tt := []struct {
+		desc string
+		data []byte
+	}{
+		{"truncated", b[:100]},
+		{"bad length", append([]byte("99999"), b[5:]...)},
+		{"not marc", []byte("hello, world\x1d")},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := NewReader(bytes.NewReader(tc.data)).Next()
+
+			var ferr *FormatError
+			is.True(errors.As(err, &ferr)) // format error
+			is.Equal(ferr.Record, 0)       // first record
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	want, err := os.ReadFile("testdata/records.mrc")
+	is.NoErr(err) // reading records
+	recs := readAll(t, NewReader(bytes.NewReader(want)))
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	for _, rec := range recs {
+		is.NoErr(w.Write(rec)) // writing iso 2709
+	}
+	is.Equal(buf.String(), string(want)) // iso 2709 round trip
+
+	buf.Reset()
+	xw := NewXMLWriter(&buf)
+	for _, rec := range recs {
+		is.NoErr(xw.Write(rec)) // writing marcxml
+	}
+	is.NoErr(xw.Close()) // ending collection
+	is.NoErr(xw.Close()) // closing twice
+
+	got := readAll(t, NewXMLReader(&buf))
+	is.True(reflect.DeepEqual(got, recs)) // marcxml round trip
+
+	// a new record is given a leader
+	rec := &Record{Fields: []Field{{Tag: "001", Value: "1"}, {Tag: "020", Subfields: []Subfield{{'a', "9780140328721"}}}}}
+	b, err := rec.MarshalBinary()
+	is.NoErr(err) // marshalling new record
+
+	got = readAll(t, NewReader(bytes.NewReader(b)))
+	is.Equal(got[0].Fields[1].Ind1, byte(' ')) // blank indicator
+	is.Equal(got[0].Leader[5:12], "nam a22")   // default leader
+}
+
+func TestISBNs(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	recs := readAll(t, NewReader(open(t, "testdata/records.mrc")))
+
+	fox, _ := isbn.Parse("9780140328721")
+	fields := recs[0].ISBNs()
+	is.Equal(len(fields), 3) // isbns in record
+
+	is.Equal(fields[0].ISBN, fox)                         // isbn 10 in $a
+	is.NoErr(fields[0].Err)                               // valid isbn
+	is.Equal(fields[0].Qualifiers, []string{"pbk."})      // inline qualifier
+	is.Equal(fields[0].Terms, "£4.99")                    // terms of availability
+	is.Equal(fields[1].Qualifiers, []string{"paperback"}) // $q qualifier
+	is.True(fields[2].Cancelled)                          // $z isbn
+	is.True(errors.Is(fields[2].Err, isbn.ErrValue))      // invalid check digit
+	is.Equal(fields[2].Value, "0140328727")               // value as given
+	is.Equal(len(recs[2].ISBNs()), 0)                     // no 020 fields
+
+	fields = recs[1].ISBNs()
+	is.Equal(fields[0].Qualifiers, []string{"hardcover"})  // parenthesised $q
+	is.Equal(fields[1].Qualifiers, []string{"alk. paper"}) // inline qualifier
+	is.True(fields[1].Err != nil)                          // invalid isbn in $a
+}
+
+func TestRewriteISBNs(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	recs := readAll(t, NewReader(open(t, "testdata/records.mrc")))
+
+	display := func(rec *Record) string {
+		var lines []string
+		for _, f := range rec.Field("020") {
+			lines = append(lines, f.String())
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	is.Equal(recs[0].RewriteISBNs(Hyphenated), 2) // fields changed
+	is.Equal(display(recs[0]), strings.Join([]string{
+		"020    $a978-0-14-032872-1$q(pbk.)$c£4.99",
+		"020    $a978-0-14-032872-1$qpaperback",
+		"020    $z0140328727",
+	}, "\n")) // hyphenated
+
+	is.Equal(recs[0].RewriteISBNs(Hyphenated), 0) // rewrite is idempotent
+
+	is.Equal(recs[1].RewriteISBNs(ISBN13), 2) // fields changed
+	is.Equal(display(recs[1]), strings.Join([]string{
+		"020    $a9780716703440$q(hardcover)",
+		"020    $z0716703441$q(alk. paper)",
+	}, "\n")) // invalid isbn moved to $z
+
+	rec := &Record{Fields: []Field{
+		{Tag: "020", Subfields: []Subfield{{'a', "ISBN 0716703440 (pbk.)"}}},
+		{Tag: "020", Subfields: []Subfield{{'a', "ISBN-13: 9780140328721"}}},
+		{Tag: "020", Subfields: []Subfield{{'a', "(pbk.)"}, {'c', "£4.99"}}},
+	}}
+	is.Equal(rec.ISBNs()[0].ISBN.String(), "9780716703440") // isbn after label
+	is.Equal(rec.RewriteISBNs(ISBN13), 2)                   // fields changed
+	is.Equal(display(rec), strings.Join([]string{
+		"020    $a9780716703440$q(pbk.)",
+		"020    $a9780140328721",
+		"020    $a(pbk.)$c£4.99",
+	}, "\n")) // labels removed, field without isbn unchanged
+
+	// the rewritten record is still valid
+	b, err := recs[1].MarshalBinary()
+	is.NoErr(err) // marshalling rewritten record
+
+	got := readAll(t, NewReader(bytes.NewReader(b)))
+	is.True(reflect.DeepEqual(got[0].Fields, recs[1].Fields)) // rewritten record round trip
+}
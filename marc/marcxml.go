@@ -0,0 +1,201 @@
+package marc
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// Namespace is the XML namespace of MARCXML.
+const Namespace = "http://www.loc.gov/MARC21/slim"
+
+type xmlRecord struct {
+	XMLName       xml.Name          `xml:"record"`
+	Leader        string            `xml:"leader"`
+	ControlFields []xmlControlField `xml:"controlfield"`
+	DataFields    []xmlDataField    `xml:"datafield"`
+}
+
+type xmlControlField struct {
+	Tag   string `xml:"tag,attr"`
+	Value string `xml:",chardata"`
+}
+
+type xmlDataField struct {
+	Tag       string        `xml:"tag,attr"`
+	Ind1      string        `xml:"ind1,attr"`
+	Ind2      string        `xml:"ind2,attr"`
+	Subfields []xmlSubfield `xml:"subfield"`
+}
+
+type xmlSubfield struct {
+	Code  string `xml:"code,attr"`
+	Value string `xml:",chardata"`
+}
+
+// An XMLReader reads records from a MARCXML collection, or a single MARCXML
+// record, one at a time.
+type XMLReader struct {
+	d *xml.Decoder
+	n int
+}
+
+// NewXMLReader returns an XMLReader reading from r.
+func NewXMLReader(r io.Reader) *XMLReader {
+	return &XMLReader{d: xml.NewDecoder(r)}
+}
+
+// Next returns the next record, and io.EOF once there are no more.
+func (r *XMLReader) Next() (*Record, error) {
+	for {
+		tok, err := r.d.Token()
+		if err != nil {
+			return nil, err
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "record" {
+			continue
+		}
+
+		// MARCXML keeps control and data fields apart, but they are
+		// decoded in document order
+		rec, err := r.decode()
+		if err != nil {
+			return nil, &FormatError{r.n, err.Error()}
+		}
+		r.n++
+		return rec, nil
+	}
+}
+
+func (r *XMLReader) decode() (*Record, error) {
+	rec := &Record{}
+	for {
+		tok, err := r.d.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.EndElement:
+			return rec, nil
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "leader":
+				if err := r.d.DecodeElement(&rec.Leader, &t); err != nil {
+					return nil, err
+				}
+			case "controlfield":
+				var cf xmlControlField
+				if err := r.d.DecodeElement(&cf, &t); err != nil {
+					return nil, err
+				}
+				rec.Fields = append(rec.Fields, Field{Tag: cf.Tag, Value: cf.Value})
+			case "datafield":
+				var df xmlDataField
+				if err := r.d.DecodeElement(&df, &t); err != nil {
+					return nil, err
+				}
+				f := Field{Tag: df.Tag, Ind1: indicator(df.Ind1), Ind2: indicator(df.Ind2)}
+				for _, sf := range df.Subfields {
+					if len(sf.Code) != 1 {
+						return nil, fmt.Errorf("field %s: invalid subfield code %q", df.Tag, sf.Code)
+					}
+					f.Subfields = append(f.Subfields, Subfield{Code: sf.Code[0], Value: sf.Value})
+				}
+				rec.Fields = append(rec.Fields, f)
+			default:
+				if err := r.d.Skip(); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+}
+
+func indicator(s string) byte {
+	if len(s) != 1 {
+		return ' '
+	}
+	return s[0]
+}
+
+// An XMLWriter writes records as a MARCXML collection.
+type XMLWriter struct {
+	e       *xml.Encoder
+	started bool
+	closed  bool
+	err     error
+}
+
+// NewXMLWriter returns an XMLWriter writing to w.
+func NewXMLWriter(w io.Writer) *XMLWriter {
+	e := xml.NewEncoder(w)
+	e.Indent("", "  ")
+	return &XMLWriter{e: e}
+}
+
+var collection = xml.StartElement{
+	Name: xml.Name{Local: "collection"},
+	Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: Namespace}},
+}
+
+// Write writes rec to the collection.
+func (w *XMLWriter) Write(rec *Record) error {
+	if w.closed {
+		return fmt.Errorf("marc: write to closed XMLWriter")
+	}
+	if w.err != nil {
+		return w.err
+	}
+	if err := w.start(); err != nil {
+		w.err = err
+		return err
+	}
+
+	x := xmlRecord{Leader: rec.Leader}
+	for _, f := range rec.Fields {
+		if f.IsControl() {
+			x.ControlFields = append(x.ControlFields, xmlControlField{Tag: f.Tag, Value: f.Value})
+			continue
+		}
+		df := xmlDataField{Tag: f.Tag, Ind1: string(ind(f.Ind1)), Ind2: string(ind(f.Ind2))}
+		for _, sf := range f.Subfields {
+			df.Subfields = append(df.Subfields, xmlSubfield{Code: string(sf.Code), Value: sf.Value})
+		}
+		x.DataFields = append(x.DataFields, df)
+	}
+	if err := w.e.Encode(x); err != nil {
+		w.err = err
+		return err
+	}
+	return nil
+}
+
+// Close ends the collection and flushes it. It does not close the
+// underlying writer.
+func (w *XMLWriter) Close() error {
+	if w.closed || w.err != nil {
+		return w.err
+	}
+	if err := w.start(); err != nil {
+		return err
+	}
+	if err := w.e.EncodeToken(collection.End()); err != nil {
+		return err
+	}
+	w.closed = true
+	w.err = w.e.Flush()
+	return w.err
+}
+
+func (w *XMLWriter) start() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+	if err := w.e.EncodeToken(xml.ProcInst{Target: "xml", Inst: []byte(`version="1.0" encoding="UTF-8"`)}); err != nil {
+		return err
+	}
+	return w.e.EncodeToken(collection)
+}
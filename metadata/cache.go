@@ -0,0 +1,166 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/adoublef-go/isbn"
+)
+
+// Default time to live of the entries stored by Cached.
+const (
+	DefaultTTL         = 30 * 24 * time.Hour
+	DefaultNegativeTTL = 24 * time.Hour
+)
+
+// Entry is the result of resolving an ISBN, as held by a Cache.
+type Entry struct {
+	Book Book
+	// NotFound records that the ISBN has no book, rather than Book.
+	NotFound bool
+	// Expires is when the entry should no longer be used.
+	Expires time.Time
+}
+
+// Cache stores the results of resolving ISBNs. Expired entries may be
+// returned by Get, it is up to the caller to check Expires.
+type Cache interface {
+	// Get returns the entry for isbn, and false if there is none.
+	Get(ctx context.Context, isbn isbn.ISBN) (Entry, bool, error)
+	// Put stores e as the entry for isbn, replacing any already stored.
+	Put(ctx context.Context, isbn isbn.ISBN, e Entry) error
+}
+
+// Cached is a Resolver that caches the books found by Resolver, and the
+// ISBNs it has no record of, in Cache. Concurrent lookups of the same ISBN
+// make a single call to Resolver. Errors other than ErrNotFound, such as a
+// *RateLimitError, are not cached.
+//
+// A nil Cache only collapses concurrent lookups. A Cached must not be copied
+// after first use.
+type Cached struct {
+	Resolver Resolver
+	Cache    Cache
+
+	// TTL is how long a book is cached, DefaultTTL if zero.
+	TTL time.Duration
+	// NegativeTTL is how long an ISBN with no book is cached,
+	// DefaultNegativeTTL if zero.
+	NegativeTTL time.Duration
+
+	// Errors, if set, is called with the errors of Cache, which are
+	// otherwise ignored as the lookup can still be made.
+	Errors func(error)
+
+	flight flight
+	now    func() time.Time
+}
+
+// Resolve implements Resolver.
+func (c *Cached) Resolve(ctx context.Context, v isbn.ISBN) (Book, error) {
+	if c.Cache != nil {
+		e, ok, err := c.Cache.Get(ctx, v)
+		if err != nil {
+			c.error(err)
+		}
+		if ok && c.clock().Before(e.Expires) {
+			if e.NotFound {
+				return Book{}, ErrNotFound
+			}
+			return e.Book, nil
+		}
+	}
+
+	return c.flight.do(ctx, v, func(ctx context.Context) (Book, error) {
+		book, err := c.Resolver.Resolve(ctx, v)
+		if c.Cache == nil {
+			return book, err
+		}
+
+		switch {
+		case err == nil:
+			c.put(ctx, v, Entry{Book: book, Expires: c.clock().Add(ttl(c.TTL, DefaultTTL))})
+		case errors.Is(err, ErrNotFound):
+			c.put(ctx, v, Entry{NotFound: true, Expires: c.clock().Add(ttl(c.NegativeTTL, DefaultNegativeTTL))})
+		}
+		return book, err
+	})
+}
+
+func (c *Cached) put(ctx context.Context, v isbn.ISBN, e Entry) {
+	if err := c.Cache.Put(ctx, v, e); err != nil {
+		c.error(err)
+	}
+}
+
+func (c *Cached) error(err error) {
+	if c.Errors != nil {
+		c.Errors(err)
+	}
+}
+
+func (c *Cached) clock() time.Time {
+	if c.now == nil {
+		return time.Now()
+	}
+	return c.now()
+}
+
+func ttl(d, def time.Duration) time.Duration {
+	if d == 0 {
+		return def
+	}
+	return d
+}
+
+// MemoryCache is a Cache held in memory. It is safe for concurrent use.
+type MemoryCache struct {
+	mu      sync.RWMutex
+	entries map[isbn.ISBN]Entry
+	// sweep is the number of entries at which expired entries are removed.
+	sweep int
+}
+
+// NewMemoryCache returns an empty MemoryCache.
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: make(map[isbn.ISBN]Entry), sweep: 1024}
+}
+
+// Get implements Cache.
+func (m *MemoryCache) Get(_ context.Context, v isbn.ISBN) (Entry, bool, error) {
+	m.mu.RLock()
+	e, ok := m.entries[v]
+	m.mu.RUnlock()
+	return e, ok, nil
+}
+
+// Put implements Cache.
+func (m *MemoryCache) Put(_ context.Context, v isbn.ISBN, e Entry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries[v] = e
+	if len(m.entries) >= m.sweep {
+		now := time.Now()
+		for k, e := range m.entries {
+			if !now.Before(e.Expires) {
+				delete(m.entries, k)
+			}
+		}
+		// sweep again once the live entries have doubled
+		m.sweep = 2 * len(m.entries)
+		if m.sweep < 1024 {
+			m.sweep = 1024
+		}
+	}
+	return nil
+}
+
+// Len returns the number of entries in m, including those expired.
+func (m *MemoryCache) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.entries)
+}
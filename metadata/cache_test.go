@@ -0,0 +1,261 @@
+package metadata
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/adoublef-go/isbn"
+	"github.com/hyphengolang/prelude/testing/is"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var (
+	foxISBN, _     = isbn.Parse("9780140328721")
+	missingISBN, _ = isbn.Parse("9781861972712")
+)
+
+// books returns a Resolver that returns the books in m, and ErrNotFound for
+// any other ISBN, counting the calls made to it.
+func books(calls *int32, m map[isbn.ISBN]Book) Resolver {
+	return ResolverFunc(func(ctx context.Context, v isbn.ISBN) (Book, error) {
+		atomic.AddInt32(calls, 1)
+		b, ok := m[v]
+		if !ok {
+			return Book{}, ErrNotFound
+		}
+		b.ISBN = v
+		return b, nil
+	})
+}
+
+func TestFallback(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	ctx := context.Background()
+	limited := ResolverFunc(func(ctx context.Context, v isbn.ISBN) (Book, error) {
+		return Book{}, &RateLimitError{Source: "limited"}
+	})
+
+	t.Run("merges partial results", func(t *testing.T) {
+		var a, b, c int32
+		r := Fallback(
+			books(&a, map[isbn.ISBN]Book{foxISBN: {Title: "Fantastic Mr. Fox", Pages: 96, Source: "a"}}),
+			books(&b, map[isbn.ISBN]Book{foxISBN: {Title: "Fantastic Mr Fox", Authors: []string{"Roald Dahl"}, Publisher: "Puffin", Published: "1988", Source: "b"}}),
+			books(&c, map[isbn.ISBN]Book{foxISBN: {Language: "en", Source: "c"}}),
+		)
+
+		book, err := r.Resolve(ctx, foxISBN)
+		is.NoErr(err) // resolving isbn
+
+		is.Equal(book.Title, "Fantastic Mr. Fox") // earlier backend wins
+		is.Equal(book.Pages, 96)                  // field from first backend
+		is.Equal(book.Publisher, "Puffin")        // field from second backend
+		is.Equal(book.Source, "a,b")              // contributing backends
+		is.Equal(atomic.LoadInt32(&c), int32(0))  // stops once complete
+	})
+
+	t.Run("skips failing backends", func(t *testing.T) {
+		var a int32
+		r := Fallback(limited, books(&a, map[isbn.ISBN]Book{foxISBN: {Title: "Fantastic Mr. Fox", Source: "a"}}))
+
+		book, err := r.Resolve(ctx, foxISBN)
+		is.NoErr(err)              // resolving isbn
+		is.Equal(book.Source, "a") // found by second backend
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		var a, b int32
+		r := Fallback(books(&a, nil), books(&b, nil))
+
+		_, err := r.Resolve(ctx, missingISBN)
+		is.True(errors.Is(err, ErrNotFound)) // no backend has the isbn
+	})
+
+	t.Run("rate limited", func(t *testing.T) {
+		var a int32
+		r := Fallback(books(&a, nil), limited)
+
+		_, err := r.Resolve(ctx, missingISBN)
+		is.True(errors.Is(err, ErrRateLimited)) // may yet exist
+	})
+}
+
+func TestCached(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	is.NoErr(err) // opening database
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	sqlc := NewSQLCache(db)
+	is.NoErr(sqlc.Migrate(context.Background())) // creating cache table
+	is.NoErr(sqlc.Migrate(context.Background())) // migrating twice
+
+	tt := []struct {
+		desc  string
+		cache Cache
+	}{
+		{"memory", NewMemoryCache()},
+		{"sql", sqlc},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			ctx := context.Background()
+			now := time.Unix(1700000000, 0)
+
+			var calls int32
+			c := &Cached{
+				Resolver:    books(&calls, map[isbn.ISBN]Book{foxISBN: {Title: "Fantastic Mr. Fox", Authors: []string{"Roald Dahl"}, Source: "a"}}),
+				Cache:       tc.cache,
+				TTL:         time.Hour,
+				NegativeTTL: time.Minute,
+				now:         func() time.Time { return now },
+			}
+
+			for i := 0; i < 3; i++ {
+				book, err := c.Resolve(ctx, foxISBN)
+				is.NoErr(err)                                // resolving isbn
+				is.Equal(book.Title, "Fantastic Mr. Fox")    // cached title
+				is.Equal(book.Authors[0], "Roald Dahl")      // cached authors
+				is.Equal(book.ISBN, foxISBN)                 // cached isbn
+				is.Equal(atomic.LoadInt32(&calls), int32(1)) // resolved once
+			}
+
+			for i := 0; i < 3; i++ {
+				_, err := c.Resolve(ctx, missingISBN)
+				is.True(errors.Is(err, ErrNotFound))         // negative result
+				is.Equal(atomic.LoadInt32(&calls), int32(2)) // negative result cached
+			}
+
+			now = now.Add(2 * time.Minute)
+			_, err := c.Resolve(ctx, missingISBN)
+			is.True(errors.Is(err, ErrNotFound))         // negative result
+			is.Equal(atomic.LoadInt32(&calls), int32(3)) // negative result expired
+
+			_, err = c.Resolve(ctx, foxISBN)
+			is.NoErr(err)                                // resolving isbn
+			is.Equal(atomic.LoadInt32(&calls), int32(3)) // positive result still cached
+
+			now = now.Add(time.Hour)
+			_, err = c.Resolve(ctx, foxISBN)
+			is.NoErr(err)                                // resolving isbn
+			is.Equal(atomic.LoadInt32(&calls), int32(4)) // positive result expired
+		})
+	}
+
+	t.Run("errors not cached", func(t *testing.T) {
+		var calls int32
+		c := &Cached{
+			Resolver: ResolverFunc(func(ctx context.Context, v isbn.ISBN) (Book, error) {
+				atomic.AddInt32(&calls, 1)
+				return Book{}, &RateLimitError{Source: "limited"}
+			}),
+			Cache: NewMemoryCache(),
+		}
+
+		for i := 0; i < 2; i++ {
+			_, err := c.Resolve(context.Background(), foxISBN)
+			is.True(errors.Is(err, ErrRateLimited)) // rate limited
+		}
+		is.Equal(atomic.LoadInt32(&calls), int32(2)) // not cached
+	})
+}
+
+func TestCachedDedup(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	const n = 50
+
+	var calls int32
+	release := make(chan struct{})
+	c := &Cached{
+		Resolver: ResolverFunc(func(ctx context.Context, v isbn.ISBN) (Book, error) {
+			atomic.AddInt32(&calls, 1)
+			<-release
+			return Book{ISBN: v, Title: "Fantastic Mr. Fox"}, nil
+		}),
+	}
+
+	var (
+		wg     sync.WaitGroup
+		failed int32
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if book, err := c.Resolve(context.Background(), foxISBN); err != nil || book.Title != "Fantastic Mr. Fox" {
+				atomic.AddInt32(&failed, 1)
+			}
+		}()
+	}
+
+	waitFor(t, func() bool { return waiters(&c.flight, foxISBN) == n })
+
+	// an abandoned lookup does not cancel the call for the others
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := c.Resolve(ctx, foxISBN)
+	is.True(errors.Is(err, context.Canceled)) // cancelled lookup
+
+	close(release)
+	wg.Wait()
+
+	is.Equal(atomic.LoadInt32(&failed), int32(0)) // all lookups resolved
+	is.Equal(atomic.LoadInt32(&calls), int32(1))  // single upstream call
+}
+
+func TestCachedCancel(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	cancelled := make(chan struct{})
+	c := &Cached{
+		Resolver: ResolverFunc(func(ctx context.Context, v isbn.ISBN) (Book, error) {
+			<-ctx.Done()
+			close(cancelled)
+			return Book{}, ctx.Err()
+		}),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := c.Resolve(ctx, foxISBN)
+	is.True(errors.Is(err, context.DeadlineExceeded)) // lookup timed out
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("upstream call not cancelled")
+	}
+}
+
+func waiters(f *flight, v isbn.ISBN) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if c, ok := f.calls[v]; ok {
+		return c.waiters
+	}
+	return 0
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); !cond(); {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,95 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/adoublef-go/isbn"
+)
+
+// Fallback returns a Resolver that tries rs in order. Backends often know
+// only part of a book, so the fields missing from a result are filled from
+// the backends that follow, until the book has a title, authors, a publisher
+// and a publication date. The Source of the result lists, comma separated,
+// the backends that contributed to it.
+//
+// Fallback returns ErrNotFound if no backend has a record of the ISBN. If
+// none found it and any backend failed otherwise, such as with a
+// *RateLimitError, the first such error is returned instead, as the book may
+// yet exist.
+func Fallback(rs ...Resolver) Resolver {
+	return fallback(rs)
+}
+
+type fallback []Resolver
+
+func (rs fallback) Resolve(ctx context.Context, v isbn.ISBN) (Book, error) {
+	var (
+		book    Book
+		sources []string
+		failed  error
+	)
+	for _, r := range rs {
+		b, err := r.Resolve(ctx, v)
+		if err != nil {
+			if ctx.Err() != nil {
+				return Book{}, ctx.Err()
+			}
+			if failed == nil && !errors.Is(err, ErrNotFound) {
+				failed = err
+			}
+			continue
+		}
+
+		if merge(&book, b) {
+			sources = append(sources, b.Source)
+		}
+		if complete(book) {
+			break
+		}
+	}
+
+	if len(sources) == 0 {
+		if failed != nil {
+			return Book{}, failed
+		}
+		return Book{}, ErrNotFound
+	}
+	book.ISBN = v
+	book.Source = strings.Join(sources, ",")
+	return book, nil
+}
+
+// complete reports whether b has the fields worth asking another backend for.
+func complete(b Book) bool {
+	return b.Title != "" && len(b.Authors) > 0 && b.Publisher != "" && b.Published != ""
+}
+
+// merge sets the zero fields of dst from src, and reports whether any were.
+func merge(dst *Book, src Book) (changed bool) {
+	str := func(d *string, s string) {
+		if *d == "" && s != "" {
+			*d, changed = s, true
+		}
+	}
+	strs := func(d *[]string, s []string) {
+		if len(*d) == 0 && len(s) > 0 {
+			*d, changed = s, true
+		}
+	}
+
+	str(&dst.Title, src.Title)
+	str(&dst.Subtitle, src.Subtitle)
+	strs(&dst.Authors, src.Authors)
+	str(&dst.Publisher, src.Publisher)
+	str(&dst.Published, src.Published)
+	if dst.Pages == 0 && src.Pages != 0 {
+		dst.Pages, changed = src.Pages, true
+	}
+	str(&dst.Language, src.Language)
+	strs(&dst.Subjects, src.Subjects)
+	str(&dst.Description, src.Description)
+	str(&dst.CoverURL, src.CoverURL)
+	return changed
+}
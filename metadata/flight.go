@@ -0,0 +1,74 @@
+package metadata
+
+import (
+	"context"
+	"sync"
+
+	"github.com/adoublef-go/isbn"
+)
+
+// flight collapses concurrent lookups of the same ISBN into a single call.
+// The zero value is ready to use.
+type flight struct {
+	mu    sync.Mutex
+	calls map[isbn.ISBN]*call
+}
+
+type call struct {
+	done chan struct{}
+	book Book
+	err  error
+
+	// waiters is the number of callers still waiting on the call, which is
+	// cancelled once they have all given up.
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// do calls fn for v, unless a call for v is already in flight, in which case
+// it waits for that call's result. fn is not given ctx, as it is shared by
+// callers with their own deadlines, but a context cancelled once every caller
+// waiting on it has returned.
+func (f *flight) do(ctx context.Context, v isbn.ISBN, fn func(context.Context) (Book, error)) (Book, error) {
+	f.mu.Lock()
+	if f.calls == nil {
+		f.calls = make(map[isbn.ISBN]*call)
+	}
+	c, ok := f.calls[v]
+	if !ok {
+		cctx, cancel := context.WithCancel(context.Background())
+		c = &call{done: make(chan struct{}), cancel: cancel}
+		f.calls[v] = c
+
+		go func() {
+			c.book, c.err = fn(cctx)
+
+			f.mu.Lock()
+			if f.calls[v] == c {
+				delete(f.calls, v)
+			}
+			f.mu.Unlock()
+
+			cancel()
+			close(c.done)
+		}()
+	}
+	c.waiters++
+	f.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return c.book, c.err
+	case <-ctx.Done():
+		f.mu.Lock()
+		if c.waiters--; c.waiters == 0 {
+			c.cancel()
+			// later callers start afresh rather than join a cancelled call
+			if f.calls[v] == c {
+				delete(f.calls, v)
+			}
+		}
+		f.mu.Unlock()
+		return Book{}, ctx.Err()
+	}
+}
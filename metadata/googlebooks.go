@@ -0,0 +1,140 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/adoublef-go/isbn"
+)
+
+// GoogleBooks resolves books using the Google Books volumes API. The zero
+// value uses http.DefaultClient and no API key, which Google rate limits
+// heavily.
+type GoogleBooks struct {
+	Client *http.Client
+	// Key is the API key sent with each request, if set.
+	Key string
+	// BaseURL overrides https://www.googleapis.com.
+	BaseURL string
+}
+
+const googleBooksURL = "https://www.googleapis.com"
+
+type googleBooksVolumes struct {
+	TotalItems int `json:"totalItems"`
+	Items      []struct {
+		VolumeInfo struct {
+			Title               string   `json:"title"`
+			Subtitle            string   `json:"subtitle"`
+			Authors             []string `json:"authors"`
+			Publisher           string   `json:"publisher"`
+			PublishedDate       string   `json:"publishedDate"`
+			Description         string   `json:"description"`
+			PageCount           int      `json:"pageCount"`
+			Categories          []string `json:"categories"`
+			Language            string   `json:"language"`
+			IndustryIdentifiers []struct {
+				Type       string `json:"type"`
+				Identifier string `json:"identifier"`
+			} `json:"industryIdentifiers"`
+			ImageLinks struct {
+				Thumbnail string `json:"thumbnail"`
+			} `json:"imageLinks"`
+		} `json:"volumeInfo"`
+	} `json:"items"`
+}
+
+type googleBooksError struct {
+	Error struct {
+		Errors []struct {
+			Reason string `json:"reason"`
+		} `json:"errors"`
+	} `json:"error"`
+}
+
+// Resolve implements Resolver.
+func (gb *GoogleBooks) Resolve(ctx context.Context, v isbn.ISBN) (Book, error) {
+	base := gb.BaseURL
+	if base == "" {
+		base = googleBooksURL
+	}
+
+	q := url.Values{"q": {"isbn:" + v.String()}}
+	if gb.Key != "" {
+		q.Set("key", gb.Key)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+"/books/v1/volumes?"+q.Encode(), nil)
+	if err != nil {
+		return Book{}, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client(gb.Client).Do(req)
+	if err != nil {
+		return Book{}, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return Book{}, ErrNotFound
+	case http.StatusTooManyRequests:
+		return Book{}, &RateLimitError{Source: "googlebooks", RetryAfter: retryAfter(resp)}
+	case http.StatusForbidden:
+		// quota errors are reported as 403 with a reason
+		var e googleBooksError
+		if json.NewDecoder(resp.Body).Decode(&e) == nil {
+			for _, r := range e.Error.Errors {
+				if strings.HasSuffix(r.Reason, "LimitExceeded") {
+					return Book{}, &RateLimitError{Source: "googlebooks", RetryAfter: retryAfter(resp)}
+				}
+			}
+		}
+		return Book{}, fmt.Errorf("googlebooks: unexpected status %s", resp.Status)
+	default:
+		return Book{}, fmt.Errorf("googlebooks: unexpected status %s", resp.Status)
+	}
+
+	var vols googleBooksVolumes
+	if err := json.NewDecoder(resp.Body).Decode(&vols); err != nil {
+		return Book{}, fmt.Errorf("googlebooks: %w", err)
+	}
+
+	// the search may match other editions, so pick the volume with the ISBN
+	for _, item := range vols.Items {
+		info := item.VolumeInfo
+
+		var match bool
+		for _, id := range info.IndustryIdentifiers {
+			if u, err := isbn.Parse(id.Identifier); err == nil && u == v {
+				match = true
+				break
+			}
+		}
+		if !match {
+			continue
+		}
+
+		return Book{
+			ISBN:        v,
+			Title:       info.Title,
+			Subtitle:    info.Subtitle,
+			Authors:     info.Authors,
+			Publisher:   info.Publisher,
+			Published:   info.PublishedDate,
+			Pages:       info.PageCount,
+			Language:    info.Language,
+			Subjects:    info.Categories,
+			Description: info.Description,
+			CoverURL:    info.ImageLinks.Thumbnail,
+			Source:      "googlebooks",
+		}, nil
+	}
+	return Book{}, ErrNotFound
+}
@@ -0,0 +1,115 @@
+// Package metadata looks up the bibliographic metadata of an ISBN, such as
+// its title, authors and publisher, from pluggable backends.
+//
+//	var r metadata.Resolver = &metadata.OpenLibrary{}
+//	book, err := r.Resolve(ctx, isbn)
+//	if errors.Is(err, metadata.ErrNotFound) {
+//		...
+//	}
+//
+// Backends are combined with Fallback, and wrapped with Cached to cache their
+// results and collapse concurrent lookups of the same ISBN:
+//
+//	r := &metadata.Cached{
+//		Resolver: metadata.Fallback(&metadata.OpenLibrary{}, &metadata.GoogleBooks{Key: key}),
+//		Cache:    metadata.NewSQLCache(db),
+//	}
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/adoublef-go/isbn"
+)
+
+var (
+	// ErrNotFound is returned by a Resolver that has no record of an ISBN.
+	ErrNotFound = errors.New("book not found")
+	// ErrRateLimited matches, using errors.Is, a *RateLimitError.
+	ErrRateLimited = errors.New("rate limited")
+)
+
+// RateLimitError is returned by a Resolver when its backend refuses a request
+// because too many were made.
+type RateLimitError struct {
+	Source string
+	// RetryAfter is how long the backend asked to wait before retrying, zero
+	// if it did not say.
+	RetryAfter time.Duration
+}
+
+func (err *RateLimitError) Error() string {
+	if err.RetryAfter > 0 {
+		return fmt.Sprintf("%s: rate limited, retry after %v", err.Source, err.RetryAfter)
+	}
+	return fmt.Sprintf("%s: rate limited", err.Source)
+}
+
+func (err *RateLimitError) Is(target error) bool { return target == ErrRateLimited }
+
+// Book is the metadata of a book, normalized across backends. Fields a
+// backend does not provide are left as their zero value.
+type Book struct {
+	ISBN     isbn.ISBN
+	Title    string
+	Subtitle string
+	Authors  []string
+	// Publisher is the name of the first publisher listed.
+	Publisher string
+	// Published is the date of publication as given by the backend, which
+	// may be a year ("1988"), a date ("1988-10-01") or free text.
+	Published   string
+	Pages       int
+	Language    string
+	Subjects    []string
+	Description string
+	CoverURL    string
+
+	// Source names the backend the metadata was resolved from.
+	Source string
+}
+
+// Resolver looks up the metadata of a book by its ISBN. Resolve returns
+// ErrNotFound if there is no record of the ISBN, and a *RateLimitError if the
+// backend is refusing requests.
+type Resolver interface {
+	Resolve(ctx context.Context, isbn isbn.ISBN) (Book, error)
+}
+
+// ResolverFunc is an adapter to use an ordinary function as a Resolver.
+type ResolverFunc func(ctx context.Context, isbn isbn.ISBN) (Book, error)
+
+// Resolve calls f(ctx, isbn).
+func (f ResolverFunc) Resolve(ctx context.Context, isbn isbn.ISBN) (Book, error) {
+	return f(ctx, isbn)
+}
+
+// retryAfter parses the Retry-After header of resp, given in seconds or as an
+// HTTP date.
+func retryAfter(resp *http.Response) time.Duration {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+	if n, err := strconv.Atoi(v); err == nil && n > 0 {
+		return time.Duration(n) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+func client(c *http.Client) *http.Client {
+	if c == nil {
+		return http.DefaultClient
+	}
+	return c
+}
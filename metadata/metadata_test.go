@@ -0,0 +1,162 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adoublef-go/isbn"
+	"github.com/hyphengolang/prelude/testing/is"
+)
+
+// fixtures serves the recorded responses in testdata/dir, named by the ISBN
+// in the query parameter param, and empty for an ISBN with no recording.
+func fixtures(t *testing.T, dir, param, empty string) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		v := r.URL.Query().Get(param)
+		v = v[strings.LastIndex(v, ":")+1:]
+
+		b, err := os.ReadFile(filepath.Join("testdata", dir, v+".json"))
+		if err != nil {
+			b = []byte(empty)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(b)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func status(t *testing.T, code int, header http.Header, body string) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for k, v := range header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(code)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestOpenLibrary(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	srv := fixtures(t, "openlibrary", "bibkeys", "{}")
+	r := &OpenLibrary{Client: srv.Client(), BaseURL: srv.URL}
+	ctx := context.Background()
+
+	t.Run("found", func(t *testing.T) {
+		v, _ := isbn.Parse("0140328726")
+		book, err := r.Resolve(ctx, v)
+		is.NoErr(err) // resolving isbn
+
+		is.Equal(book.ISBN, v)                                                       // requested isbn
+		is.Equal(book.Title, "Fantastic Mr. Fox")                                    // title
+		is.Equal(strings.Join(book.Authors, ";"), "Roald Dahl")                      // authors
+		is.Equal(book.Publisher, "Puffin")                                           // publisher
+		is.Equal(book.Published, "October 1, 1988")                                  // publish date
+		is.Equal(book.Pages, 96)                                                     // pages
+		is.Equal(strings.Join(book.Subjects, ";"), "Animals;Foxes")                  // subjects
+		is.Equal(book.Description, "Puffin books.")                                  // notes as string
+		is.Equal(book.CoverURL, "https://covers.openlibrary.org/b/id/8739161-L.jpg") // large cover
+		is.Equal(book.Source, "openlibrary")                                         // source
+	})
+
+	t.Run("partial", func(t *testing.T) {
+		v, _ := isbn.Parse("9780716703440")
+		book, err := r.Resolve(ctx, v)
+		is.NoErr(err) // resolving isbn
+
+		is.Equal(book.Subtitle, "a modern introduction") // subtitle
+		is.Equal(len(book.Authors), 0)                   // no authors
+		is.Equal(book.Description, "Includes index.")    // notes as text block
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		v, _ := isbn.Parse("9781861972712")
+		_, err := r.Resolve(ctx, v)
+		is.True(errors.Is(err, ErrNotFound)) // empty response
+	})
+
+	t.Run("rate limited", func(t *testing.T) {
+		srv := status(t, http.StatusTooManyRequests, http.Header{"Retry-After": {"30"}}, "")
+		r := &OpenLibrary{Client: srv.Client(), BaseURL: srv.URL}
+
+		v, _ := isbn.Parse("9781861972712")
+		_, err := r.Resolve(ctx, v)
+		is.True(errors.Is(err, ErrRateLimited)) // 429 response
+
+		var rerr *RateLimitError
+		is.True(errors.As(err, &rerr))            // rate limit error
+		is.Equal(rerr.RetryAfter, 30*time.Second) // retry after header
+	})
+
+	t.Run("server error", func(t *testing.T) {
+		srv := status(t, http.StatusBadGateway, nil, "")
+		r := &OpenLibrary{Client: srv.Client(), BaseURL: srv.URL}
+
+		v, _ := isbn.Parse("9781861972712")
+		_, err := r.Resolve(ctx, v)
+		is.True(err != nil)                   // 502 response
+		is.True(!errors.Is(err, ErrNotFound)) // not a missing book
+	})
+}
+
+func TestGoogleBooks(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	srv := fixtures(t, "googlebooks", "q", `{"kind": "books#volumes", "totalItems": 0}`)
+	r := &GoogleBooks{Client: srv.Client(), BaseURL: srv.URL}
+	ctx := context.Background()
+
+	t.Run("found", func(t *testing.T) {
+		v, _ := isbn.Parse("9780140328721")
+		book, err := r.Resolve(ctx, v)
+		is.NoErr(err) // resolving isbn
+
+		is.Equal(book.ISBN, v)                                         // requested isbn
+		is.Equal(book.Title, "Fantastic Mr. Fox")                      // title of matching volume
+		is.Equal(book.Publisher, "Penguin")                            // publisher
+		is.Equal(book.Published, "1988-10-01")                         // published date
+		is.Equal(book.Pages, 96)                                       // pages
+		is.Equal(book.Language, "en")                                  // language
+		is.Equal(strings.Join(book.Subjects, ";"), "Juvenile Fiction") // categories
+		is.True(book.Description != "")                                // description
+		is.Equal(book.Source, "googlebooks")                           // source
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		v, _ := isbn.Parse("9781861972712")
+		_, err := r.Resolve(ctx, v)
+		is.True(errors.Is(err, ErrNotFound)) // no items
+	})
+
+	t.Run("quota exceeded", func(t *testing.T) {
+		b, _ := os.ReadFile("testdata/googlebooks/ratelimit.json")
+		srv := status(t, http.StatusForbidden, nil, string(b))
+		r := &GoogleBooks{Client: srv.Client(), BaseURL: srv.URL}
+
+		v, _ := isbn.Parse("9780140328721")
+		_, err := r.Resolve(ctx, v)
+		is.True(errors.Is(err, ErrRateLimited)) // 403 with rate limit reason
+	})
+
+	t.Run("forbidden", func(t *testing.T) {
+		srv := status(t, http.StatusForbidden, nil, `{"error": {"code": 403, "errors": [{"reason": "forbidden"}]}}`)
+		r := &GoogleBooks{Client: srv.Client(), BaseURL: srv.URL}
+
+		v, _ := isbn.Parse("9780140328721")
+		_, err := r.Resolve(ctx, v)
+		is.True(err != nil)                      // 403 response
+		is.True(!errors.Is(err, ErrRateLimited)) // not a rate limit
+	})
+}
@@ -0,0 +1,338 @@
+package olindex
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/adoublef-go/isbn"
+)
+
+// Progress reports how far an ingest has got.
+type Progress struct {
+	// Source identifies the dump being ingested.
+	Source string
+	// Lines is the number of lines read, including those skipped when
+	// resuming, and Resumed the number skipped.
+	Lines, Resumed int64
+	// Editions, Authors and Skipped count the lines ingested as editions,
+	// as authors, and those ignored as malformed or of another type.
+	Editions, Authors, Skipped int64
+	// Read is the number of bytes read from the dump file, which is
+	// compressed for a .gz dump, and Size the size of the file, or -1 if
+	// unknown.
+	Read, Size int64
+	// Done is set on the last report of a completed ingest.
+	Done bool
+}
+
+// Ingest adds the records of the Open Library dump file name to the index.
+// Editions and authors are ingested, other types of record are skipped. A
+// dump compressed with gzip is decompressed as it is read.
+//
+// Ingest records its progress in the index as it goes. An ingest that was
+// interrupted resumes from its last committed batch when called again with
+// the same file, and a completed ingest is not repeated. If progress is not
+// nil it is called after each batch.
+func (ix *Index) Ingest(ctx context.Context, name string, progress func(Progress)) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	// a dump is identified by its name and size, so that a newer dump of the
+	// same name is ingested afresh
+	source := fmt.Sprintf("%s:%d", filepath.Base(name), fi.Size())
+	return ix.ingest(ctx, source, f, fi.Size(), progress)
+}
+
+// IngestReader is Ingest for a dump read from r, which is resumed if an
+// ingest with the same source was interrupted.
+func (ix *Index) IngestReader(ctx context.Context, source string, r io.Reader, progress func(Progress)) error {
+	return ix.ingest(ctx, source, r, -1, progress)
+}
+
+func (ix *Index) ingest(ctx context.Context, source string, r io.Reader, size int64, progress func(Progress)) error {
+	var (
+		resume int64
+		done   bool
+	)
+	err := ix.db.QueryRowContext(ctx, `SELECT lines, done FROM ingests WHERE source = ?`, source).Scan(&resume, &done)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if done {
+		return nil
+	}
+
+	cr := &countingReader{r: r}
+	br := bufio.NewReaderSize(cr, 1<<16)
+	var dump io.Reader = br
+	if magic, _ := br.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return fmt.Errorf("olindex: %w", err)
+		}
+		defer zr.Close()
+		dump = zr
+	}
+	lr := bufio.NewReaderSize(dump, 1<<20)
+
+	batch := ix.BatchSize
+	if batch <= 0 {
+		batch = 10000
+	}
+
+	p := Progress{Source: source, Size: size}
+	report := func() {
+		p.Read = cr.n
+		if progress != nil {
+			progress(p)
+		}
+	}
+
+	for eof := false; !eof; {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		tx, err := ix.db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		w, err := newWriter(ctx, tx)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		for n := 0; n < batch; {
+			line, err := lr.ReadBytes('\n')
+			if errors.Is(err, io.EOF) {
+				eof = true
+				if len(line) == 0 {
+					break
+				}
+			} else if err != nil {
+				tx.Rollback()
+				return fmt.Errorf("olindex: %s line %d: %w", source, p.Lines+1, err)
+			}
+
+			p.Lines++
+			if p.Lines <= resume {
+				p.Resumed++
+				continue
+			}
+			n++
+
+			switch kind, err := w.record(line); {
+			case err != nil:
+				tx.Rollback()
+				return fmt.Errorf("olindex: %s line %d: %w", source, p.Lines, err)
+			case kind == edition:
+				p.Editions++
+			case kind == author:
+				p.Authors++
+			default:
+				p.Skipped++
+			}
+		}
+
+		// the position is committed with the records, so a resumed ingest
+		// neither misses nor repeats any
+		if _, err := tx.ExecContext(ctx, `
+INSERT INTO ingests (source, lines, done) VALUES (?, ?, ?)
+ON CONFLICT (source) DO UPDATE SET lines = excluded.lines, done = excluded.done`,
+			source, p.Lines, eof); err != nil {
+			tx.Rollback()
+			return err
+		}
+		w.close()
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+
+		p.Done = eof
+		report()
+	}
+	return nil
+}
+
+type kind int
+
+const (
+	other kind = iota
+	edition
+	author
+)
+
+// writer inserts the records of a dump within a transaction.
+type writer struct {
+	ctx                      context.Context
+	editions, isbns, authors *sql.Stmt
+}
+
+func newWriter(ctx context.Context, tx *sql.Tx) (*writer, error) {
+	w := &writer{ctx: ctx}
+	var err error
+	if w.editions, err = tx.PrepareContext(ctx, `
+INSERT OR REPLACE INTO editions (key, title, subtitle, authors, publisher, published, pages, language, subjects, description, cover)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`); err != nil {
+		return nil, err
+	}
+	if w.isbns, err = tx.PrepareContext(ctx, `INSERT OR REPLACE INTO isbns (isbn, edition) VALUES (?, ?)`); err != nil {
+		return nil, err
+	}
+	if w.authors, err = tx.PrepareContext(ctx, `INSERT OR REPLACE INTO authors (key, name) VALUES (?, ?)`); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *writer) close() {
+	w.editions.Close()
+	w.isbns.Close()
+	w.authors.Close()
+}
+
+// dumpRecord is the JSON of an edition or author in a dump.
+type dumpRecord struct {
+	Key      string   `json:"key"`
+	Name     string   `json:"name"`
+	Title    string   `json:"title"`
+	Subtitle string   `json:"subtitle"`
+	ISBN10   []string `json:"isbn_10"`
+	ISBN13   []string `json:"isbn_13"`
+	Authors  []struct {
+		Key string `json:"key"`
+	} `json:"authors"`
+	Publishers  []string `json:"publishers"`
+	PublishDate string   `json:"publish_date"`
+	Pages       int      `json:"number_of_pages"`
+	Languages   []struct {
+		Key string `json:"key"`
+	} `json:"languages"`
+	Subjects    []string        `json:"subjects"`
+	Description json.RawMessage `json:"description"`
+	Covers      []int64         `json:"covers"`
+}
+
+// record ingests a line of a dump, of the form
+//
+//	type \t key \t revision \t last modified \t JSON
+//
+// Malformed lines, which the dumps have a few of, are skipped rather than
+// failing the ingest.
+func (w *writer) record(line []byte) (kind, error) {
+	fields := bytes.SplitN(bytes.TrimRight(line, "\r\n"), []byte{'\t'}, 5)
+	if len(fields) != 5 {
+		return other, nil
+	}
+
+	switch string(fields[0]) {
+	case "/type/edition":
+		var rec dumpRecord
+		if json.Unmarshal(fields[4], &rec) != nil {
+			return other, nil
+		}
+		return w.edition(rec)
+	case "/type/author":
+		var rec dumpRecord
+		if json.Unmarshal(fields[4], &rec) != nil || rec.Name == "" {
+			return other, nil
+		}
+		if _, err := w.authors.ExecContext(w.ctx, rec.Key, rec.Name); err != nil {
+			return other, err
+		}
+		return author, nil
+	default:
+		return other, nil
+	}
+}
+
+func (w *writer) edition(rec dumpRecord) (kind, error) {
+	var isbns []string
+	for _, s := range append(rec.ISBN13, rec.ISBN10...) {
+		if v, err := isbn.Parse(strings.TrimSpace(s)); err == nil {
+			isbns = append(isbns, v.String())
+		}
+	}
+	// only editions with an ISBN can be looked up
+	if len(isbns) == 0 || rec.Title == "" {
+		return other, nil
+	}
+
+	authors := make([]string, 0, len(rec.Authors))
+	for _, a := range rec.Authors {
+		authors = append(authors, a.Key)
+	}
+	var publisher string
+	if len(rec.Publishers) > 0 {
+		publisher = rec.Publishers[0]
+	}
+	var language string
+	if len(rec.Languages) > 0 {
+		language = strings.TrimPrefix(rec.Languages[0].Key, "/languages/")
+	}
+	var cover int64
+	if len(rec.Covers) > 0 {
+		cover = rec.Covers[0]
+	}
+	subjects := rec.Subjects
+	if subjects == nil {
+		subjects = []string{}
+	}
+
+	a, _ := json.Marshal(authors)
+	s, _ := json.Marshal(subjects)
+	if _, err := w.editions.ExecContext(w.ctx, rec.Key, rec.Title, rec.Subtitle, string(a), publisher,
+		rec.PublishDate, rec.Pages, language, string(s), description(rec.Description), cover); err != nil {
+		return other, err
+	}
+	for _, v := range isbns {
+		if _, err := w.isbns.ExecContext(w.ctx, v, rec.Key); err != nil {
+			return other, err
+		}
+	}
+	return edition, nil
+}
+
+// description returns the text of a description, which is either a string
+// or a {"type": "/type/text", "value": ...} block.
+func description(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var text struct {
+		Value string `json:"value"`
+	}
+	json.Unmarshal(raw, &text)
+	return text.Value
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	return n, err
+}
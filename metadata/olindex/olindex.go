@@ -0,0 +1,162 @@
+// Package olindex builds a local SQLite index of books from the Open Library
+// data dumps, and resolves ISBNs from it without calling any external API.
+//
+//	ix, _ := olindex.Open("books.db")
+//	ix.Ingest(ctx, "ol_dump_editions_latest.txt.gz", nil)
+//	ix.Ingest(ctx, "ol_dump_authors_latest.txt.gz", nil)
+//	book, err := ix.Resolve(ctx, isbn)
+//
+// The editions dump is enough to resolve a book, the authors dump adds the
+// names of its authors.
+package olindex
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/adoublef-go/isbn"
+	"github.com/adoublef-go/isbn/metadata"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Source is the metadata.Book Source of the books resolved by an Index.
+const Source = "openlibrary-dump"
+
+const coverURL = "https://covers.openlibrary.org/b/id/%d-L.jpg"
+
+const schema = `
+CREATE TABLE IF NOT EXISTS editions (
+	key         TEXT PRIMARY KEY,
+	title       TEXT NOT NULL,
+	subtitle    TEXT NOT NULL,
+	authors     TEXT NOT NULL, -- JSON array of author keys
+	publisher   TEXT NOT NULL,
+	published   TEXT NOT NULL,
+	pages       INTEGER NOT NULL,
+	language    TEXT NOT NULL,
+	subjects    TEXT NOT NULL, -- JSON array
+	description TEXT NOT NULL,
+	cover       INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS isbns (
+	isbn    TEXT PRIMARY KEY,
+	edition TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS authors (
+	key  TEXT PRIMARY KEY,
+	name TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS ingests (
+	source TEXT PRIMARY KEY,
+	lines  INTEGER NOT NULL,
+	done   INTEGER NOT NULL
+);`
+
+// Index is a SQLite index of Open Library editions keyed by ISBN. It
+// implements metadata.Resolver.
+type Index struct {
+	db *sql.DB
+
+	// BatchSize is the number of dump lines committed at a time by Ingest,
+	// and so the most that are read again when an ingest is resumed.
+	// Defaults to 10000.
+	BatchSize int
+}
+
+var _ metadata.Resolver = (*Index)(nil)
+
+// Open opens, creating if need be, the index in the SQLite database name.
+func Open(name string) (*Index, error) {
+	db, err := sql.Open("sqlite3", name+"?_journal_mode=WAL&_synchronous=NORMAL")
+	if err != nil {
+		return nil, err
+	}
+	// a single writer, and no surprises with in-memory databases
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("olindex: %w", err)
+	}
+	return &Index{db: db}, nil
+}
+
+// Close closes the database of the index.
+func (ix *Index) Close() error { return ix.db.Close() }
+
+// Resolve implements metadata.Resolver.
+func (ix *Index) Resolve(ctx context.Context, v isbn.ISBN) (metadata.Book, error) {
+	var (
+		book             = metadata.Book{ISBN: v, Source: Source}
+		authors, subject string
+		cover            int64
+	)
+	err := ix.db.QueryRowContext(ctx, `
+SELECT e.title, e.subtitle, e.authors, e.publisher, e.published, e.pages, e.language, e.subjects, e.description, e.cover
+FROM isbns i JOIN editions e ON e.key = i.edition
+WHERE i.isbn = ?`, v.String()).Scan(
+		&book.Title, &book.Subtitle, &authors, &book.Publisher, &book.Published,
+		&book.Pages, &book.Language, &subject, &book.Description, &cover,
+	)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return metadata.Book{}, metadata.ErrNotFound
+	case err != nil:
+		return metadata.Book{}, err
+	}
+
+	if err := json.Unmarshal([]byte(subject), &book.Subjects); err != nil {
+		return metadata.Book{}, err
+	}
+	if cover > 0 {
+		book.CoverURL = fmt.Sprintf(coverURL, cover)
+	}
+
+	var keys []string
+	if err := json.Unmarshal([]byte(authors), &keys); err != nil {
+		return metadata.Book{}, err
+	}
+	if len(keys) == 0 {
+		return book, nil
+	}
+
+	// authors missing from the index, as the authors dump has not been
+	// ingested, are left out
+	rows, err := ix.db.QueryContext(ctx,
+		`SELECT key, name FROM authors WHERE key IN (?`+strings.Repeat(", ?", len(keys)-1)+`)`,
+		anys(keys)...)
+	if err != nil {
+		return metadata.Book{}, err
+	}
+	defer rows.Close()
+
+	names := make(map[string]string, len(keys))
+	for rows.Next() {
+		var key, name string
+		if err := rows.Scan(&key, &name); err != nil {
+			return metadata.Book{}, err
+		}
+		names[key] = name
+	}
+	if err := rows.Err(); err != nil {
+		return metadata.Book{}, err
+	}
+	for _, key := range keys {
+		if name, ok := names[key]; ok {
+			book.Authors = append(book.Authors, name)
+		}
+	}
+	return book, nil
+}
+
+func anys(ss []string) []any {
+	out := make([]any, len(ss))
+	for i, s := range ss {
+		out[i] = s
+	}
+	return out
+}
@@ -0,0 +1,119 @@
+package olindex
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adoublef-go/isbn"
+	"github.com/adoublef-go/isbn/metadata"
+	"github.com/hyphengolang/prelude/testing/is"
+)
+
+var dump = strings.Join([]string{
+	`/type/edition	/books/OL7353617M	9	2010-08-12T19:26:02.203931	{"key": "/books/OL7353617M", "title": "Fantastic Mr. Fox", "authors": [{"key": "/authors/OL34184A"}], "publishers": ["Puffin"], "publish_date": "October 1, 1988", "number_of_pages": 96, "isbn_10": ["0140328726"], "isbn_13": ["9780140328721"], "languages": [{"key": "/languages/eng"}], "subjects": ["Animals", "Foxes"], "covers": [8739161], "description": {"type": "/type/text", "value": "The Fantastic Mr. Fox outwits the farmers."}}`,
+	`/type/edition	/books/OL1M	2	2008-04-01T03:28:50.625462	{"key": "/books/OL1M", "title": "No ISBN", "publishers": ["Nobody"]}`,
+	`/type/edition	/books/OL4553159M	3	2009-12-11T01:57:19.964652	{"key": "/books/OL4553159M", "title": "Computer science", "subtitle": "a modern introduction", "isbn_10": ["0-7167-0344-0"], "description": "Includes index."}`,
+	`/type/work	/works/OL45883W	5	2010-04-28T06:54:19.472104	{"key": "/works/OL45883W", "title": "Fantastic Mr Fox"}`,
+	`/type/edition	/books/OL2M	1	2008-04-01T03:28:50.625462	{not json`,
+	`/type/author	/authors/OL34184A	12	2021-03-02T12:34:56.000000	{"key": "/authors/OL34184A", "name": "Roald Dahl"}`,
+	`/type/edition	/books/OL3M	1	2008-04-01T03:28:50.625462	{"key": "/books/OL3M", "title": "Bad ISBN", "isbn_13": ["9780140328722"]}`,
+}, "\n") + "\n"
+
+func writeDump(t *testing.T, name string, gz bool) string {
+	name = filepath.Join(t.TempDir(), name)
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if !gz {
+		f.WriteString(dump)
+		return name
+	}
+	zw := gzip.NewWriter(f)
+	zw.Write([]byte(dump))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+func TestIngest(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	tt := []struct {
+		desc string
+		gz   bool
+	}{
+		{"plain", false},
+		{"gzip", true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			ctx := context.Background()
+			name := writeDump(t, "ol_dump_latest.txt.gz", tc.gz)
+
+			ix, err := Open(filepath.Join(t.TempDir(), "index.db"))
+			is.NoErr(err) // opening index
+			defer ix.Close()
+			ix.BatchSize = 2
+
+			// interrupt the ingest after its first batch
+			ctx1, cancel := context.WithCancel(ctx)
+			err = ix.Ingest(ctx1, name, func(p Progress) { cancel() })
+			is.True(errors.Is(err, context.Canceled)) // interrupted ingest
+
+			var last Progress
+			err = ix.Ingest(ctx, name, func(p Progress) { last = p })
+			is.NoErr(err) // resumed ingest
+
+			is.Equal(last.Lines, int64(7))    // lines read
+			is.Equal(last.Resumed, int64(2))  // first batch not read again
+			is.Equal(last.Editions, int64(1)) // editions after first batch
+			is.Equal(last.Authors, int64(1))  // authors
+			is.Equal(last.Skipped, int64(3))  // skipped lines after first batch
+			is.True(last.Done)                // ingest complete
+			is.True(last.Read > 0)            // bytes read reported
+			is.Equal(last.Read, last.Size)    // whole file read
+
+			var called bool
+			err = ix.Ingest(ctx, name, func(p Progress) { called = true })
+			is.NoErr(err)    // repeated ingest
+			is.True(!called) // completed ingest not repeated
+
+			v, _ := isbn.Parse("0140328726")
+			book, err := ix.Resolve(ctx, v)
+			is.NoErr(err) // resolving isbn
+
+			is.Equal(book.ISBN, v)                                                       // requested isbn
+			is.Equal(book.Title, "Fantastic Mr. Fox")                                    // title
+			is.Equal(strings.Join(book.Authors, ";"), "Roald Dahl")                      // author names
+			is.Equal(book.Publisher, "Puffin")                                           // publisher
+			is.Equal(book.Pages, 96)                                                     // pages
+			is.Equal(book.Language, "eng")                                               // language
+			is.Equal(strings.Join(book.Subjects, ";"), "Animals;Foxes")                  // subjects
+			is.Equal(book.Description, "The Fantastic Mr. Fox outwits the farmers.")     // text block description
+			is.Equal(book.CoverURL, "https://covers.openlibrary.org/b/id/8739161-L.jpg") // cover
+			is.Equal(book.Source, Source)                                                // source
+
+			v, _ = isbn.Parse("9780716703440")
+			book, err = ix.Resolve(ctx, v)
+			is.NoErr(err)                                    // resolving hyphenated isbn 10
+			is.Equal(book.Subtitle, "a modern introduction") // subtitle
+			is.Equal(len(book.Authors), 0)                   // no authors
+			is.Equal(len(book.Subjects), 0)                  // no subjects
+
+			v, _ = isbn.Parse("9781861972712")
+			_, err = ix.Resolve(ctx, v)
+			is.True(errors.Is(err, metadata.ErrNotFound)) // isbn not in dump
+		})
+	}
+}
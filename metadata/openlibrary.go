@@ -0,0 +1,114 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/adoublef-go/isbn"
+)
+
+// OpenLibrary resolves books using the Open Library books API. The zero value
+// uses http.DefaultClient and https://openlibrary.org.
+type OpenLibrary struct {
+	Client *http.Client
+	// BaseURL overrides https://openlibrary.org, e.g. to point at a mirror.
+	BaseURL string
+}
+
+const openLibraryURL = "https://openlibrary.org"
+
+type openLibraryBook struct {
+	Title    string `json:"title"`
+	Subtitle string `json:"subtitle"`
+	Authors  []struct {
+		Name string `json:"name"`
+	} `json:"authors"`
+	Publishers []struct {
+		Name string `json:"name"`
+	} `json:"publishers"`
+	PublishDate string `json:"publish_date"`
+	Pages       int    `json:"number_of_pages"`
+	Subjects    []struct {
+		Name string `json:"name"`
+	} `json:"subjects"`
+	Notes any `json:"notes"`
+	Cover struct {
+		Large  string `json:"large"`
+		Medium string `json:"medium"`
+	} `json:"cover"`
+}
+
+// Resolve implements Resolver.
+func (ol *OpenLibrary) Resolve(ctx context.Context, v isbn.ISBN) (Book, error) {
+	base := ol.BaseURL
+	if base == "" {
+		base = openLibraryURL
+	}
+
+	key := "ISBN:" + v.String()
+	q := url.Values{"bibkeys": {key}, "format": {"json"}, "jscmd": {"data"}}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+"/api/books?"+q.Encode(), nil)
+	if err != nil {
+		return Book{}, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client(ol.Client).Do(req)
+	if err != nil {
+		return Book{}, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return Book{}, ErrNotFound
+	case http.StatusTooManyRequests:
+		return Book{}, &RateLimitError{Source: "openlibrary", RetryAfter: retryAfter(resp)}
+	default:
+		return Book{}, fmt.Errorf("openlibrary: unexpected status %s", resp.Status)
+	}
+
+	var books map[string]openLibraryBook
+	if err := json.NewDecoder(resp.Body).Decode(&books); err != nil {
+		return Book{}, fmt.Errorf("openlibrary: %w", err)
+	}
+	b, ok := books[key]
+	if !ok {
+		return Book{}, ErrNotFound
+	}
+
+	book := Book{
+		ISBN:      v,
+		Title:     b.Title,
+		Subtitle:  b.Subtitle,
+		Published: b.PublishDate,
+		Pages:     b.Pages,
+		CoverURL:  b.Cover.Large,
+		Source:    "openlibrary",
+	}
+	if book.CoverURL == "" {
+		book.CoverURL = b.Cover.Medium
+	}
+	for _, a := range b.Authors {
+		book.Authors = append(book.Authors, a.Name)
+	}
+	if len(b.Publishers) > 0 {
+		book.Publisher = b.Publishers[0].Name
+	}
+	for _, s := range b.Subjects {
+		book.Subjects = append(book.Subjects, s.Name)
+	}
+	// notes are either a string or a {"type": ..., "value": ...} text block
+	switch n := b.Notes.(type) {
+	case string:
+		book.Description = n
+	case map[string]any:
+		book.Description, _ = n["value"].(string)
+	}
+	return book, nil
+}
@@ -0,0 +1,125 @@
+package metadata
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/adoublef-go/isbn"
+)
+
+// SQLCache is a Cache held in a database/sql table, so that it is shared
+// between processes and survives restarts. The statements it uses are
+// understood by both SQLite and PostgreSQL.
+type SQLCache struct {
+	db *sql.DB
+}
+
+// NewSQLCache returns a SQLCache using db. Migrate creates its table.
+func NewSQLCache(db *sql.DB) *SQLCache { return &SQLCache{db: db} }
+
+// Migrate creates the isbn_metadata table if it does not already exist.
+func (c *SQLCache) Migrate(ctx context.Context) error {
+	_, err := c.db.ExecContext(ctx, `
+CREATE TABLE IF NOT EXISTS isbn_metadata (
+	isbn    TEXT PRIMARY KEY,
+	book    TEXT,
+	expires BIGINT NOT NULL
+)`)
+	return err
+}
+
+// bookRecord is the JSON form of a Book stored by SQLCache.
+type bookRecord struct {
+	Title       string   `json:"title,omitempty"`
+	Subtitle    string   `json:"subtitle,omitempty"`
+	Authors     []string `json:"authors,omitempty"`
+	Publisher   string   `json:"publisher,omitempty"`
+	Published   string   `json:"published,omitempty"`
+	Pages       int      `json:"pages,omitempty"`
+	Language    string   `json:"language,omitempty"`
+	Subjects    []string `json:"subjects,omitempty"`
+	Description string   `json:"description,omitempty"`
+	CoverURL    string   `json:"cover_url,omitempty"`
+	Source      string   `json:"source,omitempty"`
+}
+
+// Get implements Cache.
+func (c *SQLCache) Get(ctx context.Context, v isbn.ISBN) (Entry, bool, error) {
+	var (
+		book    sql.NullString
+		expires int64
+	)
+	err := c.db.QueryRowContext(ctx, `SELECT book, expires FROM isbn_metadata WHERE isbn = $1`, v.String()).
+		Scan(&book, &expires)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return Entry{}, false, nil
+	case err != nil:
+		return Entry{}, false, err
+	}
+
+	e := Entry{NotFound: !book.Valid, Expires: time.Unix(expires, 0)}
+	if book.Valid {
+		var r bookRecord
+		if err := json.Unmarshal([]byte(book.String), &r); err != nil {
+			return Entry{}, false, err
+		}
+		e.Book = Book{
+			ISBN:        v,
+			Title:       r.Title,
+			Subtitle:    r.Subtitle,
+			Authors:     r.Authors,
+			Publisher:   r.Publisher,
+			Published:   r.Published,
+			Pages:       r.Pages,
+			Language:    r.Language,
+			Subjects:    r.Subjects,
+			Description: r.Description,
+			CoverURL:    r.CoverURL,
+			Source:      r.Source,
+		}
+	}
+	return e, true, nil
+}
+
+// Put implements Cache.
+func (c *SQLCache) Put(ctx context.Context, v isbn.ISBN, e Entry) error {
+	var book sql.NullString
+	if !e.NotFound {
+		b, err := json.Marshal(bookRecord{
+			Title:       e.Book.Title,
+			Subtitle:    e.Book.Subtitle,
+			Authors:     e.Book.Authors,
+			Publisher:   e.Book.Publisher,
+			Published:   e.Book.Published,
+			Pages:       e.Book.Pages,
+			Language:    e.Book.Language,
+			Subjects:    e.Book.Subjects,
+			Description: e.Book.Description,
+			CoverURL:    e.Book.CoverURL,
+			Source:      e.Book.Source,
+		})
+		if err != nil {
+			return err
+		}
+		book = sql.NullString{String: string(b), Valid: true}
+	}
+
+	_, err := c.db.ExecContext(ctx, `
+INSERT INTO isbn_metadata (isbn, book, expires) VALUES ($1, $2, $3)
+ON CONFLICT (isbn) DO UPDATE SET book = excluded.book, expires = excluded.expires`,
+		v.String(), book, e.Expires.Unix())
+	return err
+}
+
+// DeleteExpired removes the entries that expired before now.
+func (c *SQLCache) DeleteExpired(ctx context.Context) (int64, error) {
+	res, err := c.db.ExecContext(ctx, `DELETE FROM isbn_metadata WHERE expires <= $1`, time.Now().Unix())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,141 @@
+package onix
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// A Decoder reads the products of an ONIX 3.0 message from a stream.
+type Decoder struct {
+	d      *xml.Decoder
+	header Header
+	// release is the release attribute of the message.
+	release string
+	started bool
+}
+
+// NewDecoder returns a Decoder reading from r. Messages encoded as UTF-8,
+// US-ASCII and ISO-8859-1 are understood, as are the HTML entities feeds
+// often use in their text.
+func NewDecoder(r io.Reader) *Decoder {
+	d := xml.NewDecoder(r)
+	d.Entity = xml.HTMLEntity
+	d.CharsetReader = charsetReader
+
+	// short tags are renamed to reference tags as they are read
+	return &Decoder{d: xml.NewTokenDecoder(&renamer{d})}
+}
+
+// Header returns the header of the message. It is only known once Next has
+// been called.
+func (d *Decoder) Header() Header { return d.header }
+
+// Next returns the next product of the message, and io.EOF once there are no
+// more.
+func (d *Decoder) Next() (*Product, error) {
+	for {
+		tok, err := d.d.Token()
+		if err != nil {
+			if err == io.EOF && !d.started {
+				return nil, fmt.Errorf("onix: no ONIXMessage element")
+			}
+			return nil, err
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "ONIXMessage":
+			d.started = true
+			for _, attr := range start.Attr {
+				if attr.Name.Local == "release" {
+					d.release = attr.Value
+				}
+			}
+			if d.release != "" && !strings.HasPrefix(d.release, "3.") {
+				return nil, fmt.Errorf("onix: unsupported release %s", d.release)
+			}
+		case "Header":
+			var h xmlHeader
+			if err := d.d.DecodeElement(&h, &start); err != nil {
+				return nil, fmt.Errorf("onix: header: %w", err)
+			}
+			d.header = Header{
+				Sender: strings.TrimSpace(h.SenderName),
+				Sent:   strings.TrimSpace(h.SentDateTime),
+			}
+		case "Product":
+			var p xmlProduct
+			if err := d.d.DecodeElement(&p, &start); err != nil {
+				return nil, fmt.Errorf("onix: product: %w", err)
+			}
+			return p.product(), nil
+		default:
+			if !d.started {
+				return nil, fmt.Errorf("onix: unexpected element <%s>, want ONIXMessage", start.Name.Local)
+			}
+			// skip anything else, such as a NoProduct element
+			if err := d.d.Skip(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// renamer renames the short tags of a message to reference tags.
+type renamer struct{ d *xml.Decoder }
+
+func (r *renamer) Token() (xml.Token, error) {
+	tok, err := r.d.Token()
+	switch t := tok.(type) {
+	case xml.StartElement:
+		if ref, ok := shortTags[t.Name.Local]; ok {
+			t.Name.Local = ref
+		}
+		return t, err
+	case xml.EndElement:
+		if ref, ok := shortTags[t.Name.Local]; ok {
+			t.Name.Local = ref
+		}
+		return t, err
+	}
+	return tok, err
+}
+
+func charsetReader(charset string, r io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "iso-8859-1", "iso8859-1", "latin1", "latin-1":
+		return &latin1Reader{r: r}, nil
+	case "us-ascii", "ascii":
+		return r, nil
+	}
+	return nil, fmt.Errorf("onix: unsupported charset %s", charset)
+}
+
+// latin1Reader decodes ISO-8859-1 to UTF-8, in which each byte is a rune.
+type latin1Reader struct {
+	r   io.Reader
+	buf []byte
+}
+
+func (r *latin1Reader) Read(p []byte) (int, error) {
+	// each byte encodes to at most two
+	if n := len(p) / 2; n > 0 {
+		if cap(r.buf) < n {
+			r.buf = make([]byte, n)
+		}
+		n, err := r.r.Read(r.buf[:n])
+		var w int
+		for _, b := range r.buf[:n] {
+			w += utf8.EncodeRune(p[w:], rune(b))
+		}
+		return w, err
+	}
+	return 0, io.ErrShortBuffer
+}
@@ -0,0 +1,193 @@
+package onix
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/adoublef-go/isbn"
+)
+
+// An Encoder writes an ONIX 3.0 message with reference tags. The header is
+// written with the first product, and Close ends the message.
+type Encoder struct {
+	w   io.Writer
+	e   *xml.Encoder
+	err error
+
+	// Header is written as the header of the message. A zero Sent is set
+	// to the time of the first Encode.
+	Header  Header
+	started bool
+}
+
+// NewEncoder returns an Encoder writing to w.
+func NewEncoder(w io.Writer) *Encoder {
+	e := xml.NewEncoder(w)
+	e.Indent("", "  ")
+	return &Encoder{w: w, e: e}
+}
+
+var errClosed = errors.New("onix: encoder closed")
+
+// Encode writes p as a product record. Only the fields of Product are
+// written, which is enough to describe a book; p should have an ISBN.
+func (e *Encoder) Encode(p *Product) error {
+	if e.err != nil {
+		return e.err
+	}
+	if err := e.start(); err != nil {
+		return e.fail(err)
+	}
+	return e.fail(e.e.Encode(xmlFromProduct(p)))
+}
+
+// Close ends the message and flushes it to the underlying writer. It does
+// not close the writer.
+func (e *Encoder) Close() error {
+	if e.err != nil {
+		if e.err == errClosed {
+			return nil
+		}
+		return e.err
+	}
+	if err := e.start(); err != nil {
+		return e.fail(err)
+	}
+	if err := e.e.EncodeToken(xml.EndElement{Name: xml.Name{Local: "ONIXMessage"}}); err != nil {
+		return e.fail(err)
+	}
+	if err := e.e.Flush(); err != nil {
+		return e.fail(err)
+	}
+	if _, err := io.WriteString(e.w, "\n"); err != nil {
+		return e.fail(err)
+	}
+	e.err = errClosed
+	return nil
+}
+
+func (e *Encoder) fail(err error) error {
+	if err != nil {
+		e.err = err
+	}
+	return err
+}
+
+func (e *Encoder) start() error {
+	if e.started {
+		return nil
+	}
+	e.started = true
+
+	if _, err := io.WriteString(e.w, xml.Header); err != nil {
+		return err
+	}
+	root := xml.StartElement{
+		Name: xml.Name{Local: "ONIXMessage"},
+		Attr: []xml.Attr{
+			{Name: xml.Name{Local: "xmlns"}, Value: Namespace},
+			{Name: xml.Name{Local: "release"}, Value: "3.0"},
+		},
+	}
+	if err := e.e.EncodeToken(root); err != nil {
+		return err
+	}
+
+	h := xmlHeader{SenderName: e.Header.Sender, SentDateTime: e.Header.Sent}
+	if h.SentDateTime == "" {
+		h.SentDateTime = time.Now().UTC().Format("20060102T1504Z")
+	}
+	return e.e.Encode(h)
+}
+
+func xmlFromProduct(p *Product) *xmlProduct {
+	x := &xmlProduct{
+		RecordReference:  p.RecordReference,
+		NotificationType: p.NotificationType,
+	}
+	if x.NotificationType == "" {
+		// confirmed record
+		x.NotificationType = "03"
+	}
+
+	x.Identifiers = make([]xmlProductIdentifier, 0, len(p.Identifiers)+1)
+	var hasISBN bool
+	for _, id := range p.Identifiers {
+		x.Identifiers = append(x.Identifiers, xmlProductIdentifier{ProductIDType: id.Type, IDValue: id.Value})
+		hasISBN = hasISBN || id.Type == IDISBN13
+	}
+	if !hasISBN && p.ISBN != (isbn.ISBN{}) {
+		x.Identifiers = append(x.Identifiers, xmlProductIdentifier{ProductIDType: IDISBN13, IDValue: p.ISBN.String()})
+	}
+	if x.RecordReference == "" && len(x.Identifiers) > 0 {
+		x.RecordReference = x.Identifiers[0].IDValue
+	}
+
+	d := &xmlDescriptiveDetail{
+		// a single item, e.g. a book
+		ProductComposition: "00",
+		ProductForm:        p.Form,
+		TitleDetails: []xmlTitleDetail{{
+			TitleType: "01",
+			TitleElements: []xmlTitleElement{{
+				TitleElementLevel: "01",
+				TitleText:         p.Title,
+				Subtitle:          p.Subtitle,
+			}},
+		}},
+	}
+	if d.ProductForm == "" {
+		// undefined
+		d.ProductForm = "00"
+	}
+	for i, c := range p.Contributors {
+		d.Contributors = append(d.Contributors, xmlContributor{
+			SequenceNumber:  strconv.Itoa(i + 1),
+			ContributorRole: c.Role,
+			PersonName:      c.Name,
+		})
+	}
+	if p.Language != "" {
+		d.Languages = []xmlLanguage{{LanguageRole: "01", LanguageCode: p.Language}}
+	}
+	if p.Pages > 0 {
+		d.Extents = []xmlExtent{{ExtentType: "00", ExtentValue: strconv.Itoa(p.Pages), ExtentUnit: "03"}}
+	}
+	x.DescriptiveDetail = d
+
+	if p.Publisher != "" || p.Published != "" {
+		pd := &xmlPublishingDetail{}
+		if p.Publisher != "" {
+			pd.Publishers = []xmlPublisher{{PublishingRole: "01", PublisherName: p.Publisher}}
+		}
+		if p.Published != "" {
+			pd.PublishingDates = []xmlPublishingDate{{PublishingDateRole: "01", Date: p.Published}}
+		}
+		x.PublishingDetail = pd
+	}
+
+	if p.Availability != "" || len(p.Prices) > 0 {
+		sd := xmlSupplyDetail{
+			// the publisher, supplying to retailers
+			SupplierRole:        "01",
+			SupplierName:        p.Publisher,
+			ProductAvailability: p.Availability,
+		}
+		if sd.ProductAvailability == "" {
+			// available
+			sd.ProductAvailability = "20"
+		}
+		for _, price := range p.Prices {
+			sd.Prices = append(sd.Prices, xmlPrice{
+				PriceType:    price.Type,
+				PriceAmount:  price.Amount,
+				CurrencyCode: price.Currency,
+			})
+		}
+		x.ProductSupply = &xmlProductSupply{SupplyDetails: []xmlSupplyDetail{sd}}
+	}
+	return x
+}
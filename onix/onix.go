@@ -0,0 +1,125 @@
+// Package onix reads and writes ONIX for Books 3.0 messages, the XML format
+// publishers use to describe their products.
+//
+// A Decoder streams the Product records of a message one at a time, so that
+// a feed of any size is read in constant memory. Both the reference tags
+// (<Product>, <ProductIdentifier>) and the short tags (<product>,
+// <productidentifier>) are understood.
+//
+//	d := onix.NewDecoder(f)
+//	for {
+//		p, err := d.Next()
+//		if err == io.EOF {
+//			break
+//		}
+//		...
+//	}
+//
+// An Encoder writes a minimal message with reference tags.
+package onix
+
+import (
+	"strings"
+
+	"github.com/adoublef-go/isbn"
+	"github.com/adoublef-go/isbn/metadata"
+)
+
+// Namespace is the XML namespace of ONIX 3.0 reference tags.
+const Namespace = "http://ns.editeur.org/onix/3.0/reference"
+
+// Codes of ONIX code list 5, product identifier types.
+const (
+	IDProprietary = "01"
+	IDISBN10      = "02"
+	IDGTIN13      = "03"
+	IDISBN13      = "15"
+)
+
+// Header is the header of a message.
+type Header struct {
+	Sender string
+	// Sent is the date and time the message was sent, as given in the
+	// message, e.g. "20240131T1200Z".
+	Sent string
+}
+
+// Product is a product record, reduced to the fields used for books.
+type Product struct {
+	RecordReference string
+	// NotificationType is a code from ONIX code list 1, e.g. "03" for a
+	// confirmed record, "05" for a deletion.
+	NotificationType string
+
+	Identifiers []Identifier
+	// ISBN is from the ISBN 13, ISBN 10 or 978/979 GTIN identifier, and is
+	// the zero ISBN if there is none.
+	ISBN isbn.ISBN
+	// GTIN is the GTIN 13 identifier, if any.
+	GTIN string
+
+	// Form is a code from ONIX code list 150, e.g. "BC" for a paperback.
+	Form         string
+	Title        string
+	Subtitle     string
+	Contributors []Contributor
+	// Language is the ISO 639-2/B code of the language of the text.
+	Language string
+	// Pages is the number of pages of the main content.
+	Pages int
+
+	Publisher string
+	// Published is the publication date as given, usually YYYYMMDD.
+	Published string
+
+	// Availability is a code from ONIX code list 65, e.g. "21" for in
+	// stock, of the first supplier.
+	Availability string
+	Prices       []Price
+}
+
+// Identifier is a product identifier.
+type Identifier struct {
+	// Type is a code from ONIX code list 5, such as IDISBN13.
+	Type  string
+	Value string
+}
+
+// Contributor is a contributor to a product.
+type Contributor struct {
+	// Role is a code from ONIX code list 17, e.g. "A01" for an author.
+	Role string
+	// Name is the name of a person or corporate body.
+	Name string
+}
+
+// Price is a price of a product.
+type Price struct {
+	// Type is a code from ONIX code list 58, e.g. "02" for the recommended
+	// retail price including tax.
+	Type string
+	// Amount is the decimal amount as given, e.g. "9.99".
+	Amount   string
+	Currency string
+}
+
+// Book returns the metadata of p.
+func (p *Product) Book() metadata.Book {
+	book := metadata.Book{
+		ISBN:      p.ISBN,
+		Title:     p.Title,
+		Subtitle:  p.Subtitle,
+		Publisher: p.Publisher,
+		Published: p.Published,
+		Pages:     p.Pages,
+		Language:  p.Language,
+		Source:    "onix",
+	}
+	for _, c := range p.Contributors {
+		// authors, with or without others
+		if strings.HasPrefix(c.Role, "A") {
+			book.Authors = append(book.Authors, c.Name)
+		}
+	}
+	return book
+}
@@ -0,0 +1,249 @@
+package onix
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/adoublef-go/isbn"
+	"github.com/hyphengolang/prelude/testing/is"
+)
+
+func decodeAll(t *testing.T, r io.Reader) (Header, []*Product) {
+	t.Helper()
+
+	d := NewDecoder(r)
+	var products []*Product
+	for {
+		p, err := d.Next()
+		if err == io.EOF {
+			return d.Header(), products
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		products = append(products, p)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	tt := []struct {
+		desc string
+		name string
+	}{
+		{"reference tags", "testdata/reference.xml"},
+		{"short tags", "testdata/short.xml"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			f, err := os.Open(tc.name)
+			is.NoErr(err) // opening message
+			defer f.Close()
+
+			h, products := decodeAll(t, f)
+			is.Equal(h.Sender, "Puffin Books") // sender
+			is.Equal(h.Sent, "20240131T1200Z") // sent date
+			is.Equal(len(products), 2)         // products in message
+
+			fox := products[0]
+			is.Equal(fox.RecordReference, "com.puffin.9780140328721")            // record reference
+			is.Equal(fox.NotificationType, "03")                                 // notification type
+			is.Equal(len(fox.Identifiers), 3)                                    // all identifiers
+			is.Equal(fox.ISBN.String(), "9780140328721")                         // isbn
+			is.Equal(fox.GTIN, "9780140328721")                                  // gtin
+			is.Equal(fox.Form, "BC")                                             // product form
+			is.Equal(fox.Title, "Fantastic Mr Fox")                              // title
+			is.Equal(fox.Subtitle, "The Fox who outwits the farmers\u00a0three") // html entity
+			is.Equal(fox.Contributors, []Contributor{
+				{Role: "A01", Name: "Roald Dahl"},
+				{Role: "A12", Name: "Quentin Blake"},
+			}) // contributors
+			is.Equal(fox.Language, "eng")                                 // language
+			is.Equal(fox.Pages, 96)                                       // page count
+			is.Equal(fox.Publisher, "Penguin Random House Children's UK") // publisher
+			is.Equal(fox.Published, "19881001")                           // publication date
+			is.Equal(fox.Availability, "21")                              // availability
+			is.Equal(fox.Prices, []Price{
+				{Type: "02", Amount: "7.99", Currency: "GBP"},
+				{Type: "01", Amount: "9.99", Currency: "USD"},
+			}) // prices
+
+			science := products[1]
+			is.Equal(science.NotificationType, "05")                      // deletion
+			is.Equal(science.ISBN.String(), "9780716703440")              // isbn from isbn 10
+			is.Equal(science.GTIN, "")                                    // no gtin
+			is.Equal(science.Title, "The Science of Computing")           // title with prefix
+			is.Equal(science.Contributors[0].Name, "Scientific American") // corporate name
+			is.Equal(science.Publisher, "")                               // no publishing detail
+
+			book := fox.Book()
+			is.Equal(book.ISBN, fox.ISBN)                                         // book isbn
+			is.Equal(strings.Join(book.Authors, ";"), "Roald Dahl;Quentin Blake") // book authors
+		})
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	tt := []struct {
+		desc string
+		data string
+	}{
+		{"empty", ``},
+		{"not onix", `<html><body/></html>`},
+		{"onix 2.1", `<ONIXMessage release="2.1"><Product/></ONIXMessage>`},
+		{"truncated", `<ONIXMessage release="3.0"><Product><RecordReference>x</Record`},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := NewDecoder(strings.NewReader(tc.data)).Next()
+			is.True(err != nil && err != io.EOF) // invalid message
+		})
+	}
+}
+
+func TestDecodeGTIN(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	product := func(gtin string) string {
+		return `<Product><RecordReference>` + gtin + `</RecordReference><ProductIdentifier>` +
+			`<ProductIDType>03</ProductIDType><IDValue>` + gtin + `</IDValue></ProductIdentifier></Product>`
+	}
+	data := `<ONIXMessage release="3.0">` + product("9780140328721") + product("4006381333931") + product("9790000000001") + `</ONIXMessage>`
+
+	_, products := decodeAll(t, strings.NewReader(data))
+	is.Equal(len(products), 3) // products in message
+
+	is.Equal(products[0].ISBN.String(), "9780140328721") // isbn from bookland gtin
+	is.Equal(products[1].GTIN, "4006381333931")          // gtin
+	is.Equal(products[1].ISBN, isbn.ISBN{})              // no isbn from other gtin
+	is.Equal(products[2].GTIN, "9790000000001")          // ismn gtin
+	is.Equal(products[2].ISBN, isbn.ISBN{})              // no isbn from ismn
+}
+
+func TestDecodeLatin1(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	data := "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?>\n" +
+		"<ONIXMessage release=\"3.0\"><Product><DescriptiveDetail><TitleDetail><TitleType>01</TitleType>" +
+		"<TitleElement><TitleElementLevel>01</TitleElementLevel><TitleText>Les Mis\xe9rables</TitleText>" +
+		"</TitleElement></TitleDetail></DescriptiveDetail></Product></ONIXMessage>"
+
+	p, err := NewDecoder(strings.NewReader(data)).Next()
+	is.NoErr(err)                       // decoding latin 1
+	is.Equal(p.Title, "Les Misérables") // title decoded to utf 8
+}
+
+// products streams a message of n products without holding it in memory.
+type products struct {
+	n, i int
+	buf  bytes.Buffer
+}
+
+func (r *products) Read(p []byte) (int, error) {
+	for r.buf.Len() == 0 {
+		switch {
+		case r.i == 0:
+			r.buf.WriteString(`<ONIXMessage release="3.0" xmlns="` + Namespace + `">`)
+		case r.i <= r.n:
+			fmt.Fprintf(&r.buf, `<Product><RecordReference>%d</RecordReference>`+
+				`<ProductIdentifier><ProductIDType>15</ProductIDType><IDValue>9780140328721</IDValue></ProductIdentifier>`+
+				`</Product>`, r.i)
+		case r.i == r.n+1:
+			r.buf.WriteString(`</ONIXMessage>`)
+		default:
+			return 0, io.EOF
+		}
+		r.i++
+	}
+	return r.buf.Read(p)
+}
+
+func TestDecodeStream(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	const n = 20000
+
+	d := NewDecoder(&products{n: n})
+	var count int
+	for {
+		p, err := d.Next()
+		if err == io.EOF {
+			break
+		}
+		is.NoErr(err) // decoding product
+		count++
+		if p.RecordReference != fmt.Sprint(count) {
+			t.Fatalf("product %d: record reference %s", count, p.RecordReference)
+		}
+	}
+	is.Equal(count, n) // all products decoded
+}
+
+func TestEncode(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	v, _ := isbn.Parse("9780140328721")
+	want := []*Product{
+		{
+			RecordReference:  "9780140328721",
+			NotificationType: "03",
+			Identifiers:      []Identifier{{Type: IDISBN13, Value: "9780140328721"}},
+			ISBN:             v,
+			Form:             "BC",
+			Title:            "Fantastic Mr Fox",
+			Subtitle:         "Farmers & Foxes",
+			Contributors:     []Contributor{{Role: "A01", Name: "Roald Dahl"}},
+			Language:         "eng",
+			Pages:            96,
+			Publisher:        "Puffin",
+			Published:        "19881001",
+			Availability:     "21",
+			Prices:           []Price{{Type: "02", Amount: "7.99", Currency: "GBP"}},
+		},
+		{
+			RecordReference:  "9780716703440",
+			NotificationType: "03",
+			Identifiers:      []Identifier{{Type: IDISBN13, Value: "9780716703440"}},
+			Form:             "00",
+			Title:            "Computer science",
+		},
+	}
+	want[1].ISBN, _ = isbn.Parse("9780716703440")
+
+	var buf bytes.Buffer
+	e := NewEncoder(&buf)
+	e.Header = Header{Sender: "adoublef", Sent: "20241019T0900Z"}
+
+	// identifiers are written from the ISBN if there are none
+	p := *want[0]
+	p.Identifiers, p.RecordReference = nil, ""
+	is.NoErr(e.Encode(&p)) // encoding product
+
+	p = *want[1]
+	p.Identifiers, p.RecordReference, p.NotificationType, p.Form = nil, "", "", ""
+	is.NoErr(e.Encode(&p)) // encoding minimal product
+	is.NoErr(e.Close())    // ending message
+	is.NoErr(e.Close())    // closing twice
+
+	is.True(strings.Contains(buf.String(), `<ONIXMessage xmlns="`+Namespace+`" release="3.0">`)) // root element
+
+	h, got := decodeAll(t, &buf)
+	is.Equal(h, e.Header)                 // header round trip
+	is.True(reflect.DeepEqual(got, want)) // products round trip
+}
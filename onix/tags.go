@@ -0,0 +1,63 @@
+package onix
+
+// shortTags maps the short tags of the elements read by a Decoder to their
+// reference tags. Elements not listed are skipped, and so need no mapping.
+var shortTags = map[string]string{
+	"ONIXmessage": "ONIXMessage",
+	"header":      "Header",
+	"sender":      "Sender",
+	"x298":        "SenderName",
+	"x307":        "SentDateTime",
+
+	"product":           "Product",
+	"a001":              "RecordReference",
+	"a002":              "NotificationType",
+	"productidentifier": "ProductIdentifier",
+	"b221":              "ProductIDType",
+	"b244":              "IDValue",
+
+	"descriptivedetail": "DescriptiveDetail",
+	"x314":              "ProductComposition",
+	"b012":              "ProductForm",
+	"titledetail":       "TitleDetail",
+	"b202":              "TitleType",
+	"titleelement":      "TitleElement",
+	"x409":              "TitleElementLevel",
+	"b203":              "TitleText",
+	"b030":              "TitlePrefix",
+	"b031":              "TitleWithoutPrefix",
+	"b029":              "Subtitle",
+	"contributor":       "Contributor",
+	"b034":              "SequenceNumber",
+	"b035":              "ContributorRole",
+	"b036":              "PersonName",
+	"b039":              "NamesBeforeKey",
+	"b040":              "KeyNames",
+	"b047":              "CorporateName",
+	"language":          "Language",
+	"b253":              "LanguageRole",
+	"b252":              "LanguageCode",
+	"extent":            "Extent",
+	"b218":              "ExtentType",
+	"b219":              "ExtentValue",
+	"b220":              "ExtentUnit",
+
+	"publishingdetail": "PublishingDetail",
+	"publisher":        "Publisher",
+	"b291":             "PublishingRole",
+	"b081":             "PublisherName",
+	"publishingdate":   "PublishingDate",
+	"x448":             "PublishingDateRole",
+	"b306":             "Date",
+
+	"productsupply": "ProductSupply",
+	"supplydetail":  "SupplyDetail",
+	"supplier":      "Supplier",
+	"j292":          "SupplierRole",
+	"j137":          "SupplierName",
+	"j396":          "ProductAvailability",
+	"price":         "Price",
+	"x462":          "PriceType",
+	"j151":          "PriceAmount",
+	"j152":          "CurrencyCode",
+}
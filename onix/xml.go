@@ -0,0 +1,221 @@
+package onix
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/adoublef-go/isbn"
+)
+
+// The XML of a message, with reference tags. Short tags are renamed to
+// these as they are read, see shortTags.
+
+type xmlHeader struct {
+	XMLName      struct{} `xml:"Header"`
+	SenderName   string   `xml:"Sender>SenderName"`
+	SentDateTime string   `xml:"SentDateTime"`
+}
+
+type xmlProduct struct {
+	XMLName           struct{}               `xml:"Product"`
+	RecordReference   string                 `xml:"RecordReference"`
+	NotificationType  string                 `xml:"NotificationType"`
+	Identifiers       []xmlProductIdentifier `xml:"ProductIdentifier"`
+	DescriptiveDetail *xmlDescriptiveDetail  `xml:"DescriptiveDetail"`
+	PublishingDetail  *xmlPublishingDetail   `xml:"PublishingDetail"`
+	ProductSupply     *xmlProductSupply      `xml:"ProductSupply"`
+}
+
+type xmlProductIdentifier struct {
+	ProductIDType string `xml:"ProductIDType"`
+	IDValue       string `xml:"IDValue"`
+}
+
+type xmlDescriptiveDetail struct {
+	ProductComposition string           `xml:"ProductComposition"`
+	ProductForm        string           `xml:"ProductForm"`
+	TitleDetails       []xmlTitleDetail `xml:"TitleDetail"`
+	Contributors       []xmlContributor `xml:"Contributor"`
+	Languages          []xmlLanguage    `xml:"Language"`
+	Extents            []xmlExtent      `xml:"Extent"`
+}
+
+type xmlTitleDetail struct {
+	TitleType     string            `xml:"TitleType"`
+	TitleElements []xmlTitleElement `xml:"TitleElement"`
+}
+
+type xmlTitleElement struct {
+	TitleElementLevel  string `xml:"TitleElementLevel"`
+	TitleText          string `xml:"TitleText,omitempty"`
+	TitlePrefix        string `xml:"TitlePrefix,omitempty"`
+	TitleWithoutPrefix string `xml:"TitleWithoutPrefix,omitempty"`
+	Subtitle           string `xml:"Subtitle,omitempty"`
+}
+
+type xmlContributor struct {
+	SequenceNumber  string `xml:"SequenceNumber,omitempty"`
+	ContributorRole string `xml:"ContributorRole"`
+	PersonName      string `xml:"PersonName,omitempty"`
+	NamesBeforeKey  string `xml:"NamesBeforeKey,omitempty"`
+	KeyNames        string `xml:"KeyNames,omitempty"`
+	CorporateName   string `xml:"CorporateName,omitempty"`
+}
+
+type xmlLanguage struct {
+	LanguageRole string `xml:"LanguageRole"`
+	LanguageCode string `xml:"LanguageCode"`
+}
+
+type xmlExtent struct {
+	ExtentType  string `xml:"ExtentType"`
+	ExtentValue string `xml:"ExtentValue"`
+	ExtentUnit  string `xml:"ExtentUnit"`
+}
+
+type xmlPublishingDetail struct {
+	Publishers      []xmlPublisher      `xml:"Publisher"`
+	PublishingDates []xmlPublishingDate `xml:"PublishingDate"`
+}
+
+type xmlPublisher struct {
+	PublishingRole string `xml:"PublishingRole"`
+	PublisherName  string `xml:"PublisherName"`
+}
+
+type xmlPublishingDate struct {
+	PublishingDateRole string `xml:"PublishingDateRole"`
+	Date               string `xml:"Date"`
+}
+
+type xmlProductSupply struct {
+	SupplyDetails []xmlSupplyDetail `xml:"SupplyDetail"`
+}
+
+type xmlSupplyDetail struct {
+	SupplierRole        string     `xml:"Supplier>SupplierRole,omitempty"`
+	SupplierName        string     `xml:"Supplier>SupplierName,omitempty"`
+	ProductAvailability string     `xml:"ProductAvailability"`
+	Prices              []xmlPrice `xml:"Price"`
+}
+
+type xmlPrice struct {
+	PriceType    string `xml:"PriceType,omitempty"`
+	PriceAmount  string `xml:"PriceAmount"`
+	CurrencyCode string `xml:"CurrencyCode,omitempty"`
+}
+
+func (x *xmlProduct) product() *Product {
+	p := &Product{
+		RecordReference:  strings.TrimSpace(x.RecordReference),
+		NotificationType: strings.TrimSpace(x.NotificationType),
+	}
+
+	for _, id := range x.Identifiers {
+		typ, value := strings.TrimSpace(id.ProductIDType), strings.TrimSpace(id.IDValue)
+		p.Identifiers = append(p.Identifiers, Identifier{Type: typ, Value: value})
+
+		switch typ {
+		case IDGTIN13:
+			p.GTIN = value
+			// only a GTIN with a Bookland prefix is an ISBN, and 9790 is
+			// the ISMN of printed music
+			if !strings.HasPrefix(value, "978") && !strings.HasPrefix(value, "979") || strings.HasPrefix(value, "9790") {
+				break
+			}
+			fallthrough
+		case IDISBN13, IDISBN10:
+			// an ISBN identifier takes precedence over a GTIN
+			if v, err := isbn.Parse(value); err == nil && (p.ISBN == isbn.ISBN{} || typ != IDGTIN13) {
+				p.ISBN = v
+			}
+		}
+	}
+
+	if d := x.DescriptiveDetail; d != nil {
+		p.Form = strings.TrimSpace(d.ProductForm)
+		p.Title, p.Subtitle = title(d.TitleDetails)
+
+		for _, c := range d.Contributors {
+			name := c.PersonName
+			if name == "" {
+				name = strings.TrimSpace(c.NamesBeforeKey + " " + c.KeyNames)
+			}
+			if name == "" {
+				name = c.CorporateName
+			}
+			p.Contributors = append(p.Contributors, Contributor{
+				Role: strings.TrimSpace(c.ContributorRole),
+				Name: strings.TrimSpace(name),
+			})
+		}
+
+		for _, l := range d.Languages {
+			// the language of the text
+			if strings.TrimSpace(l.LanguageRole) == "01" {
+				p.Language = strings.TrimSpace(l.LanguageCode)
+				break
+			}
+		}
+
+		for _, e := range d.Extents {
+			// main content page count, in pages
+			if strings.TrimSpace(e.ExtentType) == "00" && strings.TrimSpace(e.ExtentUnit) == "03" {
+				p.Pages, _ = strconv.Atoi(strings.TrimSpace(e.ExtentValue))
+				break
+			}
+		}
+	}
+
+	if d := x.PublishingDetail; d != nil {
+		for _, pub := range d.Publishers {
+			if p.Publisher == "" || strings.TrimSpace(pub.PublishingRole) == "01" {
+				p.Publisher = strings.TrimSpace(pub.PublisherName)
+			}
+		}
+		for _, date := range d.PublishingDates {
+			// the publication date
+			if strings.TrimSpace(date.PublishingDateRole) == "01" {
+				p.Published = strings.TrimSpace(date.Date)
+				break
+			}
+		}
+	}
+
+	if s := x.ProductSupply; s != nil {
+		for i, d := range s.SupplyDetails {
+			if i == 0 {
+				p.Availability = strings.TrimSpace(d.ProductAvailability)
+			}
+			for _, price := range d.Prices {
+				p.Prices = append(p.Prices, Price{
+					Type:     strings.TrimSpace(price.PriceType),
+					Amount:   strings.TrimSpace(price.PriceAmount),
+					Currency: strings.TrimSpace(price.CurrencyCode),
+				})
+			}
+		}
+	}
+	return p
+}
+
+// title returns the title and subtitle of the product itself, the title
+// element of level 01 of the distinctive title, type 01.
+func title(details []xmlTitleDetail) (title, subtitle string) {
+	for _, d := range details {
+		if strings.TrimSpace(d.TitleType) != "01" {
+			continue
+		}
+		for _, e := range d.TitleElements {
+			if strings.TrimSpace(e.TitleElementLevel) != "01" {
+				continue
+			}
+			title = e.TitleText
+			if title == "" {
+				title = strings.TrimSpace(e.TitlePrefix + " " + e.TitleWithoutPrefix)
+			}
+			return strings.TrimSpace(title), strings.TrimSpace(e.Subtitle)
+		}
+	}
+	return "", ""
+}
@@ -0,0 +1,23 @@
+//go:build ignore
+
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/adoublef-go/isbn"
+	"github.com/adoublef-go/isbn/pgxisbn"
+)
+
+func main() {
+	f, err := os.Create("schema.sql")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	if err := pgxisbn.WriteSchema(f, isbn.DefaultRanges()); err != nil {
+		log.Fatal(err)
+	}
+}
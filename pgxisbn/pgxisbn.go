@@ -0,0 +1,245 @@
+// Package pgxisbn integrates ISBN values with pgx v5 without the
+// database/sql fallback of Value and Scan.
+//
+//	conn, _ := pgx.Connect(ctx, url)
+//	pgxisbn.Register(conn.TypeMap())
+//
+//	rows, _ := conn.Query(ctx, "SELECT isbn FROM books")
+//	isbns, err := pgx.CollectRows(rows, pgx.RowTo[isbn.ISBN])
+package pgxisbn
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/adoublef-go/isbn"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Register teaches m to encode and scan ISBN values for the builtin
+// text (text, varchar, bpchar) and bigint types, including their arrays.
+func Register(m *pgtype.Map) {
+	m.TryWrapEncodePlanFuncs = append([]pgtype.TryWrapEncodePlanFunc{TryWrapEncodePlan}, m.TryWrapEncodePlanFuncs...)
+	m.TryWrapScanPlanFuncs = append([]pgtype.TryWrapScanPlanFunc{TryWrapScanPlan}, m.TryWrapScanPlanFuncs...)
+}
+
+// RegisterType looks up the database type name (e.g. a domain or the isn
+// extension's isbn13) and registers it, along with its array type, using Codec.
+func RegisterType(ctx context.Context, conn *pgx.Conn, name string) error {
+	var oid, arrayOID uint32
+	err := conn.QueryRow(ctx, "SELECT oid, typarray FROM pg_type WHERE oid = $1::text::regtype::oid", name).Scan(&oid, &arrayOID)
+	if err != nil {
+		return err
+	}
+
+	m := conn.TypeMap()
+	t := &pgtype.Type{Name: name, OID: oid, Codec: Codec{}}
+	m.RegisterType(t)
+	if arrayOID != 0 {
+		m.RegisterType(&pgtype.Type{Name: "_" + name, OID: arrayOID, Codec: &pgtype.ArrayCodec{ElementType: t}})
+	}
+	return nil
+}
+
+// Codec is a pgtype.Codec for database types that hold an ISBN as text. It
+// prefers the text format, as the types of the isn extension have no binary
+// input or output functions. The binary format of a domain over text is the
+// same as its text format, so either is scanned.
+type Codec struct{}
+
+func (Codec) FormatSupported(format int16) bool {
+	return format == pgtype.TextFormatCode || format == pgtype.BinaryFormatCode
+}
+
+func (Codec) PreferredFormat() int16 { return pgtype.TextFormatCode }
+
+func (Codec) PlanEncode(m *pgtype.Map, oid uint32, format int16, value any) pgtype.EncodePlan {
+	switch value.(type) {
+	case isbn.ISBN, *isbn.ISBN:
+		return encodePlanText{}
+	case string, []byte:
+		return encodePlanParse{}
+	}
+	return nil
+}
+
+func (Codec) PlanScan(m *pgtype.Map, oid uint32, format int16, target any) pgtype.ScanPlan {
+	switch target.(type) {
+	case *isbn.ISBN:
+		return scanPlanText{}
+	case *string:
+		return scanPlanString{}
+	}
+	return nil
+}
+
+func (c Codec) DecodeDatabaseSQLValue(m *pgtype.Map, oid uint32, format int16, src []byte) (driver.Value, error) {
+	if src == nil {
+		return nil, nil
+	}
+	return string(src), nil
+}
+
+func (c Codec) DecodeValue(m *pgtype.Map, oid uint32, format int16, src []byte) (any, error) {
+	if src == nil {
+		return nil, nil
+	}
+	return isbn.ParseBytes(src)
+}
+
+type encodePlanText struct{}
+
+func (encodePlanText) Encode(value any, buf []byte) ([]byte, error) {
+	switch v := value.(type) {
+	case isbn.ISBN:
+		return append(buf, v[:]...), nil
+	case *isbn.ISBN:
+		if v == nil {
+			return nil, nil
+		}
+		return append(buf, v[:]...), nil
+	}
+	return nil, fmt.Errorf("cannot encode %T as ISBN", value)
+}
+
+type encodePlanParse struct{}
+
+func (encodePlanParse) Encode(value any, buf []byte) (newBuf []byte, err error) {
+	var v isbn.ISBN
+	switch u := value.(type) {
+	case string:
+		v, err = isbn.Parse(u)
+	case []byte:
+		if u == nil {
+			return nil, nil
+		}
+		v, err = isbn.ParseBytes(u)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return append(buf, v[:]...), nil
+}
+
+type scanPlanText struct{}
+
+func (scanPlanText) Scan(src []byte, dst any) (err error) {
+	if src == nil {
+		return fmt.Errorf("cannot scan NULL into %T", dst)
+	}
+	*dst.(*isbn.ISBN), err = isbn.ParseBytes(src)
+	return err
+}
+
+type scanPlanString struct{}
+
+func (scanPlanString) Scan(src []byte, dst any) error {
+	if src == nil {
+		return fmt.Errorf("cannot scan NULL into %T", dst)
+	}
+	*dst.(*string) = string(src)
+	return nil
+}
+
+// TryWrapEncodePlan wraps an ISBN so the builtin text and bigint codecs
+// can encode it. It is installed by Register.
+func TryWrapEncodePlan(value any) (plan pgtype.WrappedEncodePlanNextSetter, nextValue any, ok bool) {
+	switch value.(type) {
+	case isbn.ISBN:
+		return &wrapEncodePlan{}, wrapper{}, true
+	case *isbn.ISBN:
+		return &wrapPtrEncodePlan{}, wrapper{}, true
+	}
+	return nil, nil, false
+}
+
+type wrapEncodePlan struct{ next pgtype.EncodePlan }
+
+func (plan *wrapEncodePlan) SetNext(next pgtype.EncodePlan) { plan.next = next }
+
+func (plan *wrapEncodePlan) Encode(value any, buf []byte) ([]byte, error) {
+	return plan.next.Encode(wrapper(value.(isbn.ISBN)), buf)
+}
+
+type wrapPtrEncodePlan struct{ next pgtype.EncodePlan }
+
+func (plan *wrapPtrEncodePlan) SetNext(next pgtype.EncodePlan) { plan.next = next }
+
+func (plan *wrapPtrEncodePlan) Encode(value any, buf []byte) ([]byte, error) {
+	v := value.(*isbn.ISBN)
+	if v == nil {
+		return nil, nil
+	}
+	return plan.next.Encode(wrapper(*v), buf)
+}
+
+// TryWrapScanPlan wraps an *ISBN target so the builtin text and bigint
+// codecs can scan into it. It is installed by Register.
+func TryWrapScanPlan(target any) (plan pgtype.WrappedScanPlanNextSetter, nextDst any, ok bool) {
+	if _, ok := target.(*isbn.ISBN); ok {
+		return &wrapScanPlan{}, new(wrapper), true
+	}
+	return nil, nil, false
+}
+
+type wrapScanPlan struct{ next pgtype.ScanPlan }
+
+func (plan *wrapScanPlan) SetNext(next pgtype.ScanPlan) { plan.next = next }
+
+func (plan *wrapScanPlan) Scan(src []byte, dst any) error {
+	return plan.next.Scan(src, (*wrapper)(dst.(*isbn.ISBN)))
+}
+
+// wrapper adapts an ISBN to the interfaces the builtin codecs understand.
+type wrapper isbn.ISBN
+
+func (w wrapper) TextValue() (pgtype.Text, error) {
+	return pgtype.Text{String: string(w[:]), Valid: true}, nil
+}
+
+func (w wrapper) Int64Value() (pgtype.Int8, error) {
+	var n int64
+	for _, c := range w {
+		if c < '0' || c > '9' {
+			return pgtype.Int8{}, fmt.Errorf("invalid ISBN %q", w[:])
+		}
+		n = n*10 + int64(c-'0')
+	}
+	return pgtype.Int8{Int64: n, Valid: true}, nil
+}
+
+func (w *wrapper) ScanBytes(b []byte) (err error) {
+	if b == nil {
+		return fmt.Errorf("cannot scan NULL into %T", (*isbn.ISBN)(w))
+	}
+	*(*isbn.ISBN)(w), err = isbn.ParseBytes(b)
+	return err
+}
+
+func (w *wrapper) ScanText(v pgtype.Text) (err error) {
+	if !v.Valid {
+		return fmt.Errorf("cannot scan NULL into %T", (*isbn.ISBN)(w))
+	}
+	*(*isbn.ISBN)(w), err = isbn.Parse(v.String)
+	return err
+}
+
+func (w *wrapper) ScanInt64(v pgtype.Int8) (err error) {
+	if !v.Valid {
+		return fmt.Errorf("cannot scan NULL into %T", (*isbn.ISBN)(w))
+	}
+	if v.Int64 < 0 || v.Int64 > 9999999999999 {
+		return fmt.Errorf("invalid ISBN %d", v.Int64)
+	}
+
+	var b [13]byte
+	n := v.Int64
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i] = byte('0' + n%10)
+		n /= 10
+	}
+	*(*isbn.ISBN)(w), err = isbn.ParseBytes(b[:])
+	return err
+}
@@ -0,0 +1,259 @@
+package pgxisbn
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/adoublef-go/isbn"
+	"github.com/hyphengolang/prelude/testing/is"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestCodec(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	m := pgtype.NewMap()
+	Register(m)
+
+	const customOID = 100000
+	m.RegisterType(&pgtype.Type{Name: "isbn13", OID: customOID, Codec: Codec{}})
+
+	want, _ := isbn.Parse("9780716703440")
+
+	tt := []struct {
+		desc   string
+		oid    uint32
+		format int16
+		data   string
+	}{
+		{"varchar as text", pgtype.VarcharOID, pgtype.TextFormatCode, "9780716703440"},
+		{"varchar as binary", pgtype.VarcharOID, pgtype.BinaryFormatCode, "9780716703440"},
+		{"text as text", pgtype.TextOID, pgtype.TextFormatCode, "9780716703440"},
+		{"bigint as text", pgtype.Int8OID, pgtype.TextFormatCode, "9780716703440"},
+		{"bigint as binary", pgtype.Int8OID, pgtype.BinaryFormatCode, "\x00\x00\x08\xe5\x40\x25\x0e\xd0"},
+		{"isbn13 as text", customOID, pgtype.TextFormatCode, "9780716703440"},
+		{"isbn13 as binary", customOID, pgtype.BinaryFormatCode, "9780716703440"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			buf, err := m.Encode(tc.oid, tc.format, want, nil)
+			is.NoErr(err)                  // encode isbn
+			is.Equal(string(buf), tc.data) // wire format
+
+			var got isbn.ISBN
+			err = m.Scan(tc.oid, tc.format, buf, &got)
+			is.NoErr(err)       // scan isbn
+			is.Equal(got, want) // values are equal
+
+			var ptr *isbn.ISBN
+			err = m.Scan(tc.oid, tc.format, nil, &ptr)
+			is.NoErr(err)       // scan NULL
+			is.True(ptr == nil) // pointer is nil
+		})
+	}
+
+	t.Run("invalid value", func(t *testing.T) {
+		var got isbn.ISBN
+		err := m.Scan(pgtype.VarcharOID, pgtype.TextFormatCode, []byte("9780716703410"), &got)
+		is.Equal(err, isbn.ErrValue) // checksum is validated
+	})
+
+	// the isn types have no binary send and receive functions
+	is.Equal(m.FormatCodeForOID(customOID), int16(pgtype.TextFormatCode)) // parameters and results as text
+}
+
+func TestCodecArray(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	m := pgtype.NewMap()
+	Register(m)
+
+	want := []isbn.ISBN{}
+	for _, s := range []string{"9780716703440", "9781861972712"} {
+		v, _ := isbn.Parse(s)
+		want = append(want, v)
+	}
+
+	tt := []struct {
+		desc   string
+		oid    uint32
+		format int16
+	}{
+		{"varchar[] as text", pgtype.VarcharArrayOID, pgtype.TextFormatCode},
+		{"varchar[] as binary", pgtype.VarcharArrayOID, pgtype.BinaryFormatCode},
+		{"bigint[] as text", pgtype.Int8ArrayOID, pgtype.TextFormatCode},
+		{"bigint[] as binary", pgtype.Int8ArrayOID, pgtype.BinaryFormatCode},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			buf, err := m.Encode(tc.oid, tc.format, want, nil)
+			is.NoErr(err) // encode isbn array
+
+			var got []isbn.ISBN
+			err = m.Scan(tc.oid, tc.format, buf, &got)
+			is.NoErr(err)       // scan isbn array
+			is.Equal(got, want) // values are equal
+		})
+	}
+}
+
+func TestCodecPsql(t *testing.T) {
+	if os.Getenv("POSTGRES_HOST") == "" {
+		t.Skip("POSTGRES_HOST is not set")
+	}
+
+	t.Parallel()
+	is := is.New(t)
+
+	ctx := context.Background()
+	db, err := pgx.Connect(ctx, os.ExpandEnv("postgres://${POSTGRES_USER}:${POSTGRES_PASS}@${POSTGRES_HOST}:${POSTGRES_PORT}"))
+	is.NoErr(err) // connect to psql
+
+	t.Cleanup(func() { db.Close(ctx) })
+	Register(db.TypeMap())
+
+	_, err = db.Exec(ctx, "CREATE TEMP TABLE \"__test__\" (id SERIAL PRIMARY KEY, isbn VARCHAR(13), num BIGINT)")
+	is.NoErr(err) // migrate schema
+
+	want, _ := isbn.Parse("9780716703440")
+	_, err = db.Exec(ctx, "INSERT INTO \"__test__\" (isbn, num) VALUES ($1, $2)", want, want)
+	is.NoErr(err) // insert into table
+
+	rows, _ := db.Query(ctx, "SELECT isbn FROM \"__test__\" UNION ALL SELECT num::text FROM \"__test__\"")
+	got, err := pgx.CollectRows(rows, pgx.RowTo[isbn.ISBN])
+	is.NoErr(err)                          // collect rows
+	is.Equal(got, []isbn.ISBN{want, want}) // values are equal
+
+	var arr []isbn.ISBN
+	err = db.QueryRow(ctx, "SELECT array_agg(num) FROM \"__test__\"").Scan(&arr)
+	is.NoErr(err)                    // scan bigint[]
+	is.Equal(arr, []isbn.ISBN{want}) // values are equal
+}
+
+func TestCodecIsnPsql(t *testing.T) {
+	if os.Getenv("POSTGRES_HOST") == "" {
+		t.Skip("POSTGRES_HOST is not set")
+	}
+
+	t.Parallel()
+	is := is.New(t)
+
+	ctx := context.Background()
+	db, err := pgx.Connect(ctx, os.ExpandEnv("postgres://${POSTGRES_USER}:${POSTGRES_PASS}@${POSTGRES_HOST}:${POSTGRES_PORT}"))
+	is.NoErr(err) // connect to psql
+
+	t.Cleanup(func() { db.Close(ctx) })
+
+	if _, err := db.Exec(ctx, "CREATE EXTENSION IF NOT EXISTS isn"); err != nil {
+		t.Skipf("isn extension is not available: %v", err)
+	}
+	is.NoErr(RegisterType(ctx, db, "isbn13")) // register isbn13 and isbn13[]
+
+	_, err = db.Exec(ctx, "CREATE TEMP TABLE \"__test_isn__\" (id SERIAL PRIMARY KEY, isbn isbn13)")
+	is.NoErr(err) // migrate schema
+
+	want, _ := isbn.Parse("9780716703440")
+	_, err = db.Exec(ctx, "INSERT INTO \"__test_isn__\" (isbn) VALUES ($1)", want)
+	is.NoErr(err) // insert into isbn13 column
+
+	var got isbn.ISBN
+	err = db.QueryRow(ctx, "SELECT isbn FROM \"__test_isn__\" WHERE isbn = $1", want).Scan(&got)
+	is.NoErr(err)       // query isbn13 column, output hyphenated
+	is.Equal(got, want) // values are equal
+
+	var arr []isbn.ISBN
+	err = db.QueryRow(ctx, "SELECT array_agg(isbn) FROM \"__test_isn__\"").Scan(&arr)
+	is.NoErr(err)                    // scan isbn13[]
+	is.Equal(arr, []isbn.ISBN{want}) // values are equal
+}
+
+func TestSchema(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	var buf bytes.Buffer
+	err := WriteSchema(&buf, isbn.DefaultRanges())
+	is.NoErr(err)                  // render schema
+	is.Equal(buf.String(), Schema) // schema.sql is up to date, run go generate
+}
+
+func TestSchemaPsql(t *testing.T) {
+	if os.Getenv("POSTGRES_HOST") == "" {
+		t.Skip("POSTGRES_HOST is not set")
+	}
+
+	t.Parallel()
+	is := is.New(t)
+
+	ctx := context.Background()
+	db, err := pgx.Connect(ctx, os.ExpandEnv("postgres://${POSTGRES_USER}:${POSTGRES_PASS}@${POSTGRES_HOST}:${POSTGRES_PORT}"))
+	is.NoErr(err) // connect to psql
+
+	t.Cleanup(func() { db.Close(ctx) })
+
+	tx, err := db.Begin(ctx)
+	is.NoErr(err) // schema is rolled back after the test
+
+	t.Cleanup(func() { tx.Rollback(ctx) })
+
+	_, err = tx.Exec(ctx, Schema)
+	is.NoErr(err) // migrate schema
+
+	// an ISBN at both ends of every rule of every registration group
+	var data []string
+	for _, g := range isbn.DefaultRanges().Groups {
+		prefix := strings.ReplaceAll(g.Prefix, "-", "")
+		for _, r := range g.Rules {
+			for _, n := range []int{r.Start, r.End} {
+				data = append(data, withCheckDigit((prefix + fmt.Sprintf("%07d", n) + "000000")[:12]))
+			}
+		}
+	}
+
+	for _, s := range data {
+		v, err := isbn.Parse(s)
+		is.NoErr(err) // parse isbn
+
+		var valid bool
+		var hyphen, isbn10 *string
+		err = tx.QueryRow(ctx, "SELECT isbn13_valid($1), isbn_hyphenate($1), isbn_to_isbn10($1)", s).Scan(&valid, &hyphen, &isbn10)
+		is.NoErr(err)  // query functions
+		is.True(valid) // check digit agrees
+
+		if want, err := v.Hyphenate(); err != nil {
+			is.True(hyphen == nil) // unassigned in both
+		} else {
+			is.Equal(*hyphen, want) // hyphenation agrees
+		}
+
+		if want, err := v.ISBN10(); err != nil {
+			is.True(isbn10 == nil) // no isbn 10 in both
+		} else {
+			is.Equal(*isbn10, want) // isbn 10 agrees
+		}
+	}
+
+	for _, s := range []string{"9780716703441", "978071670344X", "978071670344"} {
+		var valid bool
+		err = tx.QueryRow(ctx, "SELECT isbn13_valid($1)", s).Scan(&valid)
+		is.NoErr(err)   // query function
+		is.True(!valid) // invalid in both
+	}
+}
+
+func withCheckDigit(s string) string {
+	var acc int
+	for i := 0; i < 12; i++ {
+		acc += int(s[i]-'0') * (1 + 2*(i%2))
+	}
+	return s + string(rune('0'+(10-acc%10)%10))
+}
@@ -0,0 +1,118 @@
+package pgxisbn
+
+import (
+	"context"
+	_ "embed"
+	"io"
+	"text/template"
+
+	"github.com/adoublef-go/isbn"
+	"github.com/jackc/pgx/v5"
+)
+
+//go:generate go run gen.go
+
+// Schema is a migration that creates the isbn13 domain along with the
+// isbn13_valid, isbn_hyphenate and isbn_to_isbn10 functions. It is
+// generated from isbn.DefaultRanges by WriteSchema.
+//
+//go:embed schema.sql
+var Schema string
+
+// Migrate applies Schema to conn and registers the isbn13 domain with Codec.
+func Migrate(ctx context.Context, conn *pgx.Conn) error {
+	if _, err := conn.Exec(ctx, Schema); err != nil {
+		return err
+	}
+	return RegisterType(ctx, conn, "isbn13")
+}
+
+// WriteSchema writes the migration for the range table rs to w.
+func WriteSchema(w io.Writer, rs *isbn.Ranges) error {
+	return schemaTemplate.Execute(w, rs)
+}
+
+var schemaTemplate = template.Must(template.New("schema").Parse(`-- Code generated by go generate; DO NOT EDIT.
+-- {{.Source}} range message {{.Serial}} ({{.Date}})
+
+CREATE OR REPLACE FUNCTION isbn13_valid(s text) RETURNS boolean
+LANGUAGE plpgsql IMMUTABLE STRICT AS $$
+DECLARE
+	acc integer := 0;
+BEGIN
+	IF s !~ '^[0-9]{13}$' THEN
+		RETURN false;
+	END IF;
+	FOR i IN 1..13 LOOP
+		acc := acc + substr(s, i, 1)::integer * (CASE WHEN i % 2 = 0 THEN 3 ELSE 1 END);
+	END LOOP;
+	RETURN acc % 10 = 0;
+END
+$$;
+
+DO $$
+BEGIN
+	CREATE DOMAIN isbn13 AS text CHECK (isbn13_valid(VALUE));
+EXCEPTION
+	WHEN duplicate_object THEN NULL;
+END
+$$;
+
+-- isbn_range_length returns the length of the part following prefix, given
+-- the seven digits n that follow it. Zero marks a range that is not in use.
+CREATE OR REPLACE FUNCTION isbn_range_length(prefix text, n integer) RETURNS integer
+LANGUAGE sql IMMUTABLE STRICT AS $$
+SELECT CASE prefix
+{{- range $g := .Prefixes}}
+	WHEN '{{$g.Prefix}}' THEN CASE
+{{- range $g.Rules}}
+		WHEN n BETWEEN {{.Start}} AND {{.End}} THEN {{.Length}}
+{{- end}}
+		ELSE 0 END
+{{- end}}
+{{- range $g := .Groups}}
+	WHEN '{{$g.Prefix}}' THEN CASE
+{{- range $g.Rules}}
+		WHEN n BETWEEN {{.Start}} AND {{.End}} THEN {{.Length}}
+{{- end}}
+		ELSE 0 END
+{{- end}}
+	ELSE 0
+END
+$$;
+
+CREATE OR REPLACE FUNCTION isbn_hyphenate(s isbn13) RETURNS text
+LANGUAGE plpgsql IMMUTABLE STRICT AS $$
+DECLARE
+	g integer;
+	r integer;
+BEGIN
+	g := isbn_range_length(substr(s, 1, 3), substr(s, 4, 7)::integer);
+	IF g = 0 THEN
+		RETURN NULL;
+	END IF;
+	r := isbn_range_length(substr(s, 1, 3) || '-' || substr(s, 4, g), rpad(substr(s, 4 + g, 9 - g), 7, '0')::integer);
+	IF r = 0 OR g + r >= 9 THEN
+		RETURN NULL;
+	END IF;
+	RETURN substr(s, 1, 3) || '-' || substr(s, 4, g) || '-' || substr(s, 4 + g, r) || '-' || substr(s, 4 + g + r, 9 - g - r) || '-' || substr(s, 13, 1);
+END
+$$;
+
+CREATE OR REPLACE FUNCTION isbn_to_isbn10(s isbn13) RETURNS text
+LANGUAGE plpgsql IMMUTABLE STRICT AS $$
+DECLARE
+	acc integer := 0;
+	c integer;
+BEGIN
+	IF substr(s, 1, 3) <> '978' THEN
+		RETURN NULL;
+	END IF;
+	FOR i IN 1..9 LOOP
+		acc := acc + substr(s, 3 + i, 1)::integer * (11 - i);
+	END LOOP;
+	c := (11 - acc % 11) % 11;
+	RETURN substr(s, 4, 9) || (CASE WHEN c = 10 THEN 'X' ELSE c::text END);
+END
+$$;
+`))
@@ -0,0 +1,217 @@
+-- Code generated by go generate; DO NOT EDIT.
+-- International ISBN Agency range message trimmed (Mon, 2 Sep 2024 10:17:35 BST)
+
+CREATE OR REPLACE FUNCTION isbn13_valid(s text) RETURNS boolean
+LANGUAGE plpgsql IMMUTABLE STRICT AS $$
+DECLARE
+	acc integer := 0;
+BEGIN
+	IF s !~ '^[0-9]{13}$' THEN
+		RETURN false;
+	END IF;
+	FOR i IN 1..13 LOOP
+		acc := acc + substr(s, i, 1)::integer * (CASE WHEN i % 2 = 0 THEN 3 ELSE 1 END);
+	END LOOP;
+	RETURN acc % 10 = 0;
+END
+$$;
+
+DO $$
+BEGIN
+	CREATE DOMAIN isbn13 AS text CHECK (isbn13_valid(VALUE));
+EXCEPTION
+	WHEN duplicate_object THEN NULL;
+END
+$$;
+
+-- isbn_range_length returns the length of the part following prefix, given
+-- the seven digits n that follow it. Zero marks a range that is not in use.
+CREATE OR REPLACE FUNCTION isbn_range_length(prefix text, n integer) RETURNS integer
+LANGUAGE sql IMMUTABLE STRICT AS $$
+SELECT CASE prefix
+	WHEN '978' THEN CASE
+		WHEN n BETWEEN 0 AND 5999999 THEN 1
+		WHEN n BETWEEN 6000000 AND 6499999 THEN 3
+		WHEN n BETWEEN 6500000 AND 6599999 THEN 2
+		WHEN n BETWEEN 6600000 AND 6999999 THEN 0
+		WHEN n BETWEEN 7000000 AND 7999999 THEN 1
+		WHEN n BETWEEN 8000000 AND 9499999 THEN 2
+		WHEN n BETWEEN 9500000 AND 9899999 THEN 3
+		WHEN n BETWEEN 9900000 AND 9989999 THEN 4
+		WHEN n BETWEEN 9990000 AND 9999999 THEN 5
+		ELSE 0 END
+	WHEN '979' THEN CASE
+		WHEN n BETWEEN 0 AND 999999 THEN 0
+		WHEN n BETWEEN 1000000 AND 1299999 THEN 2
+		WHEN n BETWEEN 1300000 AND 7999999 THEN 0
+		WHEN n BETWEEN 8000000 AND 8999999 THEN 1
+		WHEN n BETWEEN 9000000 AND 9999999 THEN 0
+		ELSE 0 END
+	WHEN '978-0' THEN CASE
+		WHEN n BETWEEN 0 AND 1999999 THEN 2
+		WHEN n BETWEEN 2000000 AND 2279999 THEN 3
+		WHEN n BETWEEN 2280000 AND 2289999 THEN 4
+		WHEN n BETWEEN 2290000 AND 6479999 THEN 3
+		WHEN n BETWEEN 6480000 AND 6489999 THEN 7
+		WHEN n BETWEEN 6490000 AND 6999999 THEN 3
+		WHEN n BETWEEN 7000000 AND 8499999 THEN 4
+		WHEN n BETWEEN 8500000 AND 8999999 THEN 5
+		WHEN n BETWEEN 9000000 AND 9499999 THEN 6
+		WHEN n BETWEEN 9500000 AND 9999999 THEN 7
+		ELSE 0 END
+	WHEN '978-1' THEN CASE
+		WHEN n BETWEEN 0 AND 999999 THEN 2
+		WHEN n BETWEEN 1000000 AND 3999999 THEN 3
+		WHEN n BETWEEN 4000000 AND 5499999 THEN 4
+		WHEN n BETWEEN 5500000 AND 7319999 THEN 5
+		WHEN n BETWEEN 7320000 AND 7399999 THEN 7
+		WHEN n BETWEEN 7400000 AND 7749999 THEN 5
+		WHEN n BETWEEN 7750000 AND 7753999 THEN 7
+		WHEN n BETWEEN 7754000 AND 8697999 THEN 5
+		WHEN n BETWEEN 8698000 AND 9729999 THEN 6
+		WHEN n BETWEEN 9730000 AND 9877999 THEN 4
+		WHEN n BETWEEN 9878000 AND 9989999 THEN 6
+		WHEN n BETWEEN 9990000 AND 9999999 THEN 7
+		ELSE 0 END
+	WHEN '978-2' THEN CASE
+		WHEN n BETWEEN 0 AND 1999999 THEN 2
+		WHEN n BETWEEN 2000000 AND 3499999 THEN 3
+		WHEN n BETWEEN 3500000 AND 3999999 THEN 5
+		WHEN n BETWEEN 4000000 AND 6999999 THEN 3
+		WHEN n BETWEEN 7000000 AND 8399999 THEN 4
+		WHEN n BETWEEN 8400000 AND 8999999 THEN 5
+		WHEN n BETWEEN 9000000 AND 9499999 THEN 6
+		WHEN n BETWEEN 9500000 AND 9999999 THEN 7
+		ELSE 0 END
+	WHEN '978-3' THEN CASE
+		WHEN n BETWEEN 0 AND 299999 THEN 2
+		WHEN n BETWEEN 300000 AND 339999 THEN 3
+		WHEN n BETWEEN 340000 AND 369999 THEN 4
+		WHEN n BETWEEN 370000 AND 399999 THEN 5
+		WHEN n BETWEEN 400000 AND 1999999 THEN 2
+		WHEN n BETWEEN 2000000 AND 6999999 THEN 3
+		WHEN n BETWEEN 7000000 AND 8499999 THEN 4
+		WHEN n BETWEEN 8500000 AND 8999999 THEN 5
+		WHEN n BETWEEN 9000000 AND 9499999 THEN 6
+		WHEN n BETWEEN 9500000 AND 9539999 THEN 7
+		WHEN n BETWEEN 9540000 AND 9699999 THEN 5
+		WHEN n BETWEEN 9700000 AND 9899999 THEN 7
+		WHEN n BETWEEN 9900000 AND 9949999 THEN 5
+		WHEN n BETWEEN 9950000 AND 9999999 THEN 5
+		ELSE 0 END
+	WHEN '978-4' THEN CASE
+		WHEN n BETWEEN 0 AND 1999999 THEN 2
+		WHEN n BETWEEN 2000000 AND 6999999 THEN 3
+		WHEN n BETWEEN 7000000 AND 8499999 THEN 4
+		WHEN n BETWEEN 8500000 AND 8999999 THEN 5
+		WHEN n BETWEEN 9000000 AND 9499999 THEN 6
+		WHEN n BETWEEN 9500000 AND 9999999 THEN 7
+		ELSE 0 END
+	WHEN '978-5' THEN CASE
+		WHEN n BETWEEN 0 AND 1999999 THEN 2
+		WHEN n BETWEEN 2000000 AND 6999999 THEN 3
+		WHEN n BETWEEN 7000000 AND 8499999 THEN 4
+		WHEN n BETWEEN 8500000 AND 8999999 THEN 5
+		WHEN n BETWEEN 9000000 AND 9499999 THEN 6
+		WHEN n BETWEEN 9500000 AND 9999999 THEN 7
+		ELSE 0 END
+	WHEN '978-7' THEN CASE
+		WHEN n BETWEEN 0 AND 999999 THEN 2
+		WHEN n BETWEEN 1000000 AND 4999999 THEN 3
+		WHEN n BETWEEN 5000000 AND 7999999 THEN 4
+		WHEN n BETWEEN 8000000 AND 8999999 THEN 5
+		WHEN n BETWEEN 9000000 AND 9999999 THEN 6
+		ELSE 0 END
+	WHEN '978-65' THEN CASE
+		WHEN n BETWEEN 0 AND 199999 THEN 2
+		WHEN n BETWEEN 200000 AND 2499999 THEN 0
+		WHEN n BETWEEN 2500000 AND 2999999 THEN 3
+		WHEN n BETWEEN 3000000 AND 3029999 THEN 3
+		WHEN n BETWEEN 3030000 AND 4999999 THEN 0
+		WHEN n BETWEEN 5000000 AND 5129999 THEN 4
+		WHEN n BETWEEN 5130000 AND 5349999 THEN 0
+		WHEN n BETWEEN 5350000 AND 5999999 THEN 4
+		WHEN n BETWEEN 6000000 AND 8499999 THEN 0
+		WHEN n BETWEEN 8500000 AND 8999999 THEN 5
+		WHEN n BETWEEN 9000000 AND 9024999 THEN 6
+		WHEN n BETWEEN 9025000 AND 9799999 THEN 0
+		WHEN n BETWEEN 9800000 AND 9999999 THEN 6
+		ELSE 0 END
+	WHEN '978-84' THEN CASE
+		WHEN n BETWEEN 0 AND 999999 THEN 2
+		WHEN n BETWEEN 1000000 AND 1049999 THEN 5
+		WHEN n BETWEEN 1050000 AND 1199999 THEN 4
+		WHEN n BETWEEN 1200000 AND 1299999 THEN 6
+		WHEN n BETWEEN 1300000 AND 1399999 THEN 4
+		WHEN n BETWEEN 1400000 AND 1499999 THEN 3
+		WHEN n BETWEEN 1500000 AND 1999999 THEN 5
+		WHEN n BETWEEN 2000000 AND 6999999 THEN 3
+		WHEN n BETWEEN 7000000 AND 8499999 THEN 4
+		WHEN n BETWEEN 8500000 AND 8999999 THEN 5
+		WHEN n BETWEEN 9000000 AND 9199999 THEN 4
+		WHEN n BETWEEN 9200000 AND 9239999 THEN 6
+		WHEN n BETWEEN 9240000 AND 9299999 THEN 5
+		WHEN n BETWEEN 9300000 AND 9499999 THEN 6
+		WHEN n BETWEEN 9500000 AND 9699999 THEN 5
+		WHEN n BETWEEN 9700000 AND 9999999 THEN 4
+		ELSE 0 END
+	WHEN '978-99901' THEN CASE
+		WHEN n BETWEEN 0 AND 4999999 THEN 2
+		WHEN n BETWEEN 5000000 AND 7999999 THEN 3
+		WHEN n BETWEEN 8000000 AND 9999999 THEN 0
+		ELSE 0 END
+	WHEN '979-10' THEN CASE
+		WHEN n BETWEEN 0 AND 1999999 THEN 2
+		WHEN n BETWEEN 2000000 AND 6999999 THEN 3
+		WHEN n BETWEEN 7000000 AND 8999999 THEN 4
+		WHEN n BETWEEN 9000000 AND 9759999 THEN 5
+		WHEN n BETWEEN 9760000 AND 9999999 THEN 6
+		ELSE 0 END
+	WHEN '979-8' THEN CASE
+		WHEN n BETWEEN 0 AND 1999999 THEN 0
+		WHEN n BETWEEN 2000000 AND 2299999 THEN 3
+		WHEN n BETWEEN 2300000 AND 3499999 THEN 0
+		WHEN n BETWEEN 3500000 AND 8499999 THEN 4
+		WHEN n BETWEEN 8500000 AND 8999999 THEN 5
+		WHEN n BETWEEN 9000000 AND 9849999 THEN 0
+		WHEN n BETWEEN 9850000 AND 9899999 THEN 7
+		WHEN n BETWEEN 9900000 AND 9999999 THEN 0
+		ELSE 0 END
+	ELSE 0
+END
+$$;
+
+CREATE OR REPLACE FUNCTION isbn_hyphenate(s isbn13) RETURNS text
+LANGUAGE plpgsql IMMUTABLE STRICT AS $$
+DECLARE
+	g integer;
+	r integer;
+BEGIN
+	g := isbn_range_length(substr(s, 1, 3), substr(s, 4, 7)::integer);
+	IF g = 0 THEN
+		RETURN NULL;
+	END IF;
+	r := isbn_range_length(substr(s, 1, 3) || '-' || substr(s, 4, g), rpad(substr(s, 4 + g, 9 - g), 7, '0')::integer);
+	IF r = 0 OR g + r >= 9 THEN
+		RETURN NULL;
+	END IF;
+	RETURN substr(s, 1, 3) || '-' || substr(s, 4, g) || '-' || substr(s, 4 + g, r) || '-' || substr(s, 4 + g + r, 9 - g - r) || '-' || substr(s, 13, 1);
+END
+$$;
+
+CREATE OR REPLACE FUNCTION isbn_to_isbn10(s isbn13) RETURNS text
+LANGUAGE plpgsql IMMUTABLE STRICT AS $$
+DECLARE
+	acc integer := 0;
+	c integer;
+BEGIN
+	IF substr(s, 1, 3) <> '978' THEN
+		RETURN NULL;
+	END IF;
+	FOR i IN 1..9 LOOP
+		acc := acc + substr(s, 3 + i, 1)::integer * (11 - i);
+	END LOOP;
+	c := (11 - acc % 11) % 11;
+	RETURN substr(s, 4, 9) || (CASE WHEN c = 10 THEN 'X' ELSE c::text END);
+END
+$$;
@@ -0,0 +1,290 @@
+package isbn
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+//go:generate go run gen.go
+
+// rangeMessage is the RangeMessage.xml of the International ISBN Agency, as
+// published. Run go generate to replace it with the current message, then
+// go generate ./pgxisbn to update the SQL schema built from it.
+//
+//go:embed RangeMessage.xml
+var rangeMessage []byte
+
+var defaultRanges = func() *Ranges {
+	rs, err := ParseRanges(bytes.NewReader(rangeMessage))
+	if err != nil {
+		panic(err)
+	}
+	return rs
+}()
+
+// currentRanges holds the active *Ranges.
+var currentRanges atomic.Value
+
+func init() { currentRanges.Store(defaultRanges) }
+
+// DefaultRanges returns the range table embedded in the package.
+func DefaultRanges() *Ranges { return defaultRanges }
+
+// CurrentRanges returns the active range table. Parsing, hyphenation and
+// agency lookups all read from it.
+func CurrentRanges() *Ranges { return currentRanges.Load().(*Ranges) }
+
+// SetRanges atomically replaces the active range table with rs. It is safe
+// to call while other goroutines are reading from the table. A nil rs
+// restores the embedded table.
+func SetRanges(rs *Ranges) {
+	if rs == nil {
+		rs = defaultRanges
+	}
+	currentRanges.Store(rs)
+}
+
+// ReloadRanges loads the RangeMessage.xml file name and makes it the active
+// range table. The active table is left untouched if the file is invalid.
+func ReloadRanges(name string) error {
+	rs, err := LoadRanges(name)
+	if err != nil {
+		return err
+	}
+	SetRanges(rs)
+	return nil
+}
+
+// Ranges is the range table published by the International ISBN Agency
+// as RangeMessage.xml. It splits an ISBN into its registration group,
+// registrant, publication and check digit. A Ranges must not be modified
+// once it is in use.
+type Ranges struct {
+	// Source is the publisher of the message, e.g. "International ISBN
+	// Agency".
+	Source string
+	// Serial is the serial number of the message.
+	Serial string
+	// Date is the date of the message as published, see Time.
+	Date string
+
+	// Prefixes holds the rules for the length of the registration group
+	// of each EAN.UCC prefix (e.g. "978").
+	Prefixes []RangeGroup
+	// Groups holds the rules for the length of the registrant of each
+	// registration group (e.g. "978-0").
+	Groups []RangeGroup
+
+	index map[string]*RangeGroup
+}
+
+// RangeGroup is the set of rules for a single prefix.
+type RangeGroup struct {
+	Prefix string
+	Agency string
+	Rules  []RangeRule
+}
+
+// RangeRule maps the seven digits following a prefix to a length.
+// A Length of zero marks a range that is not in use.
+type RangeRule struct {
+	Start, End int
+	Length     int
+}
+
+// agencyZones are the offsets of the zones the agency dates its messages in.
+// time.Parse gives a zero offset to an abbreviation it does not know, which
+// is BST unless the local zone is Europe/London, so Time corrects BST here.
+var agencyZones = map[string]int{
+	"GMT": 0,
+	"UTC": 0,
+	"BST": 60 * 60,
+}
+
+// Time parses Date, which is of the form "Mon, 2 Sep 2024 10:17:35 BST".
+func (rs *Ranges) Time() (time.Time, error) {
+	t, err := time.Parse("Mon, 2 Jan 2006 15:04:05 MST", rs.Date)
+	if err != nil {
+		return t, err
+	}
+	zone, offset := t.Zone()
+	if known, ok := agencyZones[zone]; ok && known != offset {
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.FixedZone(zone, known))
+	}
+	return t, nil
+}
+
+func (g *RangeGroup) length(digits string) int {
+	n, _ := strconv.Atoi(digits)
+	for _, r := range g.Rules {
+		if n >= r.Start && n <= r.End {
+			return r.Length
+		}
+	}
+	return 0
+}
+
+// split returns the lengths of the registration group and registrant of isbn,
+// along with the rules of its registration group.
+func (rs *Ranges) split(isbn ISBN) (group, registrant int, g *RangeGroup, err error) {
+	s := isbn.String()
+
+	prefix, ok := rs.index[s[:3]]
+	if !ok {
+		return 0, 0, nil, ErrGroup
+	}
+	if group = prefix.length(s[3:10]); group == 0 {
+		return 0, 0, nil, ErrGroup
+	}
+
+	g, ok = rs.index[s[:3]+"-"+s[3:3+group]]
+	if !ok {
+		return 0, 0, nil, ErrGroup
+	}
+	digits := (s[3+group:12] + "000000")[:7]
+	if registrant = g.length(digits); registrant == 0 || group+registrant >= 9 {
+		return 0, 0, nil, ErrRegistrant
+	}
+	return group, registrant, g, nil
+}
+
+// Parts is an ISBN 13 split into its elements.
+type Parts struct {
+	Prefix      string
+	Group       string
+	Registrant  string
+	Publication string
+	Check       string
+
+	// Agency is the name of the agency of the registration group.
+	Agency string
+}
+
+// String returns the parts joined by hyphens (XXX-X-XXXX-XXXX-X).
+func (p Parts) String() string {
+	return p.Prefix + "-" + p.Group + "-" + p.Registrant + "-" + p.Publication + "-" + p.Check
+}
+
+// Parts splits isbn into its elements, according to rs.
+func (rs *Ranges) Parts(isbn ISBN) (Parts, error) {
+	group, registrant, g, err := rs.split(isbn)
+	if err != nil {
+		return Parts{}, err
+	}
+
+	s := isbn.String()
+	i, j := 3+group, 3+group+registrant
+	return Parts{
+		Prefix:      s[:3],
+		Group:       s[3:i],
+		Registrant:  s[i:j],
+		Publication: s[j:12],
+		Check:       s[12:],
+		Agency:      g.Agency,
+	}, nil
+}
+
+// Hyphenate returns isbn with hyphens between its parts, according to rs.
+func (rs *Ranges) Hyphenate(isbn ISBN) (string, error) {
+	p, err := rs.Parts(isbn)
+	if err != nil {
+		return "", err
+	}
+	return p.String(), nil
+}
+
+type xmlRangeMessage struct {
+	Source   string          `xml:"MessageSource"`
+	Serial   string          `xml:"MessageSerialNumber"`
+	Date     string          `xml:"MessageDate"`
+	Prefixes []xmlRangeGroup `xml:"EAN.UCCPrefixes>EAN.UCC"`
+	Groups   []xmlRangeGroup `xml:"RegistrationGroups>Group"`
+}
+
+type xmlRangeGroup struct {
+	Prefix string `xml:"Prefix"`
+	Agency string `xml:"Agency"`
+	Rules  []struct {
+		Range  string `xml:"Range"`
+		Length int    `xml:"Length"`
+	} `xml:"Rules>Rule"`
+}
+
+// LoadRanges reads the RangeMessage.xml file name.
+func LoadRanges(name string) (*Ranges, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ParseRanges(f)
+}
+
+// ParseRanges reads a RangeMessage.xml, as published by the International
+// ISBN Agency, from r.
+func ParseRanges(r io.Reader) (*Ranges, error) {
+	var msg xmlRangeMessage
+	if err := xml.NewDecoder(r).Decode(&msg); err != nil {
+		return nil, err
+	}
+
+	rs := &Ranges{
+		Source: msg.Source,
+		Serial: msg.Serial,
+		Date:   msg.Date,
+		index:  make(map[string]*RangeGroup),
+	}
+
+	convert := func(gs []xmlRangeGroup) ([]RangeGroup, error) {
+		out := make([]RangeGroup, len(gs))
+		for i, g := range gs {
+			out[i] = RangeGroup{Prefix: g.Prefix, Agency: g.Agency}
+			for _, rule := range g.Rules {
+				start, end, ok := strings.Cut(rule.Range, "-")
+				if !ok || len(start) != 7 || len(end) != 7 {
+					return nil, fmt.Errorf("invalid range %q for prefix %s", rule.Range, g.Prefix)
+				}
+				var rr RangeRule
+				var err error
+				if rr.Start, err = strconv.Atoi(start); err != nil {
+					return nil, err
+				}
+				if rr.End, err = strconv.Atoi(end); err != nil {
+					return nil, err
+				}
+				rr.Length = rule.Length
+				out[i].Rules = append(out[i].Rules, rr)
+			}
+		}
+		return out, nil
+	}
+
+	if len(msg.Prefixes) == 0 {
+		return nil, fmt.Errorf("range message has no EAN.UCC prefixes")
+	}
+
+	var err error
+	if rs.Prefixes, err = convert(msg.Prefixes); err != nil {
+		return nil, err
+	}
+	if rs.Groups, err = convert(msg.Groups); err != nil {
+		return nil, err
+	}
+
+	for i := range rs.Prefixes {
+		rs.index[rs.Prefixes[i].Prefix] = &rs.Prefixes[i]
+	}
+	for i := range rs.Groups {
+		rs.index[rs.Groups[i].Prefix] = &rs.Groups[i]
+	}
+	return rs, nil
+}
@@ -0,0 +1,79 @@
+package reconcile
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/adoublef-go/isbn"
+)
+
+// MemoryStore is a Store held in memory. It is safe for concurrent use.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	records map[isbn.ISBN][]Record
+	invalid []Record
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[isbn.ISBN][]Record)}
+}
+
+// Add implements Store.
+func (m *MemoryStore) Add(_ context.Context, records ...Record) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, rec := range records {
+		v, ok, err := parse(rec)
+		switch {
+		case !ok:
+		case err != nil:
+			m.invalid = append(m.invalid, rec)
+		default:
+			m.records[v] = append(m.records[v], rec)
+		}
+	}
+	return nil
+}
+
+// Clusters implements Store.
+func (m *MemoryStore) Clusters(ctx context.Context, fn func(Cluster) error) error {
+	m.mu.RLock()
+	var clusters []Cluster
+	for v, records := range m.records {
+		if len(records) > 1 {
+			clusters = append(clusters, cluster(v, append([]Record(nil), records...)))
+		}
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(clusters, func(i, j int) bool { return isbn.Less(clusters[i].ISBN, clusters[j].ISBN) })
+	for _, c := range clusters {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Invalid implements Store.
+func (m *MemoryStore) Invalid(ctx context.Context, fn func(Record) error) error {
+	m.mu.RLock()
+	invalid := append([]Record(nil), m.invalid...)
+	m.mu.RUnlock()
+
+	for _, rec := range invalid {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+// Package reconcile finds the records of a catalogue that describe the same
+// book, and the ISBNs that are attached to more than one title.
+//
+// Records are added to a Store as they are read, and Reconcile then reports
+// the records that share an ISBN, whichever form it was written in:
+//
+//	s := reconcile.NewMemoryStore()
+//	for records.Next() {
+//		s.Add(ctx, reconcile.Record{ID: id, ISBN: value, Title: title})
+//	}
+//	report, err := reconcile.Reconcile(ctx, s)
+//
+// A MemoryStore holds every record in memory. For inputs that do not fit,
+// a SQLStore holds them in a database/sql table and reads the clusters back
+// one at a time.
+package reconcile
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"github.com/adoublef-go/isbn"
+)
+
+// Record is a catalogue record, with the ISBN as written in the catalogue.
+type Record struct {
+	ID    string
+	ISBN  string
+	Title string
+}
+
+// Cluster is the records that share an ISBN.
+type Cluster struct {
+	ISBN isbn.ISBN
+	// Records are in the order they were added.
+	Records []Record
+	// Titles are the distinct titles of Records, as compared by TitleKey, in
+	// the order they were added. Records with no title are not counted.
+	Titles []string
+}
+
+// Conflict reports whether the records of c have different titles.
+func (c Cluster) Conflict() bool { return len(c.Titles) > 1 }
+
+// Store holds the records being reconciled.
+type Store interface {
+	// Add adds records to the store. Records with an empty ISBN are ignored.
+	Add(ctx context.Context, records ...Record) error
+	// Clusters calls fn with each ISBN held by more than one record, in
+	// ascending order of ISBN, stopping at the first error.
+	Clusters(ctx context.Context, fn func(Cluster) error) error
+	// Invalid calls fn with each record whose ISBN does not parse, in the
+	// order they were added, stopping at the first error.
+	Invalid(ctx context.Context, fn func(Record) error) error
+}
+
+// Report is the result of reconciling a Store.
+type Report struct {
+	// Duplicates are the clusters of records with the same title.
+	Duplicates []Cluster
+	// Conflicts are the clusters of records with different titles.
+	Conflicts []Cluster
+	// Invalid are the records whose ISBN does not parse.
+	Invalid []Record
+}
+
+// Reconcile reports the duplicates, conflicts and invalid records of s.
+func Reconcile(ctx context.Context, s Store) (*Report, error) {
+	var r Report
+	err := s.Clusters(ctx, func(c Cluster) error {
+		if c.Conflict() {
+			r.Conflicts = append(r.Conflicts, c)
+		} else {
+			r.Duplicates = append(r.Duplicates, c)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.Invalid(ctx, func(rec Record) error {
+		r.Invalid = append(r.Invalid, rec)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// TitleKey returns the form of title that is compared to tell titles apart.
+// Case, punctuation and spacing are ignored, so that "Fantastic Mr. Fox" and
+// "fantastic mr fox" are the same title.
+func TitleKey(title string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range title {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteRune(unicode.ToLower(r))
+		case r == '\'' || r == '’':
+			// "Panda's" is not two words
+		default:
+			space = true
+		}
+	}
+	return b.String()
+}
+
+// parse returns the ISBN of a record, and false if it has none.
+func parse(rec Record) (v isbn.ISBN, ok bool, err error) {
+	s := strings.TrimSpace(rec.ISBN)
+	if s == "" {
+		return v, false, nil
+	}
+	v, err = isbn.Parse(s)
+	return v, true, err
+}
+
+// cluster returns the cluster of records sharing v.
+func cluster(v isbn.ISBN, records []Record) Cluster {
+	c := Cluster{ISBN: v, Records: records}
+	seen := map[string]bool{}
+	for _, rec := range records {
+		key := TitleKey(rec.Title)
+		if key != "" && !seen[key] {
+			seen[key] = true
+			c.Titles = append(c.Titles, rec.Title)
+		}
+	}
+	return c
+}
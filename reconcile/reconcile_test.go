@@ -0,0 +1,148 @@
+package reconcile
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/adoublef-go/isbn"
+	"github.com/adoublef-go/isbn/isbntest"
+	"github.com/hyphengolang/prelude/testing/is"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var records = []Record{
+	{"1", "0140328726", "Fantastic Mr Fox"},
+	{"2", "0716703440", "The Panda's Thumb"},
+	{"3", "978-0-14-032872-1", "Fantastic Mr. Fox"},
+	{"4", "9780716703440", "The Mismeasure of Man"},
+	{"5", "0716703441", "The Panda's Thumb"},
+	{"6", "", "Untitled"},
+	{"7", "9782070409228", "Les Misérables"},
+	{"8", "9780140328721", ""},
+	{"9", "0-7167-0344-0", "the pandas thumb"},
+}
+
+func stores(t *testing.T) []struct {
+	desc  string
+	store Store
+} {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	s := NewSQLStore(db)
+	if err := s.Migrate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return []struct {
+		desc  string
+		store Store
+	}{
+		{"memory", NewMemoryStore()},
+		{"sql", s},
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	for _, tc := range stores(t) {
+		t.Run(tc.desc, func(t *testing.T) {
+			ctx := context.Background()
+
+			// added as a stream of batches
+			is.NoErr(tc.store.Add(ctx, records[:4]...)) // adding records
+			is.NoErr(tc.store.Add(ctx, records[4:]...)) // adding more records
+
+			r, err := Reconcile(ctx, tc.store)
+			is.NoErr(err) // reconciling records
+
+			is.Equal(len(r.Duplicates), 1)                                                  // one duplicate cluster
+			is.Equal(r.Duplicates[0].ISBN.String(), "9780140328721")                        // fox isbn
+			is.Equal(r.Duplicates[0].Records, []Record{records[0], records[2], records[7]}) // isbn 10 and 13 records
+			is.Equal(r.Duplicates[0].Titles, []string{"Fantastic Mr Fox"})                  // one title
+			is.True(!r.Duplicates[0].Conflict())                                            // no conflict
+
+			is.Equal(len(r.Conflicts), 1)                                                           // one conflict
+			is.Equal(r.Conflicts[0].ISBN.String(), "9780716703440")                                 // panda isbn
+			is.Equal(r.Conflicts[0].Records, []Record{records[1], records[3], records[8]})          // records sharing isbn
+			is.Equal(r.Conflicts[0].Titles, []string{"The Panda's Thumb", "The Mismeasure of Man"}) // different titles
+
+			is.Equal(r.Invalid, []Record{records[4]}) // invalid check digit
+		})
+	}
+}
+
+func TestReconcileLarge(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	for _, tc := range stores(t) {
+		t.Run(tc.desc, func(t *testing.T) {
+			ctx := context.Background()
+
+			// every book is catalogued under its isbn 10 and isbn 13
+			g := isbntest.New(1)
+			seen := isbn.NewISBNSet()
+			var batch []Record
+			for i := 0; len(seen) < 500; i++ {
+				isbn10 := g.ISBN10()
+				v, err := isbn.Parse(isbn10)
+				is.NoErr(err) // generated isbn 10
+				if seen.Contains(v) {
+					continue
+				}
+				seen.Add(v)
+				batch = append(batch,
+					Record{fmt.Sprintf("a%d", i), isbn10, fmt.Sprintf("Book %d", i)},
+					Record{fmt.Sprintf("b%d", i), v.String(), fmt.Sprintf("book %d", i)},
+				)
+			}
+			is.NoErr(tc.store.Add(ctx, batch...)) // adding records
+
+			n := 0
+			err := tc.store.Clusters(ctx, func(c Cluster) error {
+				is.Equal(len(c.Records), 2) // isbn 10 and 13
+				is.True(!c.Conflict())      // same title
+				n++
+				return nil
+			})
+			is.NoErr(err)    // reading clusters
+			is.Equal(n, 500) // every book duplicated
+
+			stop := errors.New("stop")
+			err = tc.store.Clusters(ctx, func(Cluster) error { return stop })
+			is.True(errors.Is(err, stop)) // stops at first error
+		})
+	}
+}
+
+func TestTitleKey(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	tt := []struct {
+		desc  string
+		title string
+		key   string
+	}{
+		{"case and punctuation", "Fantastic Mr. Fox", "fantastic mr fox"},
+		{"apostrophe", "The Panda’s Thumb", "the pandas thumb"},
+		{"spacing", "  Les   Misérables ", "les misérables"},
+		{"subtitle", "Ever Since Darwin: Reflections", "ever since darwin reflections"},
+		{"empty", " - ", ""},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			is.Equal(TitleKey(tc.title), tc.key) // title key
+		})
+	}
+}
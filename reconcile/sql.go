@@ -0,0 +1,165 @@
+package reconcile
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"github.com/adoublef-go/isbn"
+)
+
+// SQLStore is a Store held in a database/sql table, for inputs too large to
+// reconcile in memory. The clusters are read back in ISBN order, so only one
+// is held in memory at a time. The statements it uses are understood by both
+// SQLite and PostgreSQL.
+//
+// A SQLStore numbers the records it adds to keep them in order, so a table
+// must only be added to by one SQLStore at a time.
+type SQLStore struct {
+	db *sql.DB
+
+	mu  sync.Mutex
+	seq int64
+	// loaded is set once seq has been read from the table.
+	loaded bool
+}
+
+// NewSQLStore returns a SQLStore using db. Migrate creates its table.
+func NewSQLStore(db *sql.DB) *SQLStore { return &SQLStore{db: db} }
+
+// Migrate creates the isbn_reconcile table if it does not already exist.
+func (s *SQLStore) Migrate(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `
+CREATE TABLE IF NOT EXISTS isbn_reconcile (
+	seq       BIGINT PRIMARY KEY,
+	record_id TEXT NOT NULL,
+	value     TEXT NOT NULL,
+	title     TEXT NOT NULL,
+	isbn      TEXT
+)`)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, `CREATE INDEX IF NOT EXISTS isbn_reconcile_isbn ON isbn_reconcile (isbn, seq)`)
+	return err
+}
+
+// Reset removes every record from the table.
+func (s *SQLStore) Reset(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM isbn_reconcile`)
+	return err
+}
+
+// Add implements Store. The records are added in a single transaction.
+func (s *SQLStore) Add(ctx context.Context, records ...Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.loaded {
+		if err := s.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(seq), 0) FROM isbn_reconcile`).Scan(&s.seq); err != nil {
+			return err
+		}
+		s.loaded = true
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO isbn_reconcile (seq, record_id, value, title, isbn) VALUES ($1, $2, $3, $4, $5)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	seq := s.seq
+	for _, rec := range records {
+		v, ok, err := parse(rec)
+		if !ok {
+			continue
+		}
+		// an invalid ISBN is held as NULL
+		var value sql.NullString
+		if err == nil {
+			value = sql.NullString{String: v.String(), Valid: true}
+		}
+
+		seq++
+		if _, err := stmt.ExecContext(ctx, seq, rec.ID, rec.ISBN, rec.Title, value); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	s.seq = seq
+	return nil
+}
+
+// Clusters implements Store.
+func (s *SQLStore) Clusters(ctx context.Context, fn func(Cluster) error) error {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT isbn, record_id, value, title FROM isbn_reconcile
+WHERE isbn IN (SELECT isbn FROM isbn_reconcile WHERE isbn IS NOT NULL GROUP BY isbn HAVING COUNT(*) > 1)
+ORDER BY isbn, seq`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var (
+		current isbn.ISBN
+		records []Record
+	)
+	for rows.Next() {
+		var (
+			value string
+			rec   Record
+		)
+		if err := rows.Scan(&value, &rec.ID, &rec.ISBN, &rec.Title); err != nil {
+			return err
+		}
+		v, err := isbn.Parse(value)
+		if err != nil {
+			return err
+		}
+
+		if v != current && records != nil {
+			if err := fn(cluster(current, records)); err != nil {
+				return err
+			}
+			records = nil
+		}
+		current = v
+		records = append(records, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if records != nil {
+		return fn(cluster(current, records))
+	}
+	return nil
+}
+
+// Invalid implements Store.
+func (s *SQLStore) Invalid(ctx context.Context, fn func(Record) error) error {
+	rows, err := s.db.QueryContext(ctx, `SELECT record_id, value, title FROM isbn_reconcile WHERE isbn IS NULL ORDER BY seq`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rec Record
+		if err := rows.Scan(&rec.ID, &rec.ISBN, &rec.Title); err != nil {
+			return err
+		}
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,114 @@
+// Package catalogue is a catalogue of books keyed by ISBN, with repositories
+// over SQLite and PostgreSQL and HTTP handlers to manage it.
+//
+//	db, _ := sql.Open("sqlite3", "books.db")
+//	repo := catalogue.NewSQLiteRepository(db)
+//	repo.Migrate(ctx)
+//
+//	r := services.NewRouter()
+//	r.Mount("/books", catalogue.NewService(repo))
+//
+// The ISBN of a book is its key, so the same book cannot be added under
+// its ISBN 10 and ISBN 13 forms.
+package catalogue
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/adoublef-go/isbn"
+)
+
+var (
+	// ErrNotFound is returned for a book that is not in the catalogue.
+	ErrNotFound = errors.New("catalogue: book not found")
+	// ErrExists is returned when creating a book that is already in the
+	// catalogue.
+	ErrExists = errors.New("catalogue: book already exists")
+
+	// ErrNoISBN and ErrNoTitle are returned by Validate.
+	ErrNoISBN  = errors.New("catalogue: book has no ISBN")
+	ErrNoTitle = errors.New("catalogue: book has no title")
+)
+
+// Book is a book of the catalogue.
+type Book struct {
+	ISBN      isbn.ISBN
+	Title     string
+	Subtitle  string
+	Authors   []string
+	Publisher string
+	// Published is the date of publication, which may be a year ("1988"),
+	// a date ("1988-10-01") or free text.
+	Published string
+	Pages     int
+	Language  string
+
+	// Created and Updated are set by the Repository.
+	Created time.Time
+	Updated time.Time
+}
+
+// Validate reports whether b can be stored in the catalogue.
+func (b *Book) Validate() error {
+	switch {
+	case b.ISBN == isbn.ISBN{}:
+		return ErrNoISBN
+	case b.Title == "":
+		return ErrNoTitle
+	}
+	return nil
+}
+
+// DefaultLimit is the number of books listed if ListOptions.Limit is zero.
+const DefaultLimit = 50
+
+// ListOptions select the books listed by a Repository.
+type ListOptions struct {
+	// After lists the books whose ISBN comes after it, from the first book
+	// if it is the zero ISBN. Pass the ISBN of the last book listed to list
+	// the next page.
+	After isbn.ISBN
+	// Limit is the most books listed, DefaultLimit if zero.
+	Limit int
+}
+
+func (o ListOptions) limit() int {
+	if o.Limit <= 0 {
+		return DefaultLimit
+	}
+	return o.Limit
+}
+
+// after returns the ISBN to list from, which sorts before every ISBN if it
+// is the zero ISBN.
+func (o ListOptions) after() string {
+	if o.After == (isbn.ISBN{}) {
+		return ""
+	}
+	return o.After.String()
+}
+
+// Repository stores the books of a catalogue.
+type Repository interface {
+	// Migrate creates or updates the schema of the repository.
+	Migrate(ctx context.Context) error
+
+	// Create adds b to the catalogue, returning ErrExists if its ISBN is
+	// already in it, and returns b as stored.
+	Create(ctx context.Context, b Book) (Book, error)
+	// Get returns the book with v, or ErrNotFound.
+	Get(ctx context.Context, v isbn.ISBN) (Book, error)
+	// Update replaces the book with the ISBN of b, returning ErrNotFound if
+	// there is none, and returns b as stored.
+	Update(ctx context.Context, b Book) (Book, error)
+	// Delete removes the book with v, or returns ErrNotFound.
+	Delete(ctx context.Context, v isbn.ISBN) error
+	// List returns books in ascending order of ISBN.
+	List(ctx context.Context, opts ListOptions) ([]Book, error)
+}
+
+// now is the time stored as Created and Updated, to the microsecond as
+// PostgreSQL stores it.
+func now() time.Time { return time.Now().UTC().Truncate(time.Microsecond) }
@@ -0,0 +1,223 @@
+package catalogue
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/adoublef-go/isbn"
+	"github.com/go-chi/chi/v5"
+	"github.com/hyphengolang/prelude/testing/is"
+	"github.com/jackc/pgx/v5"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func newSQLiteRepository(t *testing.T) *SQLiteRepository {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	repo := NewSQLiteRepository(db)
+	if err := repo.Migrate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+func TestSQLiteRepository(t *testing.T) {
+	t.Parallel()
+
+	testRepository(t, newSQLiteRepository(t))
+}
+
+func TestPostgresRepository(t *testing.T) {
+	t.Parallel()
+
+	if os.Getenv("POSTGRES_HOST") == "" {
+		t.Skip("POSTGRES_HOST is not set")
+	}
+
+	ctx := context.Background()
+	conn, err := pgx.Connect(ctx, os.ExpandEnv("postgres://${POSTGRES_USER}:${POSTGRES_PASS}@${POSTGRES_HOST}:${POSTGRES_PORT}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close(ctx) })
+
+	// the tables are dropped at the end of the transaction
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { tx.Rollback(ctx) })
+
+	repo := NewPostgresRepository(tx)
+	if err := repo.Migrate(ctx); err != nil {
+		t.Fatal(err)
+	}
+	testRepository(t, repo)
+}
+
+func testRepository(t *testing.T, repo Repository) {
+	is := is.New(t)
+	ctx := context.Background()
+
+	is.NoErr(repo.Migrate(ctx)) // migrate again
+
+	fox, _ := isbn.Parse("0140328726")
+	panda, _ := isbn.Parse("0716703440")
+	misérables, _ := isbn.Parse("9782070409228")
+
+	b, err := repo.Create(ctx, Book{ISBN: fox, Title: "Fantastic Mr Fox", Authors: []string{"Roald Dahl"}, Pages: 96})
+	is.NoErr(err)                               // create book
+	is.True(!b.Created.IsZero())                // created time
+	is.Equal(b.Created, b.Updated)              // updated when created
+	is.Equal(b.Authors, []string{"Roald Dahl"}) // authors
+	_, err = repo.Create(ctx, Book{ISBN: panda, Title: "The Panda's Thumb"})
+	is.NoErr(err) // create book without authors
+	_, err = repo.Create(ctx, Book{ISBN: misérables, Title: "Les Misérables", Language: "fre"})
+	is.NoErr(err) // create book
+
+	_, err = repo.Create(ctx, Book{ISBN: fox, Title: "Fantastic Mr. Fox"})
+	is.True(errors.Is(err, ErrExists)) // create existing book
+	_, err = repo.Create(ctx, Book{Title: "Untitled"})
+	is.True(errors.Is(err, ErrNoISBN)) // create book without isbn
+	_, err = repo.Create(ctx, Book{ISBN: fox})
+	is.True(errors.Is(err, ErrNoTitle)) // create book without title
+
+	got, err := repo.Get(ctx, fox)
+	is.NoErr(err)    // get book
+	is.Equal(got, b) // stored book
+	got, err = repo.Get(ctx, panda)
+	is.NoErr(err)              // get book
+	is.Equal(got.Authors, nil) // no authors
+
+	b.Subtitle = "A Story"
+	b.Authors = append(b.Authors, "Quentin Blake")
+	u, err := repo.Update(ctx, b)
+	is.NoErr(err)                                                // update book
+	is.Equal(u.Created, b.Created)                               // created unchanged
+	is.True(!u.Updated.Before(b.Updated))                        // updated time
+	is.Equal(u.Authors, []string{"Roald Dahl", "Quentin Blake"}) // updated authors
+	got, _ = repo.Get(ctx, fox)
+	is.Equal(got, u) // stored update
+
+	missing, _ := isbn.Parse("9781861972712")
+	_, err = repo.Get(ctx, missing)
+	is.True(errors.Is(err, ErrNotFound)) // get missing book
+	_, err = repo.Update(ctx, Book{ISBN: missing, Title: "Missing"})
+	is.True(errors.Is(err, ErrNotFound))                       // update missing book
+	is.True(errors.Is(repo.Delete(ctx, missing), ErrNotFound)) // delete missing book
+
+	tt := []struct {
+		desc string
+		opts ListOptions
+		want []isbn.ISBN
+	}{
+		{"all", ListOptions{}, []isbn.ISBN{fox, panda, misérables}},
+		{"first page", ListOptions{Limit: 2}, []isbn.ISBN{fox, panda}},
+		{"next page", ListOptions{After: panda, Limit: 2}, []isbn.ISBN{misérables}},
+		{"last page", ListOptions{After: misérables}, nil},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			books, err := repo.List(ctx, tc.opts)
+			is.NoErr(err) // list books
+
+			var got []isbn.ISBN
+			for _, b := range books {
+				got = append(got, b.ISBN)
+			}
+			is.Equal(got, tc.want) // listed isbns
+		})
+	}
+
+	is.NoErr(repo.Delete(ctx, fox)) // delete book
+	_, err = repo.Get(ctx, fox)
+	is.True(errors.Is(err, ErrNotFound)) // deleted book
+}
+
+func TestService(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	r := chi.NewRouter()
+	r.Mount("/books", NewService(newSQLiteRepository(t)))
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+
+	do := func(method, path, body string) (*http.Response, map[string]any) {
+		req, err := http.NewRequest(method, srv.URL+"/books"+path, strings.NewReader(body))
+		is.NoErr(err) // new request
+		resp, err := http.DefaultClient.Do(req)
+		is.NoErr(err) // do request
+		defer resp.Body.Close()
+
+		var v map[string]any
+		json.NewDecoder(resp.Body).Decode(&v)
+		return resp, v
+	}
+
+	resp, v := do(http.MethodPost, "/", `{"isbn": "0-14-032872-6", "title": "Fantastic Mr Fox", "authors": ["Roald Dahl"]}`)
+	is.Equal(resp.StatusCode, http.StatusCreated)                         // create book
+	is.Equal(resp.Header.Get("Location"), srv.URL+"/books/9780140328721") // location of book
+	is.Equal(v["isbn"], "9780140328721")                                  // canonical isbn
+	is.True(v["created_at"] != nil)                                       // created time
+
+	resp, _ = do(http.MethodPost, "/", `{"isbn": "9780140328721", "title": "Fantastic Mr. Fox"}`)
+	is.Equal(resp.StatusCode, http.StatusConflict) // create existing book
+
+	tt := []struct {
+		desc   string
+		method string
+		path   string
+		body   string
+		code   int
+	}{
+		{"create invalid isbn", http.MethodPost, "/", `{"isbn": "0140328727", "title": "Fantastic Mr Fox"}`, http.StatusBadRequest},
+		{"create without title", http.MethodPost, "/", `{"isbn": "0716703440"}`, http.StatusBadRequest},
+		{"create invalid body", http.MethodPost, "/", `["0716703440"]`, http.StatusBadRequest},
+		{"get", http.MethodGet, "/0140328726", "", http.StatusOK},
+		{"get invalid isbn", http.MethodGet, "/0140328727", "", http.StatusBadRequest},
+		{"get missing", http.MethodGet, "/0716703440", "", http.StatusNotFound},
+		{"update", http.MethodPut, "/9780140328721", `{"title": "Fantastic Mr Fox", "subtitle": "A Story"}`, http.StatusOK},
+		{"update other isbn", http.MethodPut, "/9780140328721", `{"isbn": "0716703440", "title": "The Panda's Thumb"}`, http.StatusBadRequest},
+		{"update missing", http.MethodPut, "/0716703440", `{"title": "The Panda's Thumb"}`, http.StatusNotFound},
+		{"delete missing", http.MethodDelete, "/0716703440", "", http.StatusNotFound},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			resp, _ := do(tc.method, tc.path, tc.body)
+			is.Equal(resp.StatusCode, tc.code) // status code
+		})
+	}
+
+	_, v = do(http.MethodGet, "/978-0-14-032872-1", "")
+	is.Equal(v["subtitle"], "A Story") // updated book
+
+	do(http.MethodPost, "/", `{"isbn": "0716703440", "title": "The Panda's Thumb"}`)
+	resp, v = do(http.MethodGet, "/?limit=1", "")
+	is.Equal(resp.StatusCode, http.StatusOK) // list books
+	is.Equal(len(v["books"].([]any)), 1)     // one book
+	is.Equal(v["next"], "9780140328721")     // next page
+	_, v = do(http.MethodGet, "/?limit=1&after="+v["next"].(string), "")
+	is.Equal(v["books"].([]any)[0].(map[string]any)["isbn"], "9780716703440") // second page
+	resp, _ = do(http.MethodGet, "/?limit=0", "")
+	is.Equal(resp.StatusCode, http.StatusBadRequest) // invalid limit
+
+	resp, _ = do(http.MethodDelete, "/0140328726", "")
+	is.Equal(resp.StatusCode, http.StatusNoContent) // delete book
+	resp, _ = do(http.MethodGet, "/9780140328721", "")
+	is.Equal(resp.StatusCode, http.StatusNotFound) // deleted book
+}
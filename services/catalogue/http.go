@@ -0,0 +1,230 @@
+package catalogue
+
+import (
+	"errors"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/adoublef-go/isbn"
+	"github.com/go-chi/chi/v5"
+	"github.com/hyphengolang/services"
+)
+
+// MaxLimit is the most books a list request can ask for.
+const MaxLimit = 1000
+
+// Service is the HTTP API of a catalogue, to be mounted on a router:
+//
+//	GET    /         list books, after the ISBN of the after query parameter
+//	POST   /         create a book, at the path of the Location header
+//	GET    /{isbn}   get a book
+//	PUT    /{isbn}   update a book
+//	DELETE /{isbn}   delete a book
+//
+// An ISBN in the path may be in any form parsed by isbn.Parse.
+type Service struct {
+	services.Router
+
+	repo Repository
+}
+
+// NewService returns the HTTP API of the catalogue in repo.
+func NewService(repo Repository) *Service {
+	s := &Service{Router: services.NewRouter(), repo: repo}
+	s.routes()
+	return s
+}
+
+func (s *Service) routes() {
+	s.With(
+		services.QueryParam("after", isbn.ISBN{}, services.QueryISBN),
+		services.QueryParam("limit", DefaultLimit, services.QueryInt(1, MaxLimit)),
+	).Get("/", s.handleList)
+	s.Post("/", s.handleCreate)
+	s.Route("/{isbn}", func(r chi.Router) {
+		r.Use(services.ISBNMiddleware)
+		r.Get("/", s.handleGet)
+		r.Put("/", s.handleUpdate)
+		r.Delete("/", s.handleDelete)
+	})
+}
+
+type errorJSON struct {
+	Error string `json:"error"`
+}
+
+// bookJSON is a Book in a request or response. The ISBN is a string as an
+// isbn.ISBN is not marshalled as one, and Created and Updated are ignored in
+// requests.
+type bookJSON struct {
+	ISBN      string    `json:"isbn"`
+	Title     string    `json:"title"`
+	Subtitle  string    `json:"subtitle,omitempty"`
+	Authors   []string  `json:"authors,omitempty"`
+	Publisher string    `json:"publisher,omitempty"`
+	Published string    `json:"published,omitempty"`
+	Pages     int       `json:"pages,omitempty"`
+	Language  string    `json:"language,omitempty"`
+	Created   time.Time `json:"created_at"`
+	Updated   time.Time `json:"updated_at"`
+}
+
+func newBookJSON(b Book) bookJSON {
+	return bookJSON{
+		ISBN:      b.ISBN.String(),
+		Title:     b.Title,
+		Subtitle:  b.Subtitle,
+		Authors:   b.Authors,
+		Publisher: b.Publisher,
+		Published: b.Published,
+		Pages:     b.Pages,
+		Language:  b.Language,
+		Created:   b.Created,
+		Updated:   b.Updated,
+	}
+}
+
+// book returns j as a Book, with the ISBN v if j has none.
+func (j bookJSON) book(v isbn.ISBN) (Book, error) {
+	if j.ISBN != "" {
+		var err error
+		if v, err = isbn.Parse(j.ISBN); err != nil {
+			return Book{}, err
+		}
+	}
+	return Book{
+		ISBN:      v,
+		Title:     j.Title,
+		Subtitle:  j.Subtitle,
+		Authors:   j.Authors,
+		Publisher: j.Publisher,
+		Published: j.Published,
+		Pages:     j.Pages,
+		Language:  j.Language,
+	}, nil
+}
+
+// listJSON is a page of books. Next is the after query parameter of the
+// next page, if there may be one.
+type listJSON struct {
+	Books []bookJSON `json:"books"`
+	Next  string     `json:"next,omitempty"`
+}
+
+// respondError writes err as the JSON body of a response with the status
+// of the catalogue error it is, or logs it and writes a 500 response.
+func (s *Service) respondError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		s.Respond(w, r, errorJSON{err.Error()}, http.StatusNotFound)
+	case errors.Is(err, ErrExists):
+		s.Respond(w, r, errorJSON{err.Error()}, http.StatusConflict)
+	case errors.Is(err, ErrNoISBN), errors.Is(err, ErrNoTitle):
+		s.Respond(w, r, errorJSON{err.Error()}, http.StatusBadRequest)
+	default:
+		s.Logf("catalogue: %s %s: %v", r.Method, r.URL.Path, err)
+		s.Respond(w, r, errorJSON{http.StatusText(http.StatusInternalServerError)}, http.StatusInternalServerError)
+	}
+}
+
+func (s *Service) handleList(w http.ResponseWriter, r *http.Request) {
+	after, _ := services.QueryParamValue[isbn.ISBN](r, "after")
+	limit, _ := services.QueryParamValue[int](r, "limit")
+
+	books, err := s.repo.List(r.Context(), ListOptions{After: after, Limit: limit})
+	if err != nil {
+		s.respondError(w, r, err)
+		return
+	}
+
+	res := listJSON{Books: make([]bookJSON, len(books))}
+	for i, b := range books {
+		res.Books[i] = newBookJSON(b)
+	}
+	if len(books) == limit {
+		res.Next = books[len(books)-1].ISBN.String()
+	}
+	s.Respond(w, r, res, http.StatusOK)
+}
+
+func (s *Service) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var j bookJSON
+	if err := s.Decode(w, r, &j); err != nil {
+		s.Respond(w, r, errorJSON{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	b, err := j.book(isbn.ISBN{})
+	if err != nil {
+		s.Respond(w, r, errorJSON{err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	b, err = s.repo.Create(r.Context(), b)
+	if err != nil {
+		s.respondError(w, r, err)
+		return
+	}
+	s.SetLocation(w, r, path.Join(r.URL.Path, b.ISBN.String()))
+	s.Respond(w, r, newBookJSON(b), http.StatusCreated)
+}
+
+func (s *Service) handleGet(w http.ResponseWriter, r *http.Request) {
+	v, err := services.ISBNFromRequest(r)
+	if err != nil {
+		s.respondError(w, r, err)
+		return
+	}
+
+	b, err := s.repo.Get(r.Context(), v)
+	if err != nil {
+		s.respondError(w, r, err)
+		return
+	}
+	s.Respond(w, r, newBookJSON(b), http.StatusOK)
+}
+
+func (s *Service) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	v, err := services.ISBNFromRequest(r)
+	if err != nil {
+		s.respondError(w, r, err)
+		return
+	}
+
+	var j bookJSON
+	if err := s.Decode(w, r, &j); err != nil {
+		s.Respond(w, r, errorJSON{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	b, err := j.book(v)
+	if err != nil {
+		s.Respond(w, r, errorJSON{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if b.ISBN != v {
+		// the ISBN of a book is its key, so it cannot be changed
+		s.Respond(w, r, errorJSON{"catalogue: book ISBN does not match path"}, http.StatusBadRequest)
+		return
+	}
+
+	b, err = s.repo.Update(r.Context(), b)
+	if err != nil {
+		s.respondError(w, r, err)
+		return
+	}
+	s.Respond(w, r, newBookJSON(b), http.StatusOK)
+}
+
+func (s *Service) handleDelete(w http.ResponseWriter, r *http.Request) {
+	v, err := services.ISBNFromRequest(r)
+	if err != nil {
+		s.respondError(w, r, err)
+		return
+	}
+
+	if err := s.repo.Delete(r.Context(), v); err != nil {
+		s.respondError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,175 @@
+package catalogue
+
+import (
+	"context"
+	"errors"
+
+	"github.com/adoublef-go/isbn"
+	psql "github.com/hyphengolang/prelude/sql/postgres"
+	"github.com/jackc/pgx/v5"
+)
+
+// postgresMigrations are the changes to the schema of a PostgresRepository,
+// in the order they are applied.
+var postgresMigrations = []string{`
+CREATE TABLE books (
+	isbn      TEXT PRIMARY KEY CHECK (length(isbn) = 13),
+	title     TEXT NOT NULL,
+	subtitle  TEXT NOT NULL,
+	authors   TEXT[] NOT NULL,
+	publisher TEXT NOT NULL,
+	published TEXT NOT NULL,
+	pages     INTEGER NOT NULL,
+	language  TEXT NOT NULL,
+	created   TIMESTAMPTZ NOT NULL,
+	updated   TIMESTAMPTZ NOT NULL
+)`,
+}
+
+// PostgresRepository is a Repository held in a PostgreSQL database.
+type PostgresRepository struct {
+	q psql.Q
+}
+
+var _ Repository = (*PostgresRepository)(nil)
+
+// NewPostgresRepository returns a PostgresRepository using q, such as a
+// *pgx.Conn or *pgxpool.Pool. Migrate creates its tables.
+func NewPostgresRepository(q psql.Q) *PostgresRepository { return &PostgresRepository{q: q} }
+
+// Migrate implements Repository. The migrations applied are recorded in the
+// catalogue_migrations table, and each is applied in a transaction if q can
+// begin one.
+func (r *PostgresRepository) Migrate(ctx context.Context) error {
+	if err := psql.ExecContext(ctx, r.q, `CREATE TABLE IF NOT EXISTS catalogue_migrations (version INTEGER PRIMARY KEY)`); err != nil {
+		return err
+	}
+
+	var version int
+	err := psql.QueryRowContext(ctx, r.q, `SELECT COALESCE(MAX(version), 0) FROM catalogue_migrations`,
+		func(row pgx.Row) error { return row.Scan(&version) })
+	if err != nil {
+		return err
+	}
+
+	for i := version; i < len(postgresMigrations); i++ {
+		if err := r.migrate(ctx, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrate applies postgresMigrations[i].
+func (r *PostgresRepository) migrate(ctx context.Context, i int) error {
+	q := r.q
+	if b, ok := r.q.(interface {
+		Begin(ctx context.Context) (pgx.Tx, error)
+	}); ok {
+		tx, err := b.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+		q = tx
+	}
+
+	if err := psql.ExecContext(ctx, q, postgresMigrations[i]); err != nil {
+		return err
+	}
+	if err := psql.ExecContext(ctx, q, `INSERT INTO catalogue_migrations (version) VALUES ($1)`, i+1); err != nil {
+		return err
+	}
+	if tx, ok := q.(pgx.Tx); ok {
+		return tx.Commit(ctx)
+	}
+	return nil
+}
+
+// postgresArgs returns the column values of b, in the order of columns.
+func postgresArgs(b Book) []any {
+	authors := b.Authors
+	if authors == nil {
+		authors = []string{}
+	}
+	return []any{b.ISBN.String(), b.Title, b.Subtitle, authors, b.Publisher, b.Published, b.Pages, b.Language, b.Created, b.Updated}
+}
+
+// postgresScan scans a row of columns into b, returning ErrNotFound
+// if there is no row.
+func postgresScan(row pgx.Row, b *Book) error {
+	var v string
+	err := row.Scan(&v, &b.Title, &b.Subtitle, &b.Authors, &b.Publisher, &b.Published, &b.Pages, &b.Language, &b.Created, &b.Updated)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if b.ISBN, err = isbn.Parse(v); err != nil {
+		return err
+	}
+	if len(b.Authors) == 0 {
+		b.Authors = nil
+	}
+	b.Created, b.Updated = b.Created.UTC(), b.Updated.UTC()
+	return nil
+}
+
+// Create implements Repository.
+func (r *PostgresRepository) Create(ctx context.Context, b Book) (Book, error) {
+	if err := b.Validate(); err != nil {
+		return Book{}, err
+	}
+	b.Created = now()
+	b.Updated = b.Created
+
+	err := psql.QueryRowContext(ctx, r.q, `INSERT INTO books (`+columns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (isbn) DO NOTHING RETURNING `+columns,
+		func(row pgx.Row) error { return postgresScan(row, &b) }, postgresArgs(b)...)
+	if errors.Is(err, ErrNotFound) {
+		return Book{}, ErrExists
+	}
+	return b, err
+}
+
+// Get implements Repository.
+func (r *PostgresRepository) Get(ctx context.Context, v isbn.ISBN) (Book, error) {
+	var b Book
+	err := psql.QueryRowContext(ctx, r.q, `SELECT `+columns+` FROM books WHERE isbn = $1`,
+		func(row pgx.Row) error { return postgresScan(row, &b) }, v.String())
+	return b, err
+}
+
+// Update implements Repository.
+func (r *PostgresRepository) Update(ctx context.Context, b Book) (Book, error) {
+	if err := b.Validate(); err != nil {
+		return Book{}, err
+	}
+	b.Updated = now()
+
+	// created is left as it was
+	args := append(postgresArgs(b)[:8:8], b.Updated)
+	err := psql.QueryRowContext(ctx, r.q, `UPDATE books SET title = $2, subtitle = $3, authors = $4, publisher = $5, published = $6, pages = $7, language = $8, updated = $9
+WHERE isbn = $1 RETURNING `+columns,
+		func(row pgx.Row) error { return postgresScan(row, &b) }, args...)
+	return b, err
+}
+
+// Delete implements Repository.
+func (r *PostgresRepository) Delete(ctx context.Context, v isbn.ISBN) error {
+	return psql.QueryRowContext(ctx, r.q, `DELETE FROM books WHERE isbn = $1 RETURNING isbn`, func(row pgx.Row) error {
+		var deleted string
+		err := row.Scan(&deleted)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}, v.String())
+}
+
+// List implements Repository.
+func (r *PostgresRepository) List(ctx context.Context, opts ListOptions) ([]Book, error) {
+	return psql.QueryContext(ctx, r.q, `SELECT `+columns+` FROM books WHERE isbn > $1 ORDER BY isbn LIMIT $2`,
+		func(rows pgx.Rows, b *Book) error { return postgresScan(rows, b) }, opts.after(), opts.limit())
+}
@@ -0,0 +1,176 @@
+package catalogue
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/adoublef-go/isbn"
+	prelude "github.com/hyphengolang/prelude/sql"
+)
+
+// sqliteMigrations are the changes to the schema of a SQLiteRepository, in
+// the order they are applied.
+var sqliteMigrations = []string{`
+CREATE TABLE books (
+	isbn      TEXT PRIMARY KEY CHECK (length(isbn) = 13),
+	title     TEXT NOT NULL,
+	subtitle  TEXT NOT NULL,
+	authors   TEXT NOT NULL, -- JSON array
+	publisher TEXT NOT NULL,
+	published TEXT NOT NULL,
+	pages     INTEGER NOT NULL,
+	language  TEXT NOT NULL,
+	created   TIMESTAMP NOT NULL,
+	updated   TIMESTAMP NOT NULL
+)`,
+}
+
+// columns are the columns of the books table of each repository.
+const columns = `isbn, title, subtitle, authors, publisher, published, pages, language, created, updated`
+
+// SQLiteRepository is a Repository held in a SQLite database opened with
+// mattn/go-sqlite3.
+type SQLiteRepository struct {
+	db *sql.DB
+}
+
+var _ Repository = (*SQLiteRepository)(nil)
+
+// NewSQLiteRepository returns a SQLiteRepository using db. Migrate creates
+// its tables.
+func NewSQLiteRepository(db *sql.DB) *SQLiteRepository { return &SQLiteRepository{db: db} }
+
+// Migrate implements Repository. The migrations applied are recorded in the
+// catalogue_migrations table.
+func (r *SQLiteRepository) Migrate(ctx context.Context) error {
+	if err := prelude.ExecContext(ctx, r.db, `CREATE TABLE IF NOT EXISTS catalogue_migrations (version INTEGER PRIMARY KEY)`); err != nil {
+		return err
+	}
+
+	var version int
+	err := prelude.QueryRowContext(ctx, r.db, func(row *sql.Row) error { return row.Scan(&version) },
+		`SELECT COALESCE(MAX(version), 0) FROM catalogue_migrations`)
+	if err != nil {
+		return err
+	}
+
+	for i := version; i < len(sqliteMigrations); i++ {
+		tx, err := r.db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, sqliteMigrations[i]); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO catalogue_migrations (version) VALUES (?1)`, i+1); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sqliteArgs returns the column values of b, in the order of columns.
+func sqliteArgs(b Book) ([]any, error) {
+	authors := b.Authors
+	if authors == nil {
+		authors = []string{}
+	}
+	a, err := json.Marshal(authors)
+	if err != nil {
+		return nil, err
+	}
+	return []any{b.ISBN.String(), b.Title, b.Subtitle, string(a), b.Publisher, b.Published, b.Pages, b.Language, b.Created, b.Updated}, nil
+}
+
+// sqliteScan scans a row of columns into b.
+func sqliteScan(rows *sql.Rows, b *Book) error {
+	var v, authors string
+	err := rows.Scan(&v, &b.Title, &b.Subtitle, &authors, &b.Publisher, &b.Published, &b.Pages, &b.Language, &b.Created, &b.Updated)
+	if err != nil {
+		return err
+	}
+	if b.ISBN, err = isbn.Parse(v); err != nil {
+		return err
+	}
+	if err := json.Unmarshal([]byte(authors), &b.Authors); err != nil {
+		return err
+	}
+	if len(b.Authors) == 0 {
+		b.Authors = nil
+	}
+	return nil
+}
+
+// queryRow returns the book of a query of columns, or ErrNotFound. The
+// version of prelude/sql this module requires drops the arguments of
+// QueryRowContext, so queries with arguments go through QueryContext.
+func (r *SQLiteRepository) queryRow(ctx context.Context, query string, args ...any) (Book, error) {
+	books, err := prelude.QueryContext(ctx, r.db, sqliteScan, query, args...)
+	if err != nil {
+		return Book{}, err
+	}
+	if len(books) == 0 {
+		return Book{}, ErrNotFound
+	}
+	return books[0], nil
+}
+
+// Create implements Repository.
+func (r *SQLiteRepository) Create(ctx context.Context, b Book) (Book, error) {
+	if err := b.Validate(); err != nil {
+		return Book{}, err
+	}
+	b.Created = now()
+	b.Updated = b.Created
+
+	args, err := sqliteArgs(b)
+	if err != nil {
+		return Book{}, err
+	}
+	b, err = r.queryRow(ctx, `INSERT INTO books (`+columns+`) VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10)
+ON CONFLICT (isbn) DO NOTHING RETURNING `+columns, args...)
+	if errors.Is(err, ErrNotFound) {
+		return Book{}, ErrExists
+	}
+	return b, err
+}
+
+// Get implements Repository.
+func (r *SQLiteRepository) Get(ctx context.Context, v isbn.ISBN) (Book, error) {
+	return r.queryRow(ctx, `SELECT `+columns+` FROM books WHERE isbn = ?1`, v.String())
+}
+
+// Update implements Repository.
+func (r *SQLiteRepository) Update(ctx context.Context, b Book) (Book, error) {
+	if err := b.Validate(); err != nil {
+		return Book{}, err
+	}
+	b.Updated = now()
+
+	args, err := sqliteArgs(b)
+	if err != nil {
+		return Book{}, err
+	}
+	// created is left as it was
+	return r.queryRow(ctx, `UPDATE books SET title = ?2, subtitle = ?3, authors = ?4, publisher = ?5, published = ?6, pages = ?7, language = ?8, updated = ?9
+WHERE isbn = ?1 RETURNING `+columns, append(args[:8:8], b.Updated)...)
+}
+
+// Delete implements Repository.
+func (r *SQLiteRepository) Delete(ctx context.Context, v isbn.ISBN) error {
+	_, err := r.queryRow(ctx, `DELETE FROM books WHERE isbn = ?1 RETURNING `+columns, v.String())
+	return err
+}
+
+// List implements Repository.
+func (r *SQLiteRepository) List(ctx context.Context, opts ListOptions) ([]Book, error) {
+	return prelude.QueryContext(ctx, r.db, sqliteScan,
+		`SELECT `+columns+` FROM books WHERE isbn > ?1 ORDER BY isbn LIMIT ?2`, opts.after(), opts.limit())
+}
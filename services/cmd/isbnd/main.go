@@ -0,0 +1,90 @@
+// Command isbnd serves ISBN validation, barcodes and book metadata over HTTP,
+// as one source of truth for services that are not written in Go.
+//
+// Usage:
+//
+//	isbnd [-addr :8080] [-redirect] [-olindex books.db] [-google-key key]
+//
+// The API is described by the OpenAPI document served at /openapi.json:
+//
+//	GET  /isbn/{isbn}              validity, canonical forms and parts of an ISBN
+//	POST /isbn/batch               the same for a JSON array or JSON lines of ISBNs
+//	GET  /isbn/{isbn}/barcode.svg  the EAN-13 barcode of an ISBN
+//	GET  /isbn/{isbn}/book         the metadata of the book with an ISBN
+//
+// An ISBN may be given in any form, with or without hyphens. An invalid ISBN
+// gets a 400 response whose JSON body says why. With -redirect, a valid ISBN
+// that is not in its canonical ISBN 13 form is redirected to the path with
+// that form instead, so that caches hold one copy of each resource.
+//
+// Books are looked up from the Open Library index built by olindex if one is
+// given, then from the Open Library and Google Books APIs, and are cached in
+// memory.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/adoublef-go/isbn/metadata"
+	"github.com/adoublef-go/isbn/metadata/olindex"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "listen on `address`")
+	redirect := flag.Bool("redirect", false, "redirect ISBNs that are not in ISBN 13 form to their canonical path")
+	index := flag.String("olindex", "", "look up books in the olindex `database` first")
+	googleKey := flag.String("google-key", "", "Google Books API `key`")
+	flag.Parse()
+	if flag.NArg() > 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	resolvers := []metadata.Resolver{&metadata.OpenLibrary{}, &metadata.GoogleBooks{Key: *googleKey}}
+	if *index != "" {
+		ix, err := olindex.Open(*index)
+		if err != nil {
+			log.Fatalln("isbnd:", err)
+		}
+		defer ix.Close()
+		resolvers = append([]metadata.Resolver{ix}, resolvers...)
+	}
+	resolver := &metadata.Cached{
+		Resolver: metadata.Fallback(resolvers...),
+		Cache:    metadata.NewMemoryCache(),
+		Errors:   func(err error) { log.Println("isbnd: cache:", err) },
+	}
+
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           newServer(resolver, *redirect),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	errc := make(chan error, 1)
+	go func() { errc <- srv.ListenAndServe() }()
+	log.Printf("isbnd: listening on %s", *addr)
+
+	select {
+	case err := <-errc:
+		log.Println("isbnd:", err)
+		os.Exit(1)
+	case <-ctx.Done():
+	}
+
+	// finish the requests in flight, such as streaming batches
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Println("isbnd:", err)
+	}
+}
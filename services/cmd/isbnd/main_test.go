@@ -0,0 +1,261 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adoublef-go/isbn"
+	"github.com/adoublef-go/isbn/metadata"
+	"github.com/hyphengolang/prelude/testing/is"
+)
+
+var books = metadata.ResolverFunc(func(ctx context.Context, v isbn.ISBN) (metadata.Book, error) {
+	switch v.String() {
+	case "9780140328721":
+		return metadata.Book{ISBN: v, Title: "Fantastic Mr Fox", Authors: []string{"Roald Dahl"}, Source: "test"}, nil
+	case "9780716703440":
+		return metadata.Book{}, &metadata.RateLimitError{Source: "test", RetryAfter: 1500 * time.Millisecond}
+	default:
+		return metadata.Book{}, metadata.ErrNotFound
+	}
+})
+
+func newTestServer(t *testing.T, redirect bool) *httptest.Server {
+	srv := httptest.NewServer(newServer(books, redirect))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// noRedirect is a client that returns redirects rather than follow them.
+var noRedirect = &http.Client{
+	CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+}
+
+func TestISBN(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	srv := newTestServer(t, false)
+
+	tt := []struct {
+		desc string
+		path string
+		code int
+		want map[string]any
+	}{
+		{"isbn 10", "/isbn/0-14-032872-6", http.StatusOK, map[string]any{
+			"isbn": "9780140328721", "isbn10": "0140328726", "hyphenated": "978-0-14-032872-1", "assigned": true, "agency": "English language",
+			"parts": map[string]any{"prefix": "978", "group": "0", "registrant": "14", "publication": "032872", "check": "1"},
+		}},
+		{"979 isbn", "/isbn/9791032305690", http.StatusOK, map[string]any{
+			"isbn": "9791032305690", "hyphenated": "979-10-323-0569-0", "assigned": true, "agency": "France",
+			"parts": map[string]any{"prefix": "979", "group": "10", "registrant": "323", "publication": "0569", "check": "0"},
+		}},
+		{"unassigned", "/isbn/9790727887794", http.StatusOK, map[string]any{"isbn": "9790727887794", "assigned": false}},
+		{"invalid", "/isbn/0140328727", http.StatusBadRequest, map[string]any{
+			"param": "isbn", "value": "0140328727", "reason": "checksum", "message": "invalid ISBN value",
+		}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			resp, err := http.Get(srv.URL + tc.path)
+			is.NoErr(err) // request isbn
+			defer resp.Body.Close()
+			is.Equal(resp.StatusCode, tc.code) // status code
+
+			var got map[string]any
+			is.NoErr(json.NewDecoder(resp.Body).Decode(&got)) // decode response
+			is.Equal(got, tc.want)                            // response body
+		})
+	}
+}
+
+func TestRedirect(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	srv := newTestServer(t, true)
+
+	resp, err := noRedirect.Get(srv.URL + "/isbn/0140328726/barcode.svg?scale=2")
+	is.NoErr(err) // request isbn 10
+	resp.Body.Close()
+	is.Equal(resp.StatusCode, http.StatusMovedPermanently)                           // redirected
+	is.Equal(resp.Header.Get("Location"), "/isbn/9780140328721/barcode.svg?scale=2") // canonical path
+
+	resp, err = http.Get(srv.URL + "/isbn/978-0-14-032872-1")
+	is.NoErr(err) // request hyphenated isbn, following redirect
+	resp.Body.Close()
+	is.Equal(resp.StatusCode, http.StatusOK)               // canonical resource
+	is.Equal(resp.Request.URL.Path, "/isbn/9780140328721") // redirected to canonical path
+}
+
+func TestBatch(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	srv := newTestServer(t, false)
+
+	tt := []struct {
+		desc        string
+		contentType string
+		body        string
+		want        []string
+	}{
+		{"array", "application/json", `["0140328726", {"isbn": "0140328727"}, "9790727887794"]`, []string{
+			`{"input":"0140328726","valid":true,"isbn":"9780140328721","isbn10":"0140328726","hyphenated":"978-0-14-032872-1","assigned":true,"agency":"English language","parts":{"prefix":"978","group":"0","registrant":"14","publication":"032872","check":"1"}}`,
+			`{"input":"0140328727","valid":false,"error":"invalid ISBN value","reason":"checksum"}`,
+			`{"input":"9790727887794","valid":true,"isbn":"9790727887794","assigned":false}`,
+		}},
+		{"json lines", "application/x-ndjson", "\"014032872\"\n{\"isbn\": \"014032872A\"}\n", []string{
+			`{"input":"014032872","valid":false,"error":"invalid ISBN length 9","reason":"length"}`,
+			`{"input":"014032872A","valid":false,"error":"invalid ISBN format","reason":"format"}`,
+		}},
+		{"empty array", "application/json", " [ ] ", nil},
+		{"empty", "application/x-ndjson", "", nil},
+		{"invalid item after results", "application/x-ndjson", "\"0140328726\"\n42\n", []string{
+			`{"input":"0140328726","valid":true,"isbn":"9780140328721","isbn10":"0140328726","hyphenated":"978-0-14-032872-1","assigned":true,"agency":"English language","parts":{"prefix":"978","group":"0","registrant":"14","publication":"032872","check":"1"}}`,
+			`{"error":"batch items must be strings or objects with an isbn field"}`,
+		}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			resp, err := http.Post(srv.URL+"/isbn/batch", tc.contentType, strings.NewReader(tc.body))
+			is.NoErr(err) // post batch
+			defer resp.Body.Close()
+			is.Equal(resp.StatusCode, http.StatusOK)                          // status code
+			is.Equal(resp.Header.Get("Content-Type"), "application/x-ndjson") // json lines
+
+			var lines []string
+			sc := bufio.NewScanner(resp.Body)
+			for sc.Scan() {
+				lines = append(lines, sc.Text())
+			}
+			is.NoErr(sc.Err())       // read response
+			is.Equal(lines, tc.want) // result lines
+		})
+	}
+
+	resp, err := http.Post(srv.URL+"/isbn/batch", "application/json", strings.NewReader(`{"isbns": []}`))
+	is.NoErr(err) // post invalid batch
+	resp.Body.Close()
+	is.Equal(resp.StatusCode, http.StatusBadRequest) // invalid request
+}
+
+func TestBatchStream(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	srv := newTestServer(t, false)
+
+	// a result is written as soon as its ISBN is read
+	pr, pw := io.Pipe()
+	t.Cleanup(func() { pw.Close() })
+	go io.WriteString(pw, "\"0140328726\"\n")
+
+	resp, err := http.Post(srv.URL+"/isbn/batch", "application/x-ndjson", pr)
+	is.NoErr(err) // post batch
+	defer resp.Body.Close()
+
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	is.NoErr(err)                                                          // first line before the request ends
+	is.True(strings.HasPrefix(line, `{"input":"0140328726","valid":true`)) // first result
+}
+
+func TestBarcode(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	srv := newTestServer(t, false)
+
+	resp, err := http.Get(srv.URL + "/isbn/9780140328721/barcode.svg?addon=51299&scale=2&text=false")
+	is.NoErr(err) // request barcode
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	is.Equal(resp.StatusCode, http.StatusOK)                   // status code
+	is.Equal(resp.Header.Get("Content-Type"), "image/svg+xml") // svg
+	is.True(strings.HasPrefix(string(body), "<svg"))           // svg document
+	is.True(!strings.Contains(string(body), "<text"))          // no text
+
+	tt := []struct {
+		desc  string
+		query string
+		param string
+	}{
+		{"addon", "addon=5129", "addon"},
+		{"scale", "scale=0", "scale"},
+		{"height", "height=tall", "height"},
+		{"text", "text=maybe", "text"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			resp, err := http.Get(srv.URL + "/isbn/9780140328721/barcode.svg?" + tc.query)
+			is.NoErr(err) // request barcode
+			defer resp.Body.Close()
+			is.Equal(resp.StatusCode, http.StatusBadRequest) // invalid query
+
+			var perr struct{ Param string }
+			is.NoErr(json.NewDecoder(resp.Body).Decode(&perr)) // decode error
+			is.Equal(perr.Param, tc.param)                     // invalid param
+		})
+	}
+}
+
+func TestBook(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	srv := newTestServer(t, false)
+
+	resp, err := http.Get(srv.URL + "/isbn/0140328726/book")
+	is.NoErr(err) // request book
+	var book bookJSON
+	is.NoErr(json.NewDecoder(resp.Body).Decode(&book)) // decode book
+	resp.Body.Close()
+	is.Equal(resp.StatusCode, http.StatusOK)                                                                                    // status code
+	is.Equal(book, bookJSON{ISBN: "9780140328721", Title: "Fantastic Mr Fox", Authors: []string{"Roald Dahl"}, Source: "test"}) // book
+
+	resp, err = http.Get(srv.URL + "/isbn/9781861972712/book")
+	is.NoErr(err) // request missing book
+	resp.Body.Close()
+	is.Equal(resp.StatusCode, http.StatusNotFound) // not found
+
+	resp, err = http.Get(srv.URL + "/isbn/9780716703440/book")
+	is.NoErr(err) // request rate limited book
+	resp.Body.Close()
+	is.Equal(resp.StatusCode, http.StatusServiceUnavailable) // rate limited
+	is.Equal(resp.Header.Get("Retry-After"), "2")            // seconds rounded up
+}
+
+func TestOpenAPI(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	srv := newTestServer(t, false)
+
+	resp, err := http.Get(srv.URL + "/openapi.json")
+	is.NoErr(err) // request document
+	defer resp.Body.Close()
+
+	var doc struct {
+		OpenAPI string
+		Paths   map[string]any
+	}
+	is.NoErr(json.NewDecoder(resp.Body).Decode(&doc)) // valid json
+	is.Equal(doc.OpenAPI, "3.0.3")                    // openapi version
+
+	// every route is documented
+	for _, path := range []string{"/isbn/{isbn}", "/isbn/batch", "/isbn/{isbn}/barcode.svg", "/isbn/{isbn}/book", "/openapi.json"} {
+		_, ok := doc.Paths[path]
+		is.True(ok) // path documented
+	}
+}
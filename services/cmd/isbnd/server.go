@@ -0,0 +1,318 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/adoublef-go/isbn"
+	"github.com/adoublef-go/isbn/barcode"
+	"github.com/adoublef-go/isbn/metadata"
+	"github.com/go-chi/chi/v5"
+	"github.com/hyphengolang/services"
+)
+
+//go:embed openapi.json
+var openapi []byte
+
+// maxBatchBytes limits the body of a batch request.
+const maxBatchBytes = 64 << 20
+
+type server struct {
+	services.Router
+
+	resolver metadata.Resolver
+}
+
+// newServer returns the handler of the API, looking up books with resolver.
+// If redirect is set, ISBNs that are not in their canonical form are
+// redirected to it.
+func newServer(resolver metadata.Resolver, redirect bool) *server {
+	s := &server{Router: services.NewRouter(), resolver: resolver}
+	s.routes(redirect)
+	return s
+}
+
+func (s *server) routes(redirect bool) {
+	isbnMiddleware := services.ISBNMiddleware
+	if redirect {
+		isbnMiddleware = services.ISBNRedirectMiddleware
+	}
+
+	s.Get("/openapi.json", s.handleOpenAPI)
+	s.Route("/isbn", func(r chi.Router) {
+		r.Post("/batch", s.handleBatch)
+		r.Route("/{isbn}", func(r chi.Router) {
+			r.Use(isbnMiddleware)
+			r.Get("/", s.handleISBN)
+			r.With(
+				services.QueryParam("addon", "", services.QueryString),
+				services.QueryParam("scale", 1, services.QueryInt(1, 20)),
+				services.QueryParam("height", barcode.DefaultHeight, services.QueryInt(10, 500)),
+				services.QueryParam("text", true, services.QueryBool),
+			).Get("/barcode.svg", s.handleBarcode)
+			r.Get("/book", s.handleBook)
+		})
+	})
+}
+
+type errorJSON struct {
+	Error string `json:"error"`
+}
+
+type partsJSON struct {
+	Prefix      string `json:"prefix"`
+	Group       string `json:"group"`
+	Registrant  string `json:"registrant"`
+	Publication string `json:"publication"`
+	Check       string `json:"check"`
+}
+
+type isbnJSON struct {
+	ISBN       string     `json:"isbn"`
+	ISBN10     string     `json:"isbn10,omitempty"`
+	Hyphenated string     `json:"hyphenated,omitempty"`
+	Assigned   bool       `json:"assigned"`
+	Agency     string     `json:"agency,omitempty"`
+	Parts      *partsJSON `json:"parts,omitempty"`
+}
+
+func newISBNJSON(v isbn.ISBN) *isbnJSON {
+	j := &isbnJSON{ISBN: v.String()}
+	j.ISBN10, _ = v.ISBN10()
+	if p, err := v.Parts(); err == nil {
+		j.Assigned = true
+		j.Hyphenated = p.String()
+		j.Agency = p.Agency
+		j.Parts = &partsJSON{
+			Prefix:      p.Prefix,
+			Group:       p.Group,
+			Registrant:  p.Registrant,
+			Publication: p.Publication,
+			Check:       p.Check,
+		}
+	}
+	return j
+}
+
+func (s *server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openapi)
+}
+
+func (s *server) handleISBN(w http.ResponseWriter, r *http.Request) {
+	v, err := services.ISBNFromRequest(r)
+	if err != nil {
+		s.Respond(w, r, errorJSON{err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	s.Respond(w, r, newISBNJSON(v), http.StatusOK)
+}
+
+// resultJSON is a line of the response to a batch request.
+type resultJSON struct {
+	Input string `json:"input"`
+	Valid bool   `json:"valid"`
+	*isbnJSON
+	Error  string `json:"error,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// newResultJSON returns the result of parsing input, with the reason of an
+// error named as in the 400 responses of the other endpoints.
+func newResultJSON(input string) resultJSON {
+	v, err := isbn.Parse(input)
+	if err != nil {
+		return resultJSON{Input: input, Error: err.Error(), Reason: services.Reason(err)}
+	}
+	return resultJSON{Input: input, Valid: true, isbnJSON: newISBNJSON(v)}
+}
+
+// batchItem is an ISBN of a batch request, given as a string or as an
+// object with an isbn field.
+type batchItem string
+
+func (item *batchItem) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*item = batchItem(s)
+		return nil
+	}
+
+	var o struct {
+		ISBN *string `json:"isbn"`
+	}
+	if err := json.Unmarshal(b, &o); err != nil || o.ISBN == nil {
+		return errors.New("batch items must be strings or objects with an isbn field")
+	}
+	*item = batchItem(*o.ISBN)
+	return nil
+}
+
+// handleBatch reads ISBNs as a JSON array or as a stream of JSON values,
+// such as JSON lines, and writes the result of each as a JSON line as soon
+// as it is read. An error in the request after the first result has been
+// written ends the response with an error line.
+func (s *server) handleBatch(w http.ResponseWriter, r *http.Request) {
+	// an HTTP/1 request body cannot otherwise be read once the response has
+	// started; this is http.ResponseController.EnableFullDuplex on toolchains
+	// that have it
+	if fd, ok := w.(interface{ EnableFullDuplex() error }); ok {
+		fd.EnableFullDuplex()
+	}
+
+	br := bufio.NewReader(http.MaxBytesReader(w, r.Body, maxBatchBytes))
+	array := false
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			break
+		}
+		if b[0] == ' ' || b[0] == '\t' || b[0] == '\r' || b[0] == '\n' {
+			br.ReadByte()
+			continue
+		}
+		array = b[0] == '['
+		break
+	}
+
+	dec := json.NewDecoder(br)
+	if array {
+		dec.Token()
+	}
+	enc := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+
+	started := false
+	fail := func(err error) {
+		if !started {
+			s.Respond(w, r, errorJSON{err.Error()}, http.StatusBadRequest)
+			return
+		}
+		enc.Encode(errorJSON{err.Error()})
+	}
+	for r.Context().Err() == nil {
+		if array && !dec.More() {
+			if _, err := dec.Token(); err != nil {
+				fail(err)
+			}
+			break
+		}
+
+		var item batchItem
+		err := dec.Decode(&item)
+		if err == io.EOF && !array {
+			break
+		}
+		if err != nil {
+			fail(err)
+			return
+		}
+
+		if !started {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			started = true
+		}
+		if err := enc.Encode(newResultJSON(string(item))); err != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	if !started {
+		// an empty batch
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	}
+}
+
+func (s *server) handleBarcode(w http.ResponseWriter, r *http.Request) {
+	v, err := services.ISBNFromRequest(r)
+	if err != nil {
+		s.Respond(w, r, errorJSON{err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	addOn, _ := services.QueryParamValue[string](r, "addon")
+	scale, _ := services.QueryParamValue[int](r, "scale")
+	height, _ := services.QueryParamValue[int](r, "height")
+	text, _ := services.QueryParamValue[bool](r, "text")
+
+	b, err := barcode.New(v, addOn)
+	if err != nil {
+		perr := &services.ParamError{Param: "addon", Value: addOn, Reason: "format", Message: err.Error(), Err: err}
+		s.Respond(w, r, perr, http.StatusBadRequest)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := b.SVG(&buf, &barcode.Options{Scale: float64(scale), Height: float64(height), NoText: !text}); err != nil {
+		s.Respond(w, r, errorJSON{err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	w.Write(buf.Bytes())
+}
+
+type bookJSON struct {
+	ISBN        string   `json:"isbn"`
+	Title       string   `json:"title"`
+	Subtitle    string   `json:"subtitle,omitempty"`
+	Authors     []string `json:"authors,omitempty"`
+	Publisher   string   `json:"publisher,omitempty"`
+	Published   string   `json:"published,omitempty"`
+	Pages       int      `json:"pages,omitempty"`
+	Language    string   `json:"language,omitempty"`
+	Subjects    []string `json:"subjects,omitempty"`
+	Description string   `json:"description,omitempty"`
+	CoverURL    string   `json:"cover_url,omitempty"`
+	Source      string   `json:"source,omitempty"`
+}
+
+func (s *server) handleBook(w http.ResponseWriter, r *http.Request) {
+	v, err := services.ISBNFromRequest(r)
+	if err != nil {
+		s.Respond(w, r, errorJSON{err.Error()}, http.StatusInternalServerError)
+		return
+	}
+
+	book, err := s.resolver.Resolve(r.Context(), v)
+	var rerr *metadata.RateLimitError
+	switch {
+	case errors.Is(err, metadata.ErrNotFound):
+		s.Respond(w, r, errorJSON{err.Error()}, http.StatusNotFound)
+		return
+	case errors.As(err, &rerr):
+		if rerr.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(rerr.RetryAfter.Seconds()))))
+		}
+		s.Respond(w, r, errorJSON{err.Error()}, http.StatusServiceUnavailable)
+		return
+	case err != nil:
+		s.Logf("isbnd: resolving %s: %v", v, err)
+		s.Respond(w, r, errorJSON{"looking up book failed"}, http.StatusBadGateway)
+		return
+	}
+
+	s.Respond(w, r, bookJSON{
+		ISBN:        v.String(),
+		Title:       book.Title,
+		Subtitle:    book.Subtitle,
+		Authors:     book.Authors,
+		Publisher:   book.Publisher,
+		Published:   book.Published,
+		Pages:       book.Pages,
+		Language:    book.Language,
+		Subjects:    book.Subjects,
+		Description: book.Description,
+		CoverURL:    book.CoverURL,
+		Source:      book.Source,
+	}, http.StatusOK)
+}
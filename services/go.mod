@@ -1,3 +1,21 @@
 module github.com/hyphengolang/services
 
 go 1.20
+
+require (
+	github.com/adoublef-go/isbn v0.0.0
+	github.com/go-chi/chi/v5 v5.0.8
+	github.com/google/uuid v1.3.0
+	github.com/hyphengolang/prelude v0.1.1
+	github.com/jackc/pgx/v5 v5.0.3
+	github.com/mattn/go-sqlite3 v1.14.15
+)
+
+require (
+	github.com/jackc/pgpassfile v1.0.0 // indirect
+	github.com/jackc/pgservicefile v0.0.0-20200714003250-2b9c44734f2b // indirect
+	golang.org/x/crypto v0.0.0-20220829220503-c86fa9a7ed90 // indirect
+	golang.org/x/text v0.3.7 // indirect
+)
+
+replace github.com/adoublef-go/isbn => ../
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/adoublef-go/isbn"
+	"github.com/go-chi/chi/v5"
+)
+
+// ISBNParam parses the path parameter key as an ISBN in any form understood
+// by isbn.Parse, returning a *ParamError if it is invalid. It can be used as
+// the parser of PathParam.
+func ISBNParam(r *http.Request, key string) (isbn.ISBN, error) {
+	value := chi.URLParam(r, key)
+	v, err := isbn.Parse(value)
+	if err != nil {
+		return v, newParamError(key, value, err)
+	}
+	return v, nil
+}
+
+// ISBNMiddleware parses the "isbn" path parameter, as ISBNParam does, and
+// stores the ISBN in the request context. An invalid ISBN gets a 400
+// response with a ParamError body.
+func ISBNMiddleware(next http.Handler) http.Handler {
+	return isbnMiddleware(next, false)
+}
+
+// ISBNRedirectMiddleware is like ISBNMiddleware, except it redirects a
+// request whose "isbn" path parameter is valid but not in the canonical ISBN
+// 13 form, such as an ISBN 10 or a hyphenated ISBN, to the path with the
+// canonical form. GET and HEAD requests are redirected with 301, others
+// with 308 so that the method and body are kept.
+func ISBNRedirectMiddleware(next http.Handler) http.Handler {
+	return isbnMiddleware(next, true)
+}
+
+func isbnMiddleware(next http.Handler, redirect bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		v, err := ISBNParam(r, "isbn")
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		if redirect {
+			if path, ok := canonicalPath(r.URL.Path, chi.URLParam(r, "isbn"), v.String()); ok {
+				u := *r.URL
+				u.Path, u.RawPath = path, ""
+				code := http.StatusMovedPermanently
+				if r.Method != http.MethodGet && r.Method != http.MethodHead {
+					code = http.StatusPermanentRedirect
+				}
+				http.Redirect(w, r, u.String(), code)
+				return
+			}
+		}
+
+		r = r.WithContext(context.WithValue(r.Context(), isbnKey, v))
+		next.ServeHTTP(w, r)
+	})
+}
+
+// canonicalPath replaces the last segment of path that is value with
+// canonical. It reports false if value is already canonical, or is not a
+// whole segment of path.
+func canonicalPath(path, value, canonical string) (string, bool) {
+	if value == canonical {
+		return "", false
+	}
+	if s, err := url.PathUnescape(value); err == nil {
+		value = s
+	}
+
+	segments := strings.Split(path, "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		if segments[i] == value {
+			segments[i] = canonical
+			return strings.Join(segments, "/"), true
+		}
+	}
+	return "", false
+}
+
+func ISBNFromRequest(r *http.Request) (isbn.ISBN, error) {
+	return ISBNFromContext(r.Context())
+}
+
+func ISBNFromContext(ctx context.Context) (isbn.ISBN, error) {
+	v, ok := ctx.Value(isbnKey).(isbn.ISBN)
+	if !ok {
+		return v, fmt.Errorf("isbn not found in context")
+	}
+	return v, nil
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/adoublef-go/isbn"
+	"github.com/go-chi/chi/v5"
+	"github.com/hyphengolang/prelude/testing/is"
+)
+
+func isbnRouter(middleware func(http.Handler) http.Handler) http.Handler {
+	mux := chi.NewRouter()
+	mux.With(middleware).Get("/books/{isbn}/covers", func(w http.ResponseWriter, r *http.Request) {
+		v, _ := ISBNFromRequest(r)
+		w.Write([]byte(v.String()))
+	})
+	mux.With(PathParam("id", ISBNParam)).Get("/editions/{id}", func(w http.ResponseWriter, r *http.Request) {
+		v, _ := PathParamFromRequest[isbn.ISBN](r)
+		w.Write([]byte(v.String()))
+	})
+	return mux
+}
+
+func TestISBNMiddleware(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	tt := []struct {
+		desc     string
+		redirect bool
+		target   string
+		code     int
+		body     string
+		location string
+		reason   string
+	}{
+		{desc: "isbn 13", target: "/books/9780140328721/covers", code: http.StatusOK, body: "9780140328721"},
+		{desc: "isbn 10", target: "/books/0140328726/covers", code: http.StatusOK, body: "9780140328721"},
+		{desc: "hyphenated", target: "/books/978-0-14-032872-1/covers", code: http.StatusOK, body: "9780140328721"},
+		{desc: "checksum", target: "/books/0140328727/covers", code: http.StatusBadRequest, reason: "checksum"},
+		{desc: "length", target: "/books/01403287/covers", code: http.StatusBadRequest, reason: "length"},
+		{desc: "format", target: "/books/014032872A/covers", code: http.StatusBadRequest, reason: "format"},
+		{desc: "path param", target: "/editions/0-14-032872-6", code: http.StatusOK, body: "9780140328721"},
+		{desc: "path param checksum", target: "/editions/0140328727", code: http.StatusBadRequest, reason: "checksum"},
+		{desc: "redirect isbn 10", redirect: true, target: "/books/0140328726/covers?size=L", code: http.StatusMovedPermanently, location: "/books/9780140328721/covers?size=L"},
+		{desc: "redirect hyphenated", redirect: true, target: "/books/978-0-14-032872-1/covers", code: http.StatusMovedPermanently, location: "/books/9780140328721/covers"},
+		{desc: "canonical not redirected", redirect: true, target: "/books/9780140328721/covers", code: http.StatusOK, body: "9780140328721"},
+		{desc: "invalid not redirected", redirect: true, target: "/books/0140328727/covers", code: http.StatusBadRequest, reason: "checksum"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			middleware := ISBNMiddleware
+			if tc.redirect {
+				middleware = ISBNRedirectMiddleware
+			}
+
+			w := httptest.NewRecorder()
+			isbnRouter(middleware).ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.target, nil))
+			is.Equal(w.Code, tc.code) // status code
+
+			switch tc.code {
+			case http.StatusOK:
+				is.Equal(w.Body.String(), tc.body) // canonical isbn in context
+			case http.StatusMovedPermanently:
+				is.Equal(w.Header().Get("Location"), tc.location) // canonical path
+			case http.StatusBadRequest:
+				var perr ParamError
+				is.NoErr(json.NewDecoder(w.Body).Decode(&perr)) // structured error
+				is.Equal(perr.Reason, tc.reason)                // error reason
+				is.True(perr.Message != "")                     // error message
+			}
+		})
+	}
+}
+
+func TestISBNRedirectMethod(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	mux := chi.NewRouter()
+	mux.With(ISBNRedirectMiddleware).Put("/books/{isbn}", func(w http.ResponseWriter, r *http.Request) {})
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/books/0140328726", nil))
+	is.Equal(w.Code, http.StatusPermanentRedirect)               // method kept
+	is.Equal(w.Header().Get("Location"), "/books/9780140328721") // canonical path
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -10,27 +11,51 @@ import (
 )
 
 // PathParam is a middleware that parses a path parameter and stores it in the request context.
+// Each parameter is stored under its key, so that several can be parsed for a route and
+// retrieved with PathParamValue. A parser error that is a *ParamError is written as the
+// JSON body of the 400 response.
 func PathParam[T any](key string, parser func(r *http.Request, key string) (T, error)) func(http.Handler) http.Handler {
 	return func(h http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			value, err := parser(r, key)
 			if err != nil {
-				http.Error(w, err.Error(), http.StatusBadRequest)
+				writeError(w, r, err)
 				return
 			}
 
-			r = r.WithContext(context.WithValue(r.Context(), pathParamKey, value))
-			h.ServeHTTP(w, r)
+			ctx := context.WithValue(r.Context(), pathParamKey, value)
+			ctx = context.WithValue(ctx, pathParamKey+contextKey(":"+key), value)
+			h.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
+// PathParamValue retrieves the path parameter key from the request context.
+func PathParamValue[T any](r *http.Request, key string) (T, error) {
+	return PathParamValueFromContext[T](r.Context(), key)
+}
+
+// PathParamValueFromContext retrieves the path parameter key from the request context.
+func PathParamValueFromContext[T any](ctx context.Context, key string) (T, error) {
+	v, ok := ctx.Value(pathParamKey + contextKey(":"+key)).(T)
+	if !ok {
+		return v, fmt.Errorf("path param %q not found", key)
+	}
+	return v, nil
+}
+
 // PathParamFromRequest retrieves a path parameter from the request context.
+//
+// Deprecated: the path parameter is the last parsed for the route, whatever its key. Use
+// PathParamValue.
 func PathParamFromRequest[T any](r *http.Request) (T, error) {
 	return PathParamFromContext[T](r.Context())
 }
 
 // PathParamFromContext retrieves a path parameter from the request context.
+//
+// Deprecated: the path parameter is the last parsed for the route, whatever its key. Use
+// PathParamValueFromContext.
 func PathParamFromContext[T any](ctx context.Context) (T, error) {
 	v, ok := ctx.Value(pathParamKey).(T)
 	if !ok {
@@ -40,6 +65,20 @@ func PathParamFromContext[T any](ctx context.Context) (T, error) {
 	return v, nil
 }
 
+// UUIDParam parses the path parameter key as a UUID, rejecting the nil UUID. It can be
+// used as the parser of PathParam.
+func UUIDParam(r *http.Request, key string) (uuid.UUID, error) {
+	value := chi.URLParam(r, key)
+	uid, err := uuid.Parse(value)
+	if err == nil && uid == uuid.Nil {
+		err = errors.New("nil uuid")
+	}
+	if err != nil {
+		return uid, newParamError(key, value, err)
+	}
+	return uid, nil
+}
+
 func UUIDMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		uid, err := uuid.Parse(chi.URLParam(r, "uuid"))
@@ -74,7 +113,9 @@ type contextKey string
 
 const (
 	uuidKey      = contextKey("uuid")
+	isbnKey      = contextKey("isbn")
 	pathParamKey = contextKey("path-param")
+	queryKey     = contextKey("query-param")
 )
 
 func (k contextKey) String() string {
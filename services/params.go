@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/adoublef-go/isbn"
+	h "github.com/hyphengolang/prelude/http"
+)
+
+// ParamError is an invalid request parameter. It is written as the JSON
+// body of a 400 response by PathParam, QueryParam and the ISBN middleware.
+type ParamError struct {
+	Param string `json:"param"`
+	Value string `json:"value"`
+	// Reason names the kind of error, e.g. "checksum" for an ISBN with
+	// an invalid check digit.
+	Reason string `json:"reason"`
+	// Message is the text of Err.
+	Message string `json:"message"`
+
+	Err error `json:"-"`
+}
+
+func (e *ParamError) Error() string {
+	return fmt.Sprintf("invalid %s %q: %v", e.Param, e.Value, e.Err)
+}
+
+func (e *ParamError) Unwrap() error { return e.Err }
+
+// writeError writes err as a 400 response, in JSON if it is a *ParamError.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	var perr *ParamError
+	if errors.As(err, &perr) {
+		h.Respond(w, r, perr, http.StatusBadRequest)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusBadRequest)
+}
+
+// reasons name the errors of parameter parsers, most specific first.
+var reasons = []struct {
+	err    error
+	reason string
+}{
+	{isbn.ErrLength, "length"},
+	{isbn.ErrFormat, "format"},
+	{isbn.ErrValue, "checksum"},
+	{isbn.ErrGroup, "group"},
+	{isbn.ErrRegistrant, "registrant"},
+	{isbn.ErrRange, "range"},
+	{strconv.ErrSyntax, "syntax"},
+	{strconv.ErrRange, "range"},
+}
+
+// Reason returns the ParamError reason of err, such as "checksum" for an
+// ISBN with an invalid check digit, or "invalid" if it has no other name.
+// Services that report ISBN errors in other responses should use it so that
+// they are named alike.
+func Reason(err error) string {
+	for _, k := range reasons {
+		if errors.Is(err, k.err) {
+			return k.reason
+		}
+	}
+	return "invalid"
+}
+
+// newParamError returns err as a *ParamError, unless it already is one.
+func newParamError(key, value string, err error) error {
+	var perr *ParamError
+	if errors.As(err, &perr) {
+		return err
+	}
+	return &ParamError{Param: key, Value: value, Reason: Reason(err), Message: err.Error(), Err: err}
+}
+
+// QueryParam is a middleware that parses the query parameter key and stores it in the
+// request context, to be retrieved with QueryParamValue. A parameter that is missing or
+// empty is def, without calling parser. A parser error is written as a 400 response with
+// a ParamError body.
+func QueryParam[T any](key string, def T, parser func(value string) (T, error)) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			value := def
+			if s := r.URL.Query().Get(key); s != "" {
+				var err error
+				if value, err = parser(s); err != nil {
+					writeError(w, r, newParamError(key, s, err))
+					return
+				}
+			}
+
+			r = r.WithContext(context.WithValue(r.Context(), queryKey+contextKey(":"+key), value))
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// QueryParamValue retrieves the query parameter key from the request context.
+func QueryParamValue[T any](r *http.Request, key string) (T, error) {
+	return QueryParamValueFromContext[T](r.Context(), key)
+}
+
+// QueryParamValueFromContext retrieves the query parameter key from the request context.
+func QueryParamValueFromContext[T any](ctx context.Context, key string) (T, error) {
+	v, ok := ctx.Value(queryKey + contextKey(":"+key)).(T)
+	if !ok {
+		return v, fmt.Errorf("query param %q not found", key)
+	}
+	return v, nil
+}
+
+// QueryString is a QueryParam parser of any string.
+func QueryString(value string) (string, error) { return value, nil }
+
+// QueryBool is a QueryParam parser of the booleans accepted by strconv.ParseBool.
+func QueryBool(value string) (bool, error) { return strconv.ParseBool(value) }
+
+// QueryISBN is a QueryParam parser of ISBNs in any form understood by isbn.Parse.
+func QueryISBN(value string) (isbn.ISBN, error) { return isbn.Parse(value) }
+
+// QueryInt returns a QueryParam parser of integers from min to max inclusive.
+func QueryInt(min, max int) func(value string) (int, error) {
+	return func(value string) (int, error) {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, err
+		}
+		if n < min || n > max {
+			return 0, fmt.Errorf("%w: must be from %d to %d", strconv.ErrRange, min, max)
+		}
+		return n, nil
+	}
+}
+
+// QueryOneOf returns a QueryParam parser of the strings in values.
+func QueryOneOf(values ...string) func(value string) (string, error) {
+	return func(value string) (string, error) {
+		for _, v := range values {
+			if value == v {
+				return value, nil
+			}
+		}
+		return "", fmt.Errorf("must be one of %s", strings.Join(values, ", "))
+	}
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/adoublef-go/isbn"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/hyphengolang/prelude/testing/is"
+)
+
+func paramsRouter() http.Handler {
+	mux := chi.NewRouter()
+	mux.With(
+		PathParam("isbn", ISBNParam),
+		PathParam("uuid", UUIDParam),
+		QueryParam("limit", 10, QueryInt(1, 100)),
+		QueryParam("sort", "title", QueryOneOf("title", "published")),
+		QueryParam("related", isbn.ISBN{}, QueryISBN),
+		QueryParam("covers", false, QueryBool),
+	).Get("/books/{isbn}/reviews/{uuid}", func(w http.ResponseWriter, r *http.Request) {
+		v, err := PathParamValue[isbn.ISBN](r, "isbn")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		uid, _ := PathParamValue[uuid.UUID](r, "uuid")
+		limit, _ := QueryParamValue[int](r, "limit")
+		sort, _ := QueryParamValue[string](r, "sort")
+		related, _ := QueryParamValue[isbn.ISBN](r, "related")
+		covers, _ := QueryParamValue[bool](r, "covers")
+		if related == (isbn.ISBN{}) {
+			related = v
+		}
+		fmt.Fprintf(w, "%s %s %d %s %s %t", v, uid, limit, sort, related, covers)
+	})
+	return mux
+}
+
+func TestParams(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	const uid = "7c4c4b5e-52a8-4b8e-9c4b-7a1d2a8f7d10"
+
+	tt := []struct {
+		desc   string
+		target string
+		code   int
+		body   string
+		param  string
+		reason string
+	}{
+		{desc: "defaults", target: "/books/0140328726/reviews/" + uid, code: http.StatusOK, body: "9780140328721 " + uid + " 10 title 9780140328721 false"},
+		{desc: "query params", target: "/books/0140328726/reviews/" + uid + "?limit=50&sort=published&related=0716703440&covers=true", code: http.StatusOK, body: "9780140328721 " + uid + " 50 published 9780716703440 true"},
+		{desc: "empty query param", target: "/books/0140328726/reviews/" + uid + "?limit=", code: http.StatusOK, body: "9780140328721 " + uid + " 10 title 9780140328721 false"},
+		{desc: "invalid isbn", target: "/books/0140328727/reviews/" + uid, code: http.StatusBadRequest, param: "isbn", reason: "checksum"},
+		{desc: "invalid uuid", target: "/books/0140328726/reviews/42", code: http.StatusBadRequest, param: "uuid", reason: "invalid"},
+		{desc: "nil uuid", target: "/books/0140328726/reviews/" + uuid.Nil.String(), code: http.StatusBadRequest, param: "uuid", reason: "invalid"},
+		{desc: "limit syntax", target: "/books/0140328726/reviews/" + uid + "?limit=ten", code: http.StatusBadRequest, param: "limit", reason: "syntax"},
+		{desc: "limit range", target: "/books/0140328726/reviews/" + uid + "?limit=1000", code: http.StatusBadRequest, param: "limit", reason: "range"},
+		{desc: "sort", target: "/books/0140328726/reviews/" + uid + "?sort=author", code: http.StatusBadRequest, param: "sort", reason: "invalid"},
+		{desc: "related isbn", target: "/books/0140328726/reviews/" + uid + "?related=978014032872", code: http.StatusBadRequest, param: "related", reason: "length"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			paramsRouter().ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.target, nil))
+			is.Equal(w.Code, tc.code) // status code
+
+			if tc.code == http.StatusOK {
+				is.Equal(w.Body.String(), tc.body) // parsed params
+				return
+			}
+			var perr ParamError
+			is.NoErr(json.NewDecoder(w.Body).Decode(&perr)) // structured error
+			is.Equal(perr.Param, tc.param)                  // invalid param
+			is.Equal(perr.Reason, tc.reason)                // error reason
+		})
+	}
+}
+
+func TestPathParamLegacy(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	mux := chi.NewRouter()
+	mux.With(PathParam("isbn", ISBNParam)).Get("/books/{isbn}", func(w http.ResponseWriter, r *http.Request) {
+		v, err := PathParamFromRequest[isbn.ISBN](r)
+		is.NoErr(err) // path param from context
+		w.Write([]byte(v.String()))
+	})
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/books/0140328726", nil))
+	is.Equal(w.Body.String(), "9780140328721") // last path param
+}
+
+func TestReason(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	_, err := isbn.Parse("0140328727")
+	is.Equal(Reason(err), "checksum") // isbn check digit
+
+	tt := []struct {
+		err    error
+		reason string
+	}{
+		{isbn.ErrLength, "length"},
+		{isbn.ErrGroup, "group"},
+		{fmt.Errorf("isbn 9789990190007: %w", isbn.ErrRegistrant), "registrant"},
+		{isbn.ErrRange, "range"},
+		{strconv.ErrSyntax, "syntax"},
+		{errors.New("unknown"), "invalid"},
+	}
+
+	for _, tc := range tt {
+		is.Equal(Reason(tc.err), tc.reason) // reason of error
+	}
+}
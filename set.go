@@ -0,0 +1,113 @@
+package isbn
+
+import "sort"
+
+// ISBNSet is a set of ISBNs. As every ISBN is held in its ISBN 13 form, an
+// ISBN parsed from its ISBN 10 form is the same member as its ISBN 13 form.
+type ISBNSet map[ISBN]struct{}
+
+// NewISBNSet returns a set holding isbns.
+func NewISBNSet(isbns ...ISBN) ISBNSet {
+	s := make(ISBNSet, len(isbns))
+	s.Add(isbns...)
+	return s
+}
+
+// Add adds isbns to s.
+func (s ISBNSet) Add(isbns ...ISBN) {
+	for _, isbn := range isbns {
+		s[isbn] = struct{}{}
+	}
+}
+
+// Remove removes isbns from s.
+func (s ISBNSet) Remove(isbns ...ISBN) {
+	for _, isbn := range isbns {
+		delete(s, isbn)
+	}
+}
+
+// Contains reports whether isbn is in s.
+func (s ISBNSet) Contains(isbn ISBN) bool {
+	_, ok := s[isbn]
+	return ok
+}
+
+// Len returns the number of ISBNs in s.
+func (s ISBNSet) Len() int { return len(s) }
+
+// Union returns a new set with the ISBNs in either s or t.
+func (s ISBNSet) Union(t ISBNSet) ISBNSet {
+	u := make(ISBNSet, len(s)+len(t))
+	for isbn := range s {
+		u[isbn] = struct{}{}
+	}
+	for isbn := range t {
+		u[isbn] = struct{}{}
+	}
+	return u
+}
+
+// Intersection returns a new set with the ISBNs in both s and t.
+func (s ISBNSet) Intersection(t ISBNSet) ISBNSet {
+	if len(t) < len(s) {
+		s, t = t, s
+	}
+
+	u := make(ISBNSet)
+	for isbn := range s {
+		if t.Contains(isbn) {
+			u[isbn] = struct{}{}
+		}
+	}
+	return u
+}
+
+// Diff returns a new set with the ISBNs in s that are not in t.
+func (s ISBNSet) Diff(t ISBNSet) ISBNSet {
+	u := make(ISBNSet)
+	for isbn := range s {
+		if !t.Contains(isbn) {
+			u[isbn] = struct{}{}
+		}
+	}
+	return u
+}
+
+// Slice returns the ISBNs in s in ascending order.
+func (s ISBNSet) Slice() []ISBN {
+	isbns := make([]ISBN, 0, len(s))
+	for isbn := range s {
+		isbns = append(isbns, isbn)
+	}
+	sort.Slice(isbns, func(i, j int) bool { return Less(isbns[i], isbns[j]) })
+	return isbns
+}
+
+// GroupByRegistrationGroup groups isbns by their prefix and registration
+// group (e.g. "978-0"). ISBNs outside the registered ranges are grouped
+// under the empty string.
+func GroupByRegistrationGroup(isbns []ISBN) map[string][]ISBN {
+	return groupBy(isbns, func(p Parts) string { return p.Prefix + "-" + p.Group })
+}
+
+// GroupByRegistrant groups isbns by their prefix, registration group and
+// registrant (e.g. "978-0-7167"). ISBNs outside the registered ranges are
+// grouped under the empty string.
+func GroupByRegistrant(isbns []ISBN) map[string][]ISBN {
+	return groupBy(isbns, func(p Parts) string { return p.Prefix + "-" + p.Group + "-" + p.Registrant })
+}
+
+func groupBy(isbns []ISBN, key func(Parts) string) map[string][]ISBN {
+	rs := CurrentRanges()
+
+	groups := make(map[string][]ISBN)
+	for _, isbn := range isbns {
+		var k string
+		if p, err := rs.Parts(isbn); err == nil {
+			k = key(p)
+		}
+		groups[k] = append(groups[k], isbn)
+	}
+	return groups
+}
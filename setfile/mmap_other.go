@@ -0,0 +1,17 @@
+//go:build !(aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris)
+
+package setfile
+
+import (
+	"io"
+	"os"
+)
+
+// mmap reads the file into memory where it cannot be mapped.
+func mmap(f *os.File, size int) ([]byte, func() error, error) {
+	b := make([]byte, size)
+	if _, err := io.ReadFull(f, b); err != nil {
+		return nil, nil, err
+	}
+	return b, func() error { return nil }, nil
+}
@@ -0,0 +1,159 @@
+package setfile
+
+import (
+	"encoding/binary"
+	"os"
+	"sort"
+
+	"github.com/adoublef-go/isbn"
+)
+
+// Reader answers lookups from a set file. It is safe for concurrent use.
+type Reader struct {
+	h     header
+	bloom bloom
+	index []byte
+	data  []byte
+
+	unmap func() error
+}
+
+// Open memory-maps the set file name, on systems that support it, and
+// otherwise reads it into memory. The Reader must be closed to unmap the
+// file, after which it must not be used.
+func Open(name string) (*Reader, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	// the mapping outlives the file
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if fi.Size() < headerSize || int64(int(fi.Size())) != fi.Size() {
+		return nil, ErrFormat
+	}
+
+	b, unmap, err := mmap(f, int(fi.Size()))
+	if err != nil {
+		return nil, err
+	}
+	r, err := NewReader(b)
+	if err != nil {
+		unmap()
+		return nil, err
+	}
+	r.unmap = unmap
+	return r, nil
+}
+
+// NewReader returns a Reader of the set file held in b, which must not be
+// modified while the Reader is in use.
+func NewReader(b []byte) (*Reader, error) {
+	r := &Reader{}
+	if err := r.h.unmarshal(b); err != nil {
+		return nil, err
+	}
+
+	b = b[headerSize:]
+	r.bloom = bloom{bits: b[:r.h.bloomLen], hashes: r.h.hashes}
+	b = b[r.h.bloomLen:]
+	r.index, r.data = b[:r.h.blocks*entrySize], b[r.h.blocks*entrySize:]
+	return r, nil
+}
+
+// Close unmaps the file of a Reader returned by Open.
+func (r *Reader) Close() error {
+	if r.unmap == nil {
+		return nil
+	}
+	err := r.unmap()
+	r.unmap = nil
+	return err
+}
+
+// Len returns the number of ISBNs in the set.
+func (r *Reader) Len() int { return int(r.h.count) }
+
+// Contains reports whether v is in the set.
+func (r *Reader) Contains(v isbn.ISBN) bool { return r.ContainsUint64(v.Uint64()) }
+
+// ContainsUint64 reports whether the ISBN with the compact integer form n is
+// in the set.
+func (r *Reader) ContainsUint64(n uint64) bool {
+	if r.bloom.hashes > 0 && !r.bloom.mayContain(n) {
+		return false
+	}
+
+	// the last block starting at or before n
+	i := sort.Search(int(r.h.blocks), func(i int) bool { return r.first(i) > n }) - 1
+	if i < 0 {
+		return false
+	}
+
+	found := false
+	r.block(i, func(v uint64) bool {
+		found = v == n
+		return v < n
+	})
+	return found
+}
+
+// Each calls fn with each ISBN in the set in ascending order, stopping at
+// the first error. It returns ErrFormat if the file is corrupt.
+func (r *Reader) Each(fn func(isbn.ISBN) error) error {
+	for i := 0; i < int(r.h.blocks); i++ {
+		var err error
+		ok := r.block(i, func(n uint64) bool {
+			var v isbn.ISBN
+			if v, err = isbn.FromUint64(n); err == nil {
+				err = fn(v)
+			}
+			return err == nil
+		})
+		switch {
+		case err != nil:
+			return err
+		case !ok:
+			return ErrFormat
+		}
+	}
+	return nil
+}
+
+func (r *Reader) first(i int) uint64 {
+	return binary.LittleEndian.Uint64(r.index[i*entrySize:])
+}
+
+// block calls fn with each integer of block i in turn, until fn returns
+// false. It returns false if the block is corrupt.
+func (r *Reader) block(i int, fn func(uint64) bool) bool {
+	off := binary.LittleEndian.Uint64(r.index[i*entrySize+8:])
+	end := uint64(len(r.data))
+	if i+1 < int(r.h.blocks) {
+		end = binary.LittleEndian.Uint64(r.index[(i+1)*entrySize+8:])
+	}
+	if off > end || end > uint64(len(r.data)) {
+		return false
+	}
+
+	v := r.first(i)
+	if !fn(v) {
+		return true
+	}
+	for data := r.data[off:end]; len(data) > 0; {
+		d, k := binary.Uvarint(data)
+		if k <= 0 {
+			return false
+		}
+		data = data[k:]
+		v += d
+		if !fn(v) {
+			return true
+		}
+	}
+	return true
+}
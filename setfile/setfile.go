@@ -0,0 +1,198 @@
+// Package setfile writes and reads large sets of ISBNs in a compact binary
+// file, answering membership lookups from a memory-mapped file without
+// loading it.
+//
+//	var b setfile.Builder
+//	b.Add(isbns...)
+//	b.WriteFile("known.isbnset")
+//
+//	r, _ := setfile.Open("known.isbnset")
+//	defer r.Close()
+//	r.Contains(v)
+//
+// ISBNs are held in their compact integer form (see isbn.ISBN.Uint64), sorted
+// and split into blocks. Each block holds the differences between its
+// integers as uvarints, which takes two or three bytes an ISBN for a set as
+// dense as the books in print, and the file ends in an index of the first
+// integer of each block. A lookup searches the index and decodes one block.
+//
+// A file may start with a Bloom filter, which answers most lookups of ISBNs
+// that are not in the set without reading the index or blocks.
+//
+// The file is laid out, with integers in little endian, as
+//
+//	header  magic "ISBNSET1", count uint64, block size uint32,
+//	        hashes uint32, bloom length uint64, blocks uint64,
+//	        data length uint64, zero padding to 64 bytes
+//	bloom   bloom length bytes of filter
+//	index   blocks entries of first integer uint64, data offset uint64
+//	data    blocks of uvarint differences
+package setfile
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/adoublef-go/isbn"
+)
+
+// ErrFormat is returned when opening a file that is not a set file, or is
+// truncated.
+var ErrFormat = errors.New("setfile: invalid set file")
+
+const (
+	magic      = "ISBNSET1"
+	headerSize = 64
+	entrySize  = 16
+)
+
+// DefaultBlockSize is the number of ISBNs in each block of a file written
+// by a Builder with no BlockSize.
+const DefaultBlockSize = 128
+
+// Builder collects the ISBNs of a set and writes them as a set file. It
+// holds eight bytes an ISBN until the file is written.
+type Builder struct {
+	// BlockSize is the number of ISBNs in each block, DefaultBlockSize if
+	// zero. Larger blocks make a smaller index, and slower lookups.
+	BlockSize int
+	// BloomBits is the number of bits of Bloom filter for each ISBN, with
+	// no filter if zero. Ten bits lets through about 1% of the ISBNs that
+	// are not in the set.
+	BloomBits int
+
+	values []uint64
+}
+
+// Add adds isbns to the set.
+func (b *Builder) Add(isbns ...isbn.ISBN) {
+	for _, v := range isbns {
+		b.values = append(b.values, v.Uint64())
+	}
+}
+
+// Len returns the number of ISBNs added, counting any added more than once.
+func (b *Builder) Len() int { return len(b.values) }
+
+// Reset removes the ISBNs added.
+func (b *Builder) Reset() { b.values = nil }
+
+// sorted sorts and removes duplicates from the values added.
+func (b *Builder) sorted() []uint64 {
+	values := b.values
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+
+	n := 0
+	for i, v := range values {
+		if i == 0 || v != values[n-1] {
+			values[n] = v
+			n++
+		}
+	}
+	b.values = values[:n]
+	return b.values
+}
+
+// header is the header of a set file.
+type header struct {
+	count     uint64
+	blockSize uint32
+	hashes    uint32
+	bloomLen  uint64
+	blocks    uint64
+	dataLen   uint64
+}
+
+func (h *header) marshal() []byte {
+	b := make([]byte, headerSize)
+	copy(b, magic)
+	binary.LittleEndian.PutUint64(b[8:], h.count)
+	binary.LittleEndian.PutUint32(b[16:], h.blockSize)
+	binary.LittleEndian.PutUint32(b[20:], h.hashes)
+	binary.LittleEndian.PutUint64(b[24:], h.bloomLen)
+	binary.LittleEndian.PutUint64(b[32:], h.blocks)
+	binary.LittleEndian.PutUint64(b[40:], h.dataLen)
+	return b
+}
+
+func (h *header) unmarshal(b []byte) error {
+	if len(b) < headerSize || string(b[:8]) != magic {
+		return ErrFormat
+	}
+	h.count = binary.LittleEndian.Uint64(b[8:])
+	h.blockSize = binary.LittleEndian.Uint32(b[16:])
+	h.hashes = binary.LittleEndian.Uint32(b[20:])
+	h.bloomLen = binary.LittleEndian.Uint64(b[24:])
+	h.blocks = binary.LittleEndian.Uint64(b[32:])
+	h.dataLen = binary.LittleEndian.Uint64(b[40:])
+
+	if h.blockSize == 0 || h.blocks != (h.count+uint64(h.blockSize)-1)/uint64(h.blockSize) ||
+		h.bloomLen%8 != 0 || (h.bloomLen == 0) != (h.hashes == 0) {
+		return ErrFormat
+	}
+	// the sections must fit in the file, without overflowing on the way
+	rest := uint64(len(b) - headerSize)
+	if h.bloomLen > rest || h.blocks > (rest-h.bloomLen)/entrySize ||
+		h.dataLen != rest-h.bloomLen-h.blocks*entrySize {
+		return ErrFormat
+	}
+	return nil
+}
+
+// bloom is a Bloom filter over the compact integer form of ISBNs.
+type bloom struct {
+	bits   []byte
+	hashes uint32
+}
+
+// newBloom returns a filter of bitsPer bits for each of n ISBNs.
+func newBloom(n, bitsPer int) bloom {
+	m := (uint64(n)*uint64(bitsPer) + 63) / 64 * 8
+	if m == 0 {
+		m = 8
+	}
+	k := uint32(math.Round(float64(bitsPer) * math.Ln2))
+	if k < 1 {
+		k = 1
+	} else if k > 30 {
+		k = 30
+	}
+	return bloom{bits: make([]byte, m), hashes: k}
+}
+
+// locations calls fn with the bits of v, stopping if fn returns false.
+func (f bloom) locations(v uint64, fn func(i uint64) bool) bool {
+	m := uint64(len(f.bits)) * 8
+	h1, h2 := mix(v), mix(v^0x9e3779b97f4a7c15)|1
+	for i := uint32(0); i < f.hashes; i++ {
+		if !fn((h1 + uint64(i)*h2) % m) {
+			return false
+		}
+	}
+	return true
+}
+
+func (f bloom) add(v uint64) {
+	f.locations(v, func(i uint64) bool {
+		f.bits[i/8] |= 1 << (i % 8)
+		return true
+	})
+}
+
+// mayContain reports false if v is certainly not in the set.
+func (f bloom) mayContain(v uint64) bool {
+	return f.locations(v, func(i uint64) bool { return f.bits[i/8]&(1<<(i%8)) != 0 })
+}
+
+// mix is the finalizer of splitmix64, spreading the bits of ISBNs that
+// differ in a few low digits.
+func mix(v uint64) uint64 {
+	v ^= v >> 30
+	v *= 0xbf58476d1ce4e5b9
+	v ^= v >> 27
+	v *= 0x94d049bb133111eb
+	v ^= v >> 31
+	return v
+}
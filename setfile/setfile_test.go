@@ -0,0 +1,167 @@
+package setfile
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adoublef-go/isbn"
+	"github.com/adoublef-go/isbn/isbntest"
+	"github.com/hyphengolang/prelude/testing/is"
+)
+
+// sets returns n ISBNs in the set, and n that are not.
+func sets(n int) (in, out []isbn.ISBN) {
+	g := isbntest.New(1)
+	seen := isbn.NewISBNSet()
+	for len(seen) < 2*n {
+		v := g.ISBN13()
+		if seen.Contains(v) {
+			continue
+		}
+		seen.Add(v)
+		if len(in) < n {
+			in = append(in, v)
+		} else {
+			out = append(out, v)
+		}
+	}
+	return in, out
+}
+
+func TestSetFile(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	in, out := sets(5000)
+
+	tt := []struct {
+		desc    string
+		builder Builder
+	}{
+		{"default", Builder{}},
+		{"bloom filter", Builder{BloomBits: 10}},
+		{"small blocks", Builder{BlockSize: 1}},
+		{"large blocks", Builder{BlockSize: 1000, BloomBits: 4}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			b := tc.builder
+			b.Add(in...)
+			b.Add(in[:100]...) // duplicates
+
+			name := filepath.Join(t.TempDir(), "known.isbnset")
+			is.NoErr(b.WriteFile(name)) // writing set file
+
+			r, err := Open(name)
+			is.NoErr(err) // opening set file
+			t.Cleanup(func() { r.Close() })
+
+			is.Equal(r.Len(), len(in)) // duplicates removed
+			for _, v := range in {
+				if !r.Contains(v) {
+					t.Fatalf("set does not contain %s", v)
+				}
+			}
+			for _, v := range out {
+				if r.Contains(v) {
+					t.Fatalf("set contains %s", v)
+				}
+			}
+
+			var got []isbn.ISBN
+			err = r.Each(func(v isbn.ISBN) error {
+				got = append(got, v)
+				return nil
+			})
+			is.NoErr(err)                                 // reading set
+			is.Equal(got, isbn.NewISBNSet(in...).Slice()) // in ascending order
+		})
+	}
+}
+
+func TestSetFileEmpty(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	var b Builder
+	b.BloomBits = 10
+	var buf bytes.Buffer
+	n, err := b.WriteTo(&buf)
+	is.NoErr(err)                     // writing empty set
+	is.Equal(n, int64(buf.Len()))     // bytes written
+	is.Equal(buf.Len(), headerSize+8) // header and smallest filter
+
+	r, err := NewReader(buf.Bytes())
+	is.NoErr(err) // reading empty set
+
+	v, _ := isbn.Parse("9780140328721")
+	is.Equal(r.Len(), 0)          // no isbns
+	is.True(!r.Contains(v))       // not in empty set
+	is.True(!r.ContainsUint64(0)) // not in empty set
+}
+
+func TestSetFileFormat(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	in, _ := sets(100)
+	var b Builder
+	b.Add(in...)
+	var buf bytes.Buffer
+	_, err := b.WriteTo(&buf)
+	is.NoErr(err) // writing set
+
+	data := buf.Bytes()
+	tt := []struct {
+		desc string
+		data []byte
+	}{
+		{"empty", nil},
+		{"magic", append([]byte("ISBNSET2"), data[8:]...)},
+		{"truncated", data[:len(data)-1]},
+		{"extended", append(append([]byte(nil), data...), 0)},
+		{"header only", data[:headerSize]},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := NewReader(tc.data)
+			is.True(errors.Is(err, ErrFormat)) // invalid set file
+		})
+	}
+
+	name := filepath.Join(t.TempDir(), "short.isbnset")
+	is.NoErr(os.WriteFile(name, []byte(magic), 0o644)) // writing short file
+	_, err = Open(name)
+	is.True(errors.Is(err, ErrFormat)) // shorter than header
+}
+
+func BenchmarkContains(b *testing.B) {
+	in, out := sets(100000)
+	for _, bloomBits := range []int{0, 10} {
+		builder := Builder{BloomBits: bloomBits}
+		builder.Add(in...)
+		name := filepath.Join(b.TempDir(), "bench.isbnset")
+		if err := builder.WriteFile(name); err != nil {
+			b.Fatal(err)
+		}
+		r, err := Open(name)
+		if err != nil {
+			b.Fatal(err)
+		}
+		defer r.Close()
+
+		for desc, isbns := range map[string][]isbn.ISBN{"in": in, "out": out} {
+			b.Run(fmt.Sprintf("%s/bloombits=%d", desc, bloomBits), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					r.Contains(isbns[i%len(isbns)])
+				}
+			})
+		}
+	}
+}
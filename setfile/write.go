@@ -0,0 +1,102 @@
+package setfile
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// WriteTo writes the set as a set file to w. The ISBNs are sorted in place,
+// and may be added to after.
+func (b *Builder) WriteTo(w io.Writer) (int64, error) {
+	values := b.sorted()
+	blockSize := b.BlockSize
+	if blockSize <= 0 {
+		blockSize = DefaultBlockSize
+	}
+
+	// the index is worked out before the data is written, so that the data
+	// can be written as it is encoded
+	blocks := (len(values) + blockSize - 1) / blockSize
+	index := make([]byte, blocks*entrySize)
+	var buf [binary.MaxVarintLen64]byte
+	var dataLen uint64
+	for i, v := range values {
+		if i%blockSize == 0 {
+			e := index[i/blockSize*entrySize:]
+			binary.LittleEndian.PutUint64(e, v)
+			binary.LittleEndian.PutUint64(e[8:], dataLen)
+			continue
+		}
+		dataLen += uint64(binary.PutUvarint(buf[:], v-values[i-1]))
+	}
+
+	var f bloom
+	if b.BloomBits > 0 {
+		f = newBloom(len(values), b.BloomBits)
+		for _, v := range values {
+			f.add(v)
+		}
+	}
+
+	h := header{
+		count:     uint64(len(values)),
+		blockSize: uint32(blockSize),
+		hashes:    f.hashes,
+		bloomLen:  uint64(len(f.bits)),
+		blocks:    uint64(blocks),
+		dataLen:   dataLen,
+	}
+
+	cw := &countingWriter{w: w}
+	bw := bufio.NewWriter(cw)
+	bw.Write(h.marshal())
+	bw.Write(f.bits)
+	bw.Write(index)
+	for i, v := range values {
+		if i%blockSize != 0 {
+			n := binary.PutUvarint(buf[:], v-values[i-1])
+			bw.Write(buf[:n])
+		}
+	}
+	err := bw.Flush()
+	return cw.n, err
+}
+
+// WriteFile writes the set as a set file named name. The file is written
+// in full before it replaces any file of that name, so that readers of it
+// do not see a partial set.
+func (b *Builder) WriteFile(name string) error {
+	f, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := b.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	// CreateTemp makes a file only its owner can read
+	if err := f.Chmod(0o644); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), name)
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,136 @@
+// Package sqliteisbn registers ISBN functions on mattn/go-sqlite3 connections
+// so they can be used from plain SQL, including CHECK constraints.
+//
+//	db, _ := sql.Open(sqliteisbn.DriverName, "books.db")
+//	db.Exec("CREATE TABLE books (isbn TEXT CHECK (isbn_valid(isbn)))")
+//	db.Exec("UPDATE legacy SET isbn = isbn_normalize(isbn)")
+//
+// Each function accepts any form understood by isbn.Parse, as TEXT, BLOB or
+// INTEGER, and returns NULL for a NULL argument.
+//
+//	isbn_valid(x)     1 if x is a valid ISBN, otherwise 0
+//	isbn_normalize(x) the ISBN 13 form of x, NULL if invalid
+//	isbn_hyphenate(x) the hyphenated ISBN 13 form of x, NULL if invalid or unassigned
+//	isbn10(x)         the ISBN 10 form of x, NULL if invalid or not a 978 ISBN
+//
+// isbn_hyphenate depends on the active range table, which isbn.SetRanges can
+// swap, so it is not deterministic and cannot be used in indexes or
+// generated columns.
+package sqliteisbn
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/adoublef-go/isbn"
+	"github.com/mattn/go-sqlite3"
+)
+
+// DriverName is the database/sql driver registered by this package. It is the
+// sqlite3 driver with Register as its ConnectHook.
+const DriverName = "sqlite3_isbn"
+
+func init() {
+	sql.Register(DriverName, &sqlite3.SQLiteDriver{ConnectHook: Register})
+}
+
+// Register registers the ISBN functions on conn. Use it as the ConnectHook of
+// a sqlite3.SQLiteDriver, or call it from an existing hook.
+func Register(conn *sqlite3.SQLiteConn) error {
+	// pure functions can be used in indexes and generated columns, so only
+	// those whose result does not depend on the active range table are, as
+	// isbn.SetRanges can swap it
+	funcs := []struct {
+		name string
+		impl any
+		pure bool
+	}{
+		{"isbn_valid", valid, true},
+		{"isbn_normalize", normalize, true},
+		{"isbn_hyphenate", hyphenate, false},
+		{"isbn10", isbn10, true},
+	}
+
+	for _, f := range funcs {
+		if err := conn.RegisterFunc(f.name, f.impl, f.pure); err != nil {
+			return fmt.Errorf("register %s: %w", f.name, err)
+		}
+	}
+	return nil
+}
+
+func valid(v any) any {
+	if isNull(v) {
+		return nil
+	}
+	_, err := parse(v)
+	return err == nil
+}
+
+func normalize(v any) any {
+	if isNull(v) {
+		return nil
+	}
+	u, err := parse(v)
+	if err != nil {
+		return nil
+	}
+	return u.String()
+}
+
+func hyphenate(v any) any {
+	if isNull(v) {
+		return nil
+	}
+	u, err := parse(v)
+	if err != nil {
+		return nil
+	}
+	s, err := u.Hyphenate()
+	if err != nil {
+		return nil
+	}
+	return s
+}
+
+func isbn10(v any) any {
+	if isNull(v) {
+		return nil
+	}
+	u, err := parse(v)
+	if err != nil {
+		return nil
+	}
+	s, err := u.ISBN10()
+	if err != nil {
+		return nil
+	}
+	return s
+}
+
+// isNull reports whether v is SQL NULL, which the driver passes as a nil []byte.
+func isNull(v any) bool {
+	b, ok := v.([]byte)
+	return v == nil || ok && b == nil
+}
+
+func parse(v any) (isbn.ISBN, error) {
+	switch u := v.(type) {
+	case string:
+		return isbn.Parse(strings.TrimSpace(u))
+	case []byte:
+		return isbn.ParseBytes(bytes.TrimSpace(u))
+	case int64:
+		// an INTEGER loses the leading zeros of an ISBN 10
+		s := strconv.FormatInt(u, 10)
+		if u >= 0 && len(s) < 10 {
+			s = strings.Repeat("0", 10-len(s)) + s
+		}
+		return isbn.Parse(s)
+	default:
+		return isbn.ISBN{}, isbn.ErrFormat
+	}
+}
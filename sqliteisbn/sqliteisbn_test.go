@@ -0,0 +1,99 @@
+package sqliteisbn
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/hyphengolang/prelude/testing/is"
+)
+
+func TestFunctions(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	db, err := sql.Open(DriverName, ":memory:")
+	is.NoErr(err) // open database
+
+	t.Cleanup(func() { db.Close() })
+
+	tt := []struct {
+		desc   string
+		query  string
+		result any
+	}{
+		{"valid isbn 13", "SELECT isbn_valid('9780716703440')", int64(1)},
+		{"valid isbn 10 w/ dashes", "SELECT isbn_valid(' 0-7167-0344-0 ')", int64(1)},
+		{"invalid isbn", "SELECT isbn_valid('9780716703441')", int64(0)},
+		{"valid NULL", "SELECT isbn_valid(NULL)", nil},
+		{"normalize isbn 10", "SELECT isbn_normalize('0-7167-0344-0')", "9780716703440"},
+		{"normalize integer isbn 10", "SELECT isbn_normalize(716703440)", "9780716703440"},
+		{"normalize integer isbn 13", "SELECT isbn_normalize(9780716703440)", "9780716703440"},
+		{"normalize invalid", "SELECT isbn_normalize('978071670344')", nil},
+		{"hyphenate", "SELECT isbn_hyphenate('9781861972712')", "978-1-86197-271-2"},
+		{"hyphenate unassigned", "SELECT isbn_hyphenate('9789990190007')", nil},
+		{"isbn 10", "SELECT isbn10('978-0-8044-2957-3')", "080442957X"},
+		{"isbn 10 of 979", "SELECT isbn10('9791032305690')", nil},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			var result any
+			err := db.QueryRow(tc.query).Scan(&result)
+			is.NoErr(err) // query function
+
+			if s, ok := result.([]byte); ok {
+				result = string(s)
+			}
+			is.Equal(result, tc.result) // function result
+		})
+	}
+}
+
+func TestCheckConstraint(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	db, err := sql.Open(DriverName, ":memory:")
+	is.NoErr(err) // open database
+
+	t.Cleanup(func() { db.Close() })
+	db.SetMaxOpenConns(1)
+
+	_, err = db.Exec("CREATE TABLE \"__test__\" (id INTEGER PRIMARY KEY, isbn TEXT CHECK (isbn_valid(isbn)))")
+	is.NoErr(err) // migrate schema
+
+	_, err = db.Exec("INSERT INTO \"__test__\" (isbn) VALUES ('9780716703440'), (NULL)")
+	is.NoErr(err) // insert valid values
+
+	_, err = db.Exec("INSERT INTO \"__test__\" (isbn) VALUES ('9780716703441')")
+	is.True(err != nil) // check constraint rejects invalid isbn
+
+	_, err = db.Exec("UPDATE \"__test__\" SET isbn = isbn_hyphenate(isbn)")
+	is.NoErr(err) // clean up values with plain SQL
+
+	var isbn string
+	err = db.QueryRow("SELECT isbn FROM \"__test__\" WHERE id = 1").Scan(&isbn)
+	is.NoErr(err)                       // get entry from database
+	is.Equal(isbn, "978-0-7167-0344-0") // hyphenated value
+}
+
+func TestIndex(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	db, err := sql.Open(DriverName, ":memory:")
+	is.NoErr(err) // open database
+
+	t.Cleanup(func() { db.Close() })
+	db.SetMaxOpenConns(1)
+
+	_, err = db.Exec("CREATE TABLE \"__test__\" (id INTEGER PRIMARY KEY, isbn TEXT)")
+	is.NoErr(err) // migrate schema
+
+	_, err = db.Exec("CREATE INDEX \"__test_isbn__\" ON \"__test__\" (isbn_normalize(isbn))")
+	is.NoErr(err) // index on a pure function
+
+	// the result of isbn_hyphenate depends on the active range table
+	_, err = db.Exec("CREATE INDEX \"__test_hyphen__\" ON \"__test__\" (isbn_hyphenate(isbn))")
+	is.True(err != nil) // no index on a function of the range table
+}